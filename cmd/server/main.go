@@ -0,0 +1,19 @@
+package main
+
+import (
+	"os"
+
+	"foodsharing-backend/internal/app"
+
+	// City time zones are validated and loaded by name, the embedded database keeps that
+	// working on hosts without zoneinfo files.
+	_ "time/tzdata"
+)
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		app.Migrate(os.Args[2:])
+		return
+	}
+	app.Run()
+}
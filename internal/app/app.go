@@ -0,0 +1,150 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"foodsharing-backend/internal/config"
+	delivery "foodsharing-backend/internal/delivery/http"
+	"foodsharing-backend/internal/repository"
+	"foodsharing-backend/internal/server"
+	"foodsharing-backend/internal/service"
+	"foodsharing-backend/pkg/auth"
+	"foodsharing-backend/pkg/email"
+	"foodsharing-backend/pkg/hash"
+	"foodsharing-backend/pkg/storage"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+const shutdownTimeout = 5 * time.Second
+
+func Run() {
+	cfg, err := config.Init()
+	if err != nil {
+		log.Fatalf("cannot read config: %v", err)
+	}
+
+	poolConfig, err := pgxpool.ParseConfig(cfg.Postgres.URL)
+	if err != nil {
+		log.Fatalf("cannot parse postgres url: %v", err)
+	}
+	repository.RecordActor(poolConfig)
+	pool, err := pgxpool.ConnectConfig(context.Background(), poolConfig)
+	if err != nil {
+		log.Fatalf("cannot connect to postgres: %v", err)
+	}
+	defer pool.Close()
+
+	minioClient, err := minio.New(cfg.Storage.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.Storage.AccessKey, cfg.Storage.SecretKey, ""),
+		Secure: cfg.Storage.UseSSL,
+	})
+	if err != nil {
+		log.Fatalf("cannot create storage client: %v", err)
+	}
+
+	tokenManager, err := auth.NewManager(cfg.Auth.SigningKey)
+	if err != nil {
+		log.Fatalf("cannot create token manager: %v", err)
+	}
+
+	tokenHasher, err := hash.NewHMACHasher(cfg.Auth.TokenHashKey)
+	if err != nil {
+		log.Fatalf("cannot create token hasher: %v", err)
+	}
+
+	passwordHasher, err := hash.NewArgon2Hasher(hash.Argon2Params{
+		Memory:      cfg.Auth.Password.Memory,
+		Iterations:  cfg.Auth.Password.Iterations,
+		Parallelism: cfg.Auth.Password.Parallelism,
+	})
+	if err != nil {
+		log.Fatalf("cannot create password hasher: %v", err)
+	}
+
+	mailer, err := newMailer(cfg.Email)
+	if err != nil {
+		log.Fatalf("cannot create mailer: %v", err)
+	}
+
+	repos := repository.NewRepositories(pool, tokenHasher)
+	fileStorage := storage.NewFileStorage(minioClient, cfg.Storage.Bucket, cfg.Storage.Endpoint)
+	services := service.NewServices(service.Deps{
+		Repos:          repos,
+		Storage:        fileStorage,
+		Mailer:         mailer,
+		TokenManager:   tokenManager,
+		PasswordHasher: passwordHasher,
+		AuthConfig: service.AuthConfig{
+			AccessTokenTTL:       cfg.Auth.AccessTokenTTL,
+			RefreshTokenTTL:      cfg.Auth.RefreshTokenTTL,
+			PasswordResetTTL:     cfg.Auth.PasswordResetTTL,
+			EmailVerificationTTL: cfg.Auth.EmailVerificationTTL,
+			TwoFactorPermissions: cfg.Auth.TwoFactor.RequiredPermissions,
+			TwoFactorIssuer:      cfg.Auth.TwoFactor.Issuer,
+			Lockout: service.LockoutConfig{
+				MaxAccountFailures: cfg.Auth.Lockout.AccountFailures,
+				MaxIPFailures:      cfg.Auth.Lockout.IPFailures,
+				BaseLockout:        cfg.Auth.Lockout.Base,
+				MaxLockout:         cfg.Auth.Lockout.Max,
+				FailureWindow:      cfg.Auth.Lockout.Window,
+			},
+			PasswordResetLimit: service.PasswordResetLimitConfig{
+				MaxAccountRequests: cfg.Auth.PasswordResetLimit.AccountRequests,
+				MaxIPRequests:      cfg.Auth.PasswordResetLimit.IPRequests,
+				Window:             cfg.Auth.PasswordResetLimit.Window,
+			},
+			LinkBaseURL: cfg.Email.LinkBaseURL,
+		},
+		DeletedRetention: cfg.Jobs.DeletedRetention,
+	})
+	handler := delivery.NewHandler(services, cfg.HTTP)
+
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	go runPeriodically(jobsCtx, cfg.Jobs.MembershipCleanupInterval, "expired membership cleanup",
+		services.Groups.RemoveExpiredMembers)
+	go runPeriodically(jobsCtx, cfg.Jobs.TrashPurgeInterval, "trash purge", services.Trash.Purge)
+
+	srv := server.NewServer(cfg.HTTP, handler.Init())
+	go func() {
+		if err := srv.Run(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("cannot run http server: %v", err)
+		}
+	}()
+	log.Printf("server started on port %s", cfg.HTTP.Port)
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := srv.Stop(ctx); err != nil {
+		log.Printf("cannot stop http server: %v", err)
+	}
+	if err := services.Auth.Stop(ctx); err != nil {
+		log.Printf("cannot send the queued emails: %v", err)
+	}
+}
+
+func newMailer(cfg config.EmailConfig) (email.Mailer, error) {
+	switch cfg.Driver {
+	case "file":
+		return email.NewFileMailer(cfg.DumpDir, cfg.From)
+	case "memory":
+		return email.NewMemoryMailer(), nil
+	default:
+		return email.NewSMTPMailer(cfg.SMTP.Host, cfg.SMTP.Port, cfg.SMTP.Username, cfg.SMTP.Password, cfg.From), nil
+	}
+}
@@ -0,0 +1,92 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+
+	"foodsharing-backend/internal/config"
+	"foodsharing-backend/internal/repository/migrations"
+	"foodsharing-backend/pkg/migrate"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+const migrateUsage = "usage: migrate [up | down [steps] | status]"
+
+// Migrate runs the migrate subcommand: "up" applies the pending migrations and is the
+// default, "down" reverts the given number of migrations, one unless told otherwise, and
+// "status" lists the migrations.
+func Migrate(args []string) {
+	cfg, err := config.InitPostgres()
+	if err != nil {
+		log.Fatalf("cannot read config: %v", err)
+	}
+
+	ctx := context.Background()
+	pool, err := pgxpool.Connect(ctx, cfg.URL)
+	if err != nil {
+		log.Fatalf("cannot connect to postgres: %v", err)
+	}
+	defer pool.Close()
+
+	migrator, err := migrate.NewMigrator(pool, migrations.FS)
+	if err != nil {
+		log.Fatalf("cannot load migrations: %v", err)
+	}
+
+	if err := runMigrate(ctx, migrator, args); err != nil {
+		pool.Close()
+		log.Fatalf("cannot migrate: %v", err)
+	}
+}
+
+func runMigrate(ctx context.Context, migrator *migrate.Migrator, args []string) error {
+	command := "up"
+	if len(args) > 0 {
+		command = args[0]
+	}
+
+	switch command {
+	case "up":
+		done, err := migrator.Up(ctx)
+		logMigrations("applied", done)
+		return err
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			var err error
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps <= 0 {
+				return fmt.Errorf("invalid number of steps %q, %s", args[1], migrateUsage)
+			}
+		}
+		done, err := migrator.Down(ctx, steps)
+		logMigrations("reverted", done)
+		return err
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			state := "pending"
+			if status.AppliedAt != nil {
+				state = "applied at " + status.AppliedAt.Format("2006-01-02 15:04:05 MST")
+			}
+			log.Printf("%04d_%s: %s", status.Version, status.Name, state)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown command %q, %s", command, migrateUsage)
+	}
+}
+
+func logMigrations(action string, done []migrate.Migration) {
+	if len(done) == 0 {
+		log.Printf("no migrations %s", action)
+	}
+	for _, migration := range done {
+		log.Printf("%s %04d_%s", action, migration.Version, migration.Name)
+	}
+}
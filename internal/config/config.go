@@ -0,0 +1,470 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"foodsharing-backend/internal/domain"
+)
+
+const (
+	defaultHTTPPort           = "8000"
+	defaultHTTPRWTimeout      = 10 * time.Second
+	defaultHTTPMaxHeaderBytes = 1 << 20
+	defaultMaxUploadSize      = 10 << 20
+	defaultAccessTokenTTL     = 15 * time.Minute
+	defaultRefreshTokenTTL    = 30 * 24 * time.Hour
+	defaultPasswordResetTTL   = time.Hour
+	defaultVerificationTTL    = 48 * time.Hour
+	defaultSMTPPort           = 587
+	defaultTwoFactorIssuer    = "Foodsharing"
+	defaultMembershipCleanup  = 5 * time.Minute
+	defaultTrashPurge         = time.Hour
+	defaultDeletedRetention   = 30 * 24 * time.Hour
+
+	defaultLockoutAccountFailures = 5
+	defaultLockoutIPFailures      = 50
+	defaultLockoutBase            = time.Minute
+	defaultLockoutMax             = time.Hour
+	defaultLockoutWindow          = 24 * time.Hour
+
+	defaultPasswordResetAccountRequests = 3
+	defaultPasswordResetIPRequests      = 20
+	defaultPasswordResetWindow          = time.Hour
+
+	defaultPasswordMemory      = 64 * 1024
+	defaultPasswordIterations  = 3
+	defaultPasswordParallelism = 2
+)
+
+type (
+	Config struct {
+		HTTP     HTTPConfig
+		Postgres PostgresConfig
+		Storage  StorageConfig
+		Auth     AuthConfig
+		Email    EmailConfig
+		Jobs     JobsConfig
+	}
+
+	// HTTPConfig.TrustedProxies lists the networks of the reverse proxies whose
+	// X-Forwarded-For headers are believed. The address of any other client is taken from
+	// the connection.
+	HTTPConfig struct {
+		Port           string
+		ReadTimeout    time.Duration
+		WriteTimeout   time.Duration
+		MaxHeaderBytes int
+		MaxUploadSize  int64
+		TrustedProxies []*net.IPNet
+	}
+
+	PostgresConfig struct {
+		URL string
+	}
+
+	StorageConfig struct {
+		Endpoint  string
+		AccessKey string
+		SecretKey string
+		Bucket    string
+		UseSSL    bool
+	}
+
+	AuthConfig struct {
+		SigningKey      string
+		TokenHashKey    string
+		AccessTokenTTL  time.Duration
+		RefreshTokenTTL time.Duration
+		Password        PasswordConfig
+
+		PasswordResetTTL     time.Duration
+		EmailVerificationTTL time.Duration
+		TwoFactor            TwoFactorConfig
+		Lockout              LockoutConfig
+		PasswordResetLimit   PasswordResetLimitConfig
+	}
+
+	// PasswordConfig holds the argon2id cost parameters. Changing them makes existing
+	// hashes get replaced on the next successful sign-in.
+	PasswordConfig struct {
+		Memory      uint32
+		Iterations  uint32
+		Parallelism uint8
+	}
+
+	// TwoFactorConfig lists the permissions whose holders have to use two-factor
+	// authentication. Issuer is the account name shown in authenticator apps.
+	TwoFactorConfig struct {
+		Issuer              string
+		RequiredPermissions domain.Permissions
+	}
+
+	// LockoutConfig limits failed sign-ins per email and per IP address. Lockouts start at
+	// Base and double with every further failure up to Max, failures are forgotten after
+	// Window without new ones.
+	LockoutConfig struct {
+		AccountFailures int
+		IPFailures      int
+		Base            time.Duration
+		Max             time.Duration
+		Window          time.Duration
+	}
+
+	// PasswordResetLimitConfig limits password reset requests per email and per IP address
+	// within Window.
+	PasswordResetLimitConfig struct {
+		AccountRequests int
+		IPRequests      int
+		Window          time.Duration
+	}
+
+	// EmailConfig selects how emails are delivered: "smtp" sends them, "file" dumps them to
+	// DumpDir and "memory" only keeps them in the process. LinkBaseURL is the address of the
+	// frontend that links in emails point to.
+	EmailConfig struct {
+		Driver      string
+		From        string
+		LinkBaseURL string
+		DumpDir     string
+		SMTP        SMTPConfig
+	}
+
+	// JobsConfig holds how often the background jobs run. DeletedRetention is how long
+	// deleted records are kept before the trash purge removes them.
+	JobsConfig struct {
+		MembershipCleanupInterval time.Duration
+		TrashPurgeInterval        time.Duration
+		DeletedRetention          time.Duration
+	}
+
+	SMTPConfig struct {
+		Host     string
+		Port     int
+		Username string
+		Password string
+	}
+)
+
+// Init reads the configuration from the environment. Only the connection settings
+// are mandatory, everything else falls back to sane defaults.
+func Init() (*Config, error) {
+	var cfg Config
+	var err error
+
+	cfg.HTTP.Port = getEnv("HTTP_PORT", defaultHTTPPort)
+	if cfg.HTTP.ReadTimeout, err = getDuration("HTTP_READ_TIMEOUT", defaultHTTPRWTimeout); err != nil {
+		return nil, err
+	}
+	if cfg.HTTP.WriteTimeout, err = getDuration("HTTP_WRITE_TIMEOUT", defaultHTTPRWTimeout); err != nil {
+		return nil, err
+	}
+	if cfg.HTTP.MaxHeaderBytes, err = getInt("HTTP_MAX_HEADER_BYTES", defaultHTTPMaxHeaderBytes); err != nil {
+		return nil, err
+	}
+	maxUploadSize, err := getInt("HTTP_MAX_UPLOAD_SIZE", defaultMaxUploadSize)
+	if err != nil {
+		return nil, err
+	}
+	cfg.HTTP.MaxUploadSize = int64(maxUploadSize)
+	if cfg.HTTP.TrustedProxies, err = getNetworks("HTTP_TRUSTED_PROXIES"); err != nil {
+		return nil, err
+	}
+
+	if cfg.Postgres, err = InitPostgres(); err != nil {
+		return nil, err
+	}
+
+	if cfg.Storage.Endpoint, err = requireEnv("STORAGE_ENDPOINT"); err != nil {
+		return nil, err
+	}
+	cfg.Storage.AccessKey = os.Getenv("STORAGE_ACCESS_KEY")
+	cfg.Storage.SecretKey = os.Getenv("STORAGE_SECRET_KEY")
+	if cfg.Storage.Bucket, err = requireEnv("STORAGE_BUCKET"); err != nil {
+		return nil, err
+	}
+	if cfg.Storage.UseSSL, err = getBool("STORAGE_USE_SSL", true); err != nil {
+		return nil, err
+	}
+
+	if cfg.Auth.SigningKey, err = requireEnv("AUTH_SIGNING_KEY"); err != nil {
+		return nil, err
+	}
+	if cfg.Auth.TokenHashKey, err = requireEnv("AUTH_TOKEN_HASH_KEY"); err != nil {
+		return nil, err
+	}
+	if cfg.Auth.AccessTokenTTL, err = getDuration("AUTH_ACCESS_TOKEN_TTL", defaultAccessTokenTTL); err != nil {
+		return nil, err
+	}
+	if cfg.Auth.RefreshTokenTTL, err = getDuration("AUTH_REFRESH_TOKEN_TTL", defaultRefreshTokenTTL); err != nil {
+		return nil, err
+	}
+	if err := initPasswordConfig(&cfg.Auth.Password); err != nil {
+		return nil, err
+	}
+	if cfg.Auth.PasswordResetTTL, err = getDuration("AUTH_PASSWORD_RESET_TTL", defaultPasswordResetTTL); err != nil {
+		return nil, err
+	}
+	if cfg.Auth.EmailVerificationTTL, err = getDuration("AUTH_EMAIL_VERIFICATION_TTL",
+		defaultVerificationTTL); err != nil {
+		return nil, err
+	}
+
+	cfg.Auth.TwoFactor.Issuer = getEnv("AUTH_TWO_FACTOR_ISSUER", defaultTwoFactorIssuer)
+	if cfg.Auth.TwoFactor.RequiredPermissions, err = getPermissions("AUTH_TWO_FACTOR_REQUIRED_PERMISSIONS",
+		domain.Privileged); err != nil {
+		return nil, err
+	}
+
+	if err := initLockoutConfig(&cfg.Auth.Lockout); err != nil {
+		return nil, err
+	}
+	if err := initPasswordResetLimitConfig(&cfg.Auth.PasswordResetLimit); err != nil {
+		return nil, err
+	}
+
+	if err := initEmailConfig(&cfg.Email); err != nil {
+		return nil, err
+	}
+
+	if cfg.Jobs.MembershipCleanupInterval, err = getDuration("JOBS_MEMBERSHIP_CLEANUP_INTERVAL",
+		defaultMembershipCleanup); err != nil {
+		return nil, err
+	}
+	if cfg.Jobs.MembershipCleanupInterval <= 0 {
+		return nil, fmt.Errorf("JOBS_MEMBERSHIP_CLEANUP_INTERVAL must be positive")
+	}
+	if cfg.Jobs.TrashPurgeInterval, err = getDuration("JOBS_TRASH_PURGE_INTERVAL", defaultTrashPurge); err != nil {
+		return nil, err
+	}
+	if cfg.Jobs.TrashPurgeInterval <= 0 {
+		return nil, fmt.Errorf("JOBS_TRASH_PURGE_INTERVAL must be positive")
+	}
+	if cfg.Jobs.DeletedRetention, err = getDuration("JOBS_DELETED_RETENTION", defaultDeletedRetention); err != nil {
+		return nil, err
+	}
+	if cfg.Jobs.DeletedRetention < 0 {
+		return nil, fmt.Errorf("JOBS_DELETED_RETENTION must not be negative")
+	}
+
+	return &cfg, nil
+}
+
+// InitPostgres reads only the database settings, for commands that need nothing else.
+func InitPostgres() (PostgresConfig, error) {
+	url, err := requireEnv("POSTGRES_URL")
+	if err != nil {
+		return PostgresConfig{}, err
+	}
+	return PostgresConfig{URL: url}, nil
+}
+
+func initPasswordConfig(cfg *PasswordConfig) error {
+	memory, err := getInt("AUTH_PASSWORD_MEMORY", defaultPasswordMemory)
+	if err != nil {
+		return err
+	}
+	iterations, err := getInt("AUTH_PASSWORD_ITERATIONS", defaultPasswordIterations)
+	if err != nil {
+		return err
+	}
+	parallelism, err := getInt("AUTH_PASSWORD_PARALLELISM", defaultPasswordParallelism)
+	if err != nil {
+		return err
+	}
+	if memory <= 0 || iterations <= 0 || parallelism <= 0 || parallelism > 255 {
+		return fmt.Errorf("invalid password hashing parameters")
+	}
+
+	cfg.Memory = uint32(memory)
+	cfg.Iterations = uint32(iterations)
+	cfg.Parallelism = uint8(parallelism)
+	return nil
+}
+
+func initLockoutConfig(cfg *LockoutConfig) error {
+	var err error
+
+	if cfg.AccountFailures, err = getInt("AUTH_LOCKOUT_ACCOUNT_FAILURES", defaultLockoutAccountFailures); err != nil {
+		return err
+	}
+	if cfg.IPFailures, err = getInt("AUTH_LOCKOUT_IP_FAILURES", defaultLockoutIPFailures); err != nil {
+		return err
+	}
+	if cfg.Base, err = getDuration("AUTH_LOCKOUT_BASE", defaultLockoutBase); err != nil {
+		return err
+	}
+	if cfg.Max, err = getDuration("AUTH_LOCKOUT_MAX", defaultLockoutMax); err != nil {
+		return err
+	}
+	if cfg.Window, err = getDuration("AUTH_LOCKOUT_WINDOW", defaultLockoutWindow); err != nil {
+		return err
+	}
+
+	if cfg.AccountFailures <= 0 || cfg.IPFailures <= 0 || cfg.Base <= 0 || cfg.Max < cfg.Base {
+		return fmt.Errorf("invalid lockout parameters")
+	}
+	// A lockout must not outlive the failures it is based on, otherwise every lockout
+	// would start from scratch.
+	if cfg.Window <= cfg.Max {
+		return fmt.Errorf("AUTH_LOCKOUT_WINDOW must be longer than AUTH_LOCKOUT_MAX")
+	}
+	return nil
+}
+
+func initPasswordResetLimitConfig(cfg *PasswordResetLimitConfig) error {
+	var err error
+
+	if cfg.AccountRequests, err = getInt("AUTH_PASSWORD_RESET_ACCOUNT_REQUESTS",
+		defaultPasswordResetAccountRequests); err != nil {
+		return err
+	}
+	if cfg.IPRequests, err = getInt("AUTH_PASSWORD_RESET_IP_REQUESTS", defaultPasswordResetIPRequests); err != nil {
+		return err
+	}
+	if cfg.Window, err = getDuration("AUTH_PASSWORD_RESET_WINDOW", defaultPasswordResetWindow); err != nil {
+		return err
+	}
+
+	if cfg.AccountRequests <= 0 || cfg.IPRequests <= 0 || cfg.Window <= 0 {
+		return fmt.Errorf("invalid password reset limit parameters")
+	}
+	return nil
+}
+
+func initEmailConfig(cfg *EmailConfig) error {
+	var err error
+
+	cfg.Driver = getEnv("EMAIL_DRIVER", "smtp")
+	if cfg.From, err = requireEnv("EMAIL_FROM"); err != nil {
+		return err
+	}
+	if cfg.LinkBaseURL, err = requireEnv("EMAIL_LINK_BASE_URL"); err != nil {
+		return err
+	}
+
+	switch cfg.Driver {
+	case "smtp":
+		if cfg.SMTP.Host, err = requireEnv("SMTP_HOST"); err != nil {
+			return err
+		}
+		if cfg.SMTP.Port, err = getInt("SMTP_PORT", defaultSMTPPort); err != nil {
+			return err
+		}
+		cfg.SMTP.Username = os.Getenv("SMTP_USERNAME")
+		cfg.SMTP.Password = os.Getenv("SMTP_PASSWORD")
+	case "file":
+		cfg.DumpDir = getEnv("EMAIL_DUMP_DIR", "mail")
+	case "memory":
+	default:
+		return fmt.Errorf("unknown EMAIL_DRIVER %q", cfg.Driver)
+	}
+
+	return nil
+}
+
+func getEnv(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	return fallback
+}
+
+func requireEnv(key string) (string, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return "", fmt.Errorf("%s is not set", key)
+	}
+	return value, nil
+}
+
+func getDuration(key string, fallback time.Duration) (time.Duration, error) {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("cannot parse %s: %w", key, err)
+	}
+	return d, nil
+}
+
+func getInt(key string, fallback int) (int, error) {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback, nil
+	}
+
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("cannot parse %s: %w", key, err)
+	}
+	return i, nil
+}
+
+// getNetworks reads a comma separated list of CIDRs. Single addresses stand for networks
+// of their own.
+func getNetworks(key string) ([]*net.IPNet, error) {
+	var networks []*net.IPNet
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		if ip := net.ParseIP(value); ip != nil {
+			bits := 8 * net.IPv6len
+			if ip4 := ip.To4(); ip4 != nil {
+				ip, bits = ip4, 8*net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse %s: %w", key, err)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// getPermissions reads a comma separated list of permission names.
+func getPermissions(key string, fallback domain.Permissions) (domain.Permissions, error) {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback, nil
+	}
+
+	var permissions []domain.Permission
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			permissions = append(permissions, domain.Permission(name))
+		}
+	}
+
+	set := domain.NewPermissions(permissions...)
+	if err := set.Validate(); err != nil {
+		return nil, fmt.Errorf("cannot parse %s: %w", key, err)
+	}
+	return set, nil
+}
+
+func getBool(key string, fallback bool) (bool, error) {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback, nil
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("cannot parse %s: %w", key, err)
+	}
+	return b, nil
+}
@@ -0,0 +1,48 @@
+package http
+
+import (
+	"net"
+	"net/http"
+
+	"foodsharing-backend/internal/config"
+	v1 "foodsharing-backend/internal/delivery/http/v1"
+	"foodsharing-backend/internal/service"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+type Handler struct {
+	services       *service.Services
+	maxUploadSize  int64
+	trustedProxies []*net.IPNet
+}
+
+func NewHandler(services *service.Services, cfg config.HTTPConfig) *Handler {
+	return &Handler{
+		services:       services,
+		maxUploadSize:  cfg.MaxUploadSize,
+		trustedProxies: cfg.TrustedProxies,
+	}
+}
+
+func (h *Handler) Init() http.Handler {
+	router := chi.NewRouter()
+	router.Use(middleware.RequestID, realIP(h.trustedProxies), middleware.Logger, middleware.Recoverer)
+
+	router.Get("/ping", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("pong"))
+	})
+
+	h.initAPI(router)
+
+	return router
+}
+
+func (h *Handler) initAPI(router chi.Router) {
+	handlerV1 := v1.NewHandler(h.services, h.maxUploadSize)
+	router.Route("/api", func(r chi.Router) {
+		handlerV1.Init(r)
+	})
+}
@@ -0,0 +1,61 @@
+package http
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// realIP replaces the remote address of requests coming from a trusted proxy with the
+// address of the client the proxy reports. Forwarding headers of other clients are ignored,
+// as anyone could set them to dodge the per address limits.
+func realIP(trustedProxies []*net.IPNet) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isTrusted(trustedProxies, remoteIP(r.RemoteAddr)) {
+				if ip := forwardedIP(r, trustedProxies); ip != nil {
+					r.RemoteAddr = ip.String()
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// forwardedIP reads X-Forwarded-For from the right: every proxy appends the address it
+// got the request from, so the first untrusted hop is the client. Headers carrying a single
+// address, such as X-Real-IP, are not used as a proxy may pass them on from the client.
+func forwardedIP(r *http.Request, trustedProxies []*net.IPNet) net.IP {
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	var client net.IP
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(hops[i]))
+		if ip == nil {
+			break
+		}
+		client = ip
+		if !isTrusted(trustedProxies, ip) {
+			break
+		}
+	}
+	return client
+}
+
+func remoteIP(addr string) net.IP {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	return net.ParseIP(addr)
+}
+
+func isTrusted(trustedProxies []*net.IPNet, ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	for _, network := range trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
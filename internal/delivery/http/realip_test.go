@@ -0,0 +1,91 @@
+package http
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRealIP(t *testing.T) {
+	_, proxies, err := net.ParseCIDR("10.0.0.0/8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	trusted := []*net.IPNet{proxies}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		headers    map[string]string
+		want       string
+	}{
+		{
+			name:       "direct client",
+			remoteAddr: "203.0.113.7:4321",
+			want:       "203.0.113.7:4321",
+		},
+		{
+			name:       "untrusted client spoofing headers",
+			remoteAddr: "203.0.113.7:4321",
+			headers: map[string]string{
+				"X-Forwarded-For": "198.51.100.1",
+				"X-Real-IP":       "198.51.100.2",
+				"True-Client-IP":  "198.51.100.3",
+			},
+			want: "203.0.113.7:4321",
+		},
+		{
+			name:       "trusted proxy passing on a client supplied real ip",
+			remoteAddr: "10.0.0.2:80",
+			headers: map[string]string{
+				"X-Real-IP":       "198.51.100.2",
+				"True-Client-IP":  "198.51.100.3",
+				"X-Forwarded-For": "203.0.113.7",
+			},
+			want: "203.0.113.7",
+		},
+		{
+			name:       "trusted proxy with only a client supplied real ip",
+			remoteAddr: "10.0.0.2:80",
+			headers:    map[string]string{"X-Real-IP": "198.51.100.2"},
+			want:       "10.0.0.2:80",
+		},
+		{
+			name:       "forwarded for skips trusted hops only",
+			remoteAddr: "10.0.0.2:80",
+			headers:    map[string]string{"X-Forwarded-For": "192.0.2.1, 198.51.100.1, 10.0.0.3"},
+			want:       "198.51.100.1",
+		},
+		{
+			name:       "forwarded for through trusted hops only",
+			remoteAddr: "10.0.0.2:80",
+			headers:    map[string]string{"X-Forwarded-For": "10.0.0.4"},
+			want:       "10.0.0.4",
+		},
+		{
+			name:       "trusted proxy without headers",
+			remoteAddr: "10.0.0.2:80",
+			want:       "10.0.0.2:80",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			for key, value := range tt.headers {
+				r.Header.Set(key, value)
+			}
+
+			var got string
+			realIP(trusted)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.RemoteAddr
+			})).ServeHTTP(httptest.NewRecorder(), r)
+
+			if got != tt.want {
+				t.Errorf("remote address = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
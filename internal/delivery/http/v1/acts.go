@@ -0,0 +1,325 @@
+package v1
+
+import (
+	"net/http"
+
+	"foodsharing-backend/internal/domain"
+	"foodsharing-backend/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *Handler) initActsRoutes(router chi.Router) {
+	router.Route("/acts", func(r chi.Router) {
+		r.With(h.authorize(domain.ActionCreate, domain.ResourceActs)).Post("/", h.createAct)
+		r.Get("/", h.getActs)
+		r.With(h.requireAdmin).Get("/deleted", h.getDeletedActs)
+		r.Route("/{id}", func(r chi.Router) {
+			r.Get("/", h.getAct)
+			r.Get("/contents", h.getActContents)
+			r.Get("/files", h.getActFiles)
+			r.With(h.requireAdmin).Post("/restore", h.restoreAct)
+
+			r.Group(func(r chi.Router) {
+				r.Use(h.authorize(domain.ActionEdit, domain.ResourceActs))
+				r.Put("/", h.updateAct)
+				r.Delete("/", h.deleteAct)
+
+				r.Post("/contents", h.createActContents)
+				r.Put("/contents/{contentID}", h.updateActContent)
+				r.Delete("/contents/{contentID}", h.deleteActContent)
+
+				r.Post("/files", h.addActFile)
+				r.Delete("/files/{fileID}", h.removeActFile)
+			})
+		})
+	})
+}
+
+type actFileInput struct {
+	FileID domain.ID `json:"file_id"`
+}
+
+func (h *Handler) createAct(w http.ResponseWriter, r *http.Request) {
+	var input service.ActInput
+	if err := decodeJSON(r, &input); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	act, err := h.services.Acts.Create(r.Context(), input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, act)
+}
+
+// getActs returns a page of acts, see listOptions for the query parameters. The city_id
+// parameter selects the acts of the donor companies in the city.
+func (h *Handler) getActs(w http.ResponseWriter, r *http.Request) {
+	opts, err := listOptions(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	acts, next, err := h.services.Acts.GetAll(r.Context(), opts)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeList(w, acts, next)
+}
+
+// getDeletedActs returns a page of the deleted acts to admins, see listOptions for
+// the query parameters.
+func (h *Handler) getDeletedActs(w http.ResponseWriter, r *http.Request) {
+	opts, err := listOptions(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	acts, next, err := h.services.Acts.GetDeleted(r.Context(), opts)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeList(w, acts, next)
+}
+
+func (h *Handler) restoreAct(w http.ResponseWriter, r *http.Request) {
+	id, err := urlParamID(r, "id")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := h.services.Acts.Restore(r.Context(), id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) getAct(w http.ResponseWriter, r *http.Request) {
+	id, err := urlParamID(r, "id")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	act, err := h.services.Acts.GetByID(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeVersion(w, act.Version)
+	writeJSON(w, http.StatusOK, act)
+}
+
+func (h *Handler) updateAct(w http.ResponseWriter, r *http.Request) {
+	id, err := urlParamID(r, "id")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var input service.ActUpdateInput
+	if err := decodeJSON(r, &input); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := h.services.Acts.Update(r.Context(), id, input, version); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) deleteAct(w http.ResponseWriter, r *http.Request) {
+	id, err := urlParamID(r, "id")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := h.services.Acts.Delete(r.Context(), id, version); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) getActContents(w http.ResponseWriter, r *http.Request) {
+	id, err := urlParamID(r, "id")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	contents, err := h.services.Acts.GetContents(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, contents)
+}
+
+func (h *Handler) createActContents(w http.ResponseWriter, r *http.Request) {
+	id, err := urlParamID(r, "id")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var inputs []service.ActContentInput
+	if err := decodeJSON(r, &inputs); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := h.services.Acts.AddContents(r.Context(), id, inputs...); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (h *Handler) updateActContent(w http.ResponseWriter, r *http.Request) {
+	id, err := urlParamID(r, "id")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	contentID, err := urlParamID(r, "contentID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var input service.ActContentInput
+	if err := decodeJSON(r, &input); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := h.services.Acts.UpdateContent(r.Context(), id, contentID, input, version); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) deleteActContent(w http.ResponseWriter, r *http.Request) {
+	id, err := urlParamID(r, "id")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	contentID, err := urlParamID(r, "contentID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := h.services.Acts.DeleteContent(r.Context(), id, contentID, version); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) getActFiles(w http.ResponseWriter, r *http.Request) {
+	id, err := urlParamID(r, "id")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	files, err := h.services.Acts.GetFiles(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, files)
+}
+
+func (h *Handler) addActFile(w http.ResponseWriter, r *http.Request) {
+	id, err := urlParamID(r, "id")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var input actFileInput
+	if err := decodeJSON(r, &input); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := h.services.Acts.AddFile(r.Context(), id, input.FileID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) removeActFile(w http.ResponseWriter, r *http.Request) {
+	id, err := urlParamID(r, "id")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	fileID, err := urlParamID(r, "fileID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := h.services.Acts.RemoveFile(r.Context(), id, fileID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,32 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *Handler) initAuditRoutes(router chi.Router) {
+	router.Route("/audit", func(r chi.Router) {
+		r.Get("/", h.getAuditLog)
+	})
+}
+
+// getAuditLog returns a page of the audit log to admins, see listOptions for the query
+// parameters. The log is filtered by actor_id, entity_type, entity_id and the creation
+// time of the entries.
+func (h *Handler) getAuditLog(w http.ResponseWriter, r *http.Request) {
+	opts, err := listOptions(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	entries, next, err := h.services.Audit.GetAll(r.Context(), opts)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeList(w, entries, next)
+}
@@ -0,0 +1,266 @@
+package v1
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+
+	"foodsharing-backend/internal/domain"
+	"foodsharing-backend/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *Handler) initAuthRoutes(router chi.Router) {
+	router.Route("/auth", func(r chi.Router) {
+		r.Post("/sign-up", h.signUp)
+		r.Post("/sign-in", h.signIn)
+		r.Post("/sign-in/2fa", h.signInTwoFactor)
+		r.Post("/sign-in/2fa/enroll", h.enrollTwoFactorOnSignIn)
+		r.Post("/refresh", h.refreshTokens)
+		r.With(h.authenticate).Put("/password", h.changePassword)
+		r.Post("/password/forgot", h.requestPasswordReset)
+		r.Post("/password/reset", h.resetPassword)
+		r.With(h.authenticate).Post("/email/verification", h.requestEmailVerification)
+		r.Post("/email/verify", h.verifyEmail)
+
+		r.With(h.authenticate).Post("/2fa", h.enrollTwoFactor)
+		r.With(h.authenticate).Post("/2fa/confirm", h.confirmTwoFactor)
+		r.With(h.authenticate).Delete("/2fa", h.disableTwoFactor)
+	})
+}
+
+type refreshInput struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type forgotPasswordInput struct {
+	Email string `json:"email"`
+}
+
+type verifyEmailInput struct {
+	Token string `json:"token"`
+}
+
+type twoFactorTokenInput struct {
+	TwoFactorToken string `json:"two_factor_token"`
+}
+
+type twoFactorConfirmInput struct {
+	Code string `json:"code"`
+}
+
+type recoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+func (h *Handler) signUp(w http.ResponseWriter, r *http.Request) {
+	var input service.SignUpInput
+	if err := decodeJSON(r, &input); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	tokens, err := h.services.Auth.SignUp(r.Context(), input, deviceInfo(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, tokens)
+}
+
+func (h *Handler) signIn(w http.ResponseWriter, r *http.Request) {
+	var input service.SignInInput
+	if err := decodeJSON(r, &input); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	result, err := h.services.Auth.SignIn(r.Context(), input, deviceInfo(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (h *Handler) signInTwoFactor(w http.ResponseWriter, r *http.Request) {
+	var input service.SignInTwoFactorInput
+	if err := decodeJSON(r, &input); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	result, err := h.services.Auth.SignInTwoFactor(r.Context(), input, deviceInfo(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (h *Handler) enrollTwoFactorOnSignIn(w http.ResponseWriter, r *http.Request) {
+	var input twoFactorTokenInput
+	if err := decodeJSON(r, &input); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	enrollment, err := h.services.Auth.EnrollTwoFactorOnSignIn(r.Context(), input.TwoFactorToken)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, enrollment)
+}
+
+func (h *Handler) changePassword(w http.ResponseWriter, r *http.Request) {
+	var input service.ChangePasswordInput
+	if err := decodeJSON(r, &input); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := h.services.Auth.ChangePassword(r.Context(), input); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) requestPasswordReset(w http.ResponseWriter, r *http.Request) {
+	var input forgotPasswordInput
+	if err := decodeJSON(r, &input); err != nil {
+		writeError(w, err)
+		return
+	}
+	if input.Email == "" {
+		writeError(w, fmt.Errorf("%w: email is required", domain.InvalidInput))
+		return
+	}
+
+	if err := h.services.Auth.RequestPasswordReset(r.Context(), input.Email, deviceInfo(r)); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (h *Handler) resetPassword(w http.ResponseWriter, r *http.Request) {
+	var input service.ResetPasswordInput
+	if err := decodeJSON(r, &input); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := h.services.Auth.ResetPassword(r.Context(), input); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) requestEmailVerification(w http.ResponseWriter, r *http.Request) {
+	if err := h.services.Auth.RequestEmailVerification(r.Context()); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (h *Handler) verifyEmail(w http.ResponseWriter, r *http.Request) {
+	var input verifyEmailInput
+	if err := decodeJSON(r, &input); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := h.services.Auth.VerifyEmail(r.Context(), input.Token); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) enrollTwoFactor(w http.ResponseWriter, r *http.Request) {
+	enrollment, err := h.services.Auth.EnrollTwoFactor(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, enrollment)
+}
+
+func (h *Handler) confirmTwoFactor(w http.ResponseWriter, r *http.Request) {
+	var input twoFactorConfirmInput
+	if err := decodeJSON(r, &input); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	codes, err := h.services.Auth.ConfirmTwoFactor(r.Context(), input.Code, deviceInfo(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, recoveryCodesResponse{RecoveryCodes: codes})
+}
+
+func (h *Handler) disableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	var input service.TwoFactorCodeInput
+	if err := decodeJSON(r, &input); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := h.services.Auth.DisableTwoFactor(r.Context(), input, deviceInfo(r)); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) refreshTokens(w http.ResponseWriter, r *http.Request) {
+	var input refreshInput
+	if err := decodeJSON(r, &input); err != nil {
+		writeError(w, err)
+		return
+	}
+	if input.RefreshToken == "" {
+		writeError(w, fmt.Errorf("%w: refresh_token is required", domain.InvalidInput))
+		return
+	}
+
+	tokens, err := h.services.Auth.RefreshTokens(r.Context(), input.RefreshToken, deviceInfo(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, tokens)
+}
+
+// deviceInfo describes the client of the request. RemoteAddr already holds the real client
+// address thanks to the RealIP middleware.
+func deviceInfo(r *http.Request) service.DeviceInfo {
+	ip := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+
+	return service.DeviceInfo{
+		UserAgent: r.UserAgent(),
+		IP:        ip,
+	}
+}
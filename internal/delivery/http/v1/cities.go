@@ -0,0 +1,126 @@
+package v1
+
+import (
+	"net/http"
+
+	"foodsharing-backend/internal/domain"
+	"foodsharing-backend/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *Handler) initCitiesRoutes(router chi.Router) {
+	router.Route("/cities", func(r chi.Router) {
+		r.With(h.authorize(domain.ActionCreate, domain.ResourceCities)).Post("/", h.createCity)
+
+		r.Group(func(r chi.Router) {
+			r.Use(h.authorize(domain.ActionRead, domain.ResourceCities))
+			r.Get("/", h.getCities)
+			r.Get("/{id}", h.getCity)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(h.authorize(domain.ActionEdit, domain.ResourceCities))
+			r.Put("/{id}", h.updateCity)
+			r.Delete("/{id}", h.deleteCity)
+		})
+	})
+}
+
+func (h *Handler) createCity(w http.ResponseWriter, r *http.Request) {
+	var input service.CityInput
+	if err := decodeJSON(r, &input); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	city, err := h.services.Cities.Create(r.Context(), input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, city)
+}
+
+// getCities returns a page of cities, see listOptions for the query parameters.
+func (h *Handler) getCities(w http.ResponseWriter, r *http.Request) {
+	opts, err := listOptions(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	cities, next, err := h.services.Cities.GetAll(r.Context(), opts)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeList(w, cities, next)
+}
+
+func (h *Handler) getCity(w http.ResponseWriter, r *http.Request) {
+	id, err := urlParamID(r, "id")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	city, err := h.services.Cities.GetByID(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeVersion(w, city.Version)
+	writeJSON(w, http.StatusOK, city)
+}
+
+func (h *Handler) updateCity(w http.ResponseWriter, r *http.Request) {
+	id, err := urlParamID(r, "id")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var input service.CityInput
+	if err := decodeJSON(r, &input); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := h.services.Cities.Update(r.Context(), id, input, version); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) deleteCity(w http.ResponseWriter, r *http.Request) {
+	id, err := urlParamID(r, "id")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := h.services.Cities.Delete(r.Context(), id, version); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,166 @@
+package v1
+
+import (
+	"net/http"
+
+	"foodsharing-backend/internal/domain"
+	"foodsharing-backend/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *Handler) initDonorCompaniesRoutes(router chi.Router) {
+	router.Route("/donor-companies", func(r chi.Router) {
+		r.With(h.authorize(domain.ActionCreate, domain.ResourceCompanies)).Post("/", h.createDonorCompany)
+
+		r.Group(func(r chi.Router) {
+			r.Use(h.authorize(domain.ActionRead, domain.ResourceCompanies))
+			r.Get("/", h.getDonorCompanies)
+			r.Get("/{id}", h.getDonorCompany)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(h.authorize(domain.ActionEdit, domain.ResourceCompanies))
+			r.Put("/{id}", h.updateDonorCompany)
+			r.Delete("/{id}", h.deleteDonorCompany)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(h.requireAdmin)
+			r.Get("/deleted", h.getDeletedDonorCompanies)
+			r.Post("/{id}/restore", h.restoreDonorCompany)
+		})
+	})
+}
+
+func (h *Handler) createDonorCompany(w http.ResponseWriter, r *http.Request) {
+	var input service.DonorCompanyInput
+	if err := decodeJSON(r, &input); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	company, err := h.services.DonorCompanies.Create(r.Context(), input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, company)
+}
+
+// getDonorCompanies returns a page of donor companies, see listOptions for the query
+// parameters.
+func (h *Handler) getDonorCompanies(w http.ResponseWriter, r *http.Request) {
+	opts, err := listOptions(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	companies, next, err := h.services.DonorCompanies.GetAll(r.Context(), opts)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeList(w, companies, next)
+}
+
+func (h *Handler) getDonorCompany(w http.ResponseWriter, r *http.Request) {
+	id, err := urlParamID(r, "id")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	company, err := h.services.DonorCompanies.GetByID(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeVersion(w, company.Version)
+	writeJSON(w, http.StatusOK, company)
+}
+
+func (h *Handler) updateDonorCompany(w http.ResponseWriter, r *http.Request) {
+	id, err := urlParamID(r, "id")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var input service.DonorCompanyInput
+	if err := decodeJSON(r, &input); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := h.services.DonorCompanies.Update(r.Context(), id, input, version); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) deleteDonorCompany(w http.ResponseWriter, r *http.Request) {
+	id, err := urlParamID(r, "id")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := h.services.DonorCompanies.Delete(r.Context(), id, version); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getDeletedDonorCompanies returns a page of the deleted donor companies to admins, see
+// listOptions for the query parameters.
+func (h *Handler) getDeletedDonorCompanies(w http.ResponseWriter, r *http.Request) {
+	opts, err := listOptions(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	companies, next, err := h.services.DonorCompanies.GetDeleted(r.Context(), opts)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeList(w, companies, next)
+}
+
+func (h *Handler) restoreDonorCompany(w http.ResponseWriter, r *http.Request) {
+	id, err := urlParamID(r, "id")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := h.services.DonorCompanies.Restore(r.Context(), id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
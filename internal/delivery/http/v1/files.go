@@ -0,0 +1,63 @@
+package v1
+
+import (
+	"fmt"
+	"net/http"
+
+	"foodsharing-backend/internal/domain"
+	"foodsharing-backend/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *Handler) initFilesRoutes(router chi.Router) {
+	router.Route("/files", func(r chi.Router) {
+		r.Post("/", h.uploadFile)
+		r.Get("/{id}", h.getFile)
+	})
+}
+
+// uploadFile accepts a multipart form with the file in the "file" field.
+func (h *Handler) uploadFile(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxUploadSize)
+	if err := r.ParseMultipartForm(h.maxUploadSize); err != nil {
+		writeError(w, fmt.Errorf("%w: cannot parse multipart form: %v", domain.InvalidInput, err))
+		return
+	}
+
+	src, header, err := r.FormFile("file")
+	if err != nil {
+		writeError(w, fmt.Errorf("%w: file is required", domain.InvalidInput))
+		return
+	}
+	defer src.Close()
+
+	file, err := h.services.Files.Upload(r.Context(), service.FileUploadInput{
+		File:        src,
+		Name:        header.Filename,
+		Size:        header.Size,
+		ContentType: header.Header.Get("Content-Type"),
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, file)
+}
+
+func (h *Handler) getFile(w http.ResponseWriter, r *http.Request) {
+	id, err := urlParamID(r, "id")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	file, err := h.services.Files.GetByID(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, file)
+}
@@ -0,0 +1,178 @@
+package v1
+
+import (
+	"net/http"
+
+	"foodsharing-backend/internal/domain"
+	"foodsharing-backend/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *Handler) initGroupsRoutes(router chi.Router) {
+	router.Route("/groups", func(r chi.Router) {
+		r.With(h.authorize(domain.ActionCreate, domain.ResourceGroups)).Post("/", h.createGroup)
+
+		r.Group(func(r chi.Router) {
+			r.Use(h.authorize(domain.ActionRead, domain.ResourceGroups))
+			r.Get("/", h.getGroups)
+			r.Get("/{id}", h.getGroup)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(h.authorize(domain.ActionEdit, domain.ResourceGroups))
+			r.Put("/{id}", h.updateGroup)
+			r.Delete("/{id}", h.deleteGroup)
+			r.Post("/{id}/users", h.addUserToGroup)
+			r.Delete("/{id}/users/{userID}", h.removeUserFromGroup)
+		})
+	})
+}
+
+func (h *Handler) createGroup(w http.ResponseWriter, r *http.Request) {
+	var input service.GroupInput
+	if err := decodeJSON(r, &input); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	group, err := h.services.Groups.Create(r.Context(), input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, group)
+}
+
+// getGroups returns a page of groups, see listOptions for the query parameters. The name
+// parameter is matched as a LIKE pattern.
+func (h *Handler) getGroups(w http.ResponseWriter, r *http.Request) {
+	opts, err := listOptions(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	groups, next, err := h.services.Groups.GetAll(r.Context(), opts)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeList(w, groups, next)
+}
+
+func (h *Handler) getGroup(w http.ResponseWriter, r *http.Request) {
+	id, err := urlParamID(r, "id")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	group, err := h.services.Groups.GetByID(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeVersion(w, group.Version)
+	writeJSON(w, http.StatusOK, group)
+}
+
+func (h *Handler) updateGroup(w http.ResponseWriter, r *http.Request) {
+	id, err := urlParamID(r, "id")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var input service.GroupInput
+	if err := decodeJSON(r, &input); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := h.services.Groups.Update(r.Context(), id, input, version); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) deleteGroup(w http.ResponseWriter, r *http.Request) {
+	id, err := urlParamID(r, "id")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := h.services.Groups.Delete(r.Context(), id, version); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) addUserToGroup(w http.ResponseWriter, r *http.Request) {
+	id, err := urlParamID(r, "id")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var input service.GroupMemberInput
+	if err := decodeJSON(r, &input); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := h.services.Groups.AddUser(r.Context(), id, input); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// removeUserFromGroup removes the membership limited to the city_id query parameter or,
+// without it, the global membership.
+func (h *Handler) removeUserFromGroup(w http.ResponseWriter, r *http.Request) {
+	id, err := urlParamID(r, "id")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	userID, err := urlParamID(r, "userID")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	cityID, err := queryParamID(r, "city_id")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := h.services.Groups.RemoveUser(r.Context(), id, userID, cityID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,38 @@
+package v1
+
+import (
+	"foodsharing-backend/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type Handler struct {
+	services      *service.Services
+	maxUploadSize int64
+}
+
+func NewHandler(services *service.Services, maxUploadSize int64) *Handler {
+	return &Handler{
+		services:      services,
+		maxUploadSize: maxUploadSize,
+	}
+}
+
+func (h *Handler) Init(router chi.Router) {
+	router.Route("/v1", func(r chi.Router) {
+		h.initAuthRoutes(r)
+
+		r.Group(func(r chi.Router) {
+			r.Use(h.authenticate)
+
+			h.initUsersRoutes(r)
+			h.initGroupsRoutes(r)
+			h.initCitiesRoutes(r)
+			h.initRegionsRoutes(r)
+			h.initDonorCompaniesRoutes(r)
+			h.initActsRoutes(r)
+			h.initFilesRoutes(r)
+			h.initAuditRoutes(r)
+		})
+	})
+}
@@ -0,0 +1,70 @@
+package v1
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"foodsharing-backend/internal/domain"
+)
+
+const authorizationHeader = "Authorization"
+
+// authenticate rejects requests without a valid bearer access token and stores the
+// principal of the token in the request context.
+func (h *Handler) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get(authorizationHeader)
+		token := strings.TrimPrefix(header, "Bearer ")
+		if header == "" || token == header || token == "" {
+			writeError(w, fmt.Errorf("%w: missing bearer token", domain.Unauthorized))
+			return
+		}
+
+		principal, err := h.services.Auth.Authenticate(r.Context(), token)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(domain.WithPrincipal(r.Context(), principal)))
+	})
+}
+
+// authorize rejects requests whose principal may not perform the action on the resource.
+// Routes where users may also act on their own records leave the check to the services.
+func (h *Handler) authorize(action domain.Action, resource domain.Resource) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := domain.PrincipalFromContext(r.Context())
+			if !ok {
+				writeError(w, domain.Unauthorized)
+				return
+			}
+			if !h.services.Authorizer.Can(principal, action, resource) {
+				writeError(w, fmt.Errorf("%w: cannot %s %s", domain.Forbidden, action, resource))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// requireAdmin rejects requests of principals who are not admins, for the routes that
+// list and restore deleted records.
+func (h *Handler) requireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := domain.PrincipalFromContext(r.Context())
+		if !ok {
+			writeError(w, domain.Unauthorized)
+			return
+		}
+		if !principal.Permissions.IsAdmin() {
+			writeError(w, fmt.Errorf("%w: only admins may see and restore deleted records", domain.Forbidden))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
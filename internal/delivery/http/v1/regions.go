@@ -0,0 +1,126 @@
+package v1
+
+import (
+	"net/http"
+
+	"foodsharing-backend/internal/domain"
+	"foodsharing-backend/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *Handler) initRegionsRoutes(router chi.Router) {
+	router.Route("/regions", func(r chi.Router) {
+		r.With(h.authorize(domain.ActionCreate, domain.ResourceCities)).Post("/", h.createRegion)
+
+		r.Group(func(r chi.Router) {
+			r.Use(h.authorize(domain.ActionRead, domain.ResourceCities))
+			r.Get("/", h.getRegions)
+			r.Get("/{id}", h.getRegion)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(h.authorize(domain.ActionEdit, domain.ResourceCities))
+			r.Put("/{id}", h.updateRegion)
+			r.Delete("/{id}", h.deleteRegion)
+		})
+	})
+}
+
+func (h *Handler) createRegion(w http.ResponseWriter, r *http.Request) {
+	var input service.RegionInput
+	if err := decodeJSON(r, &input); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	region, err := h.services.Regions.Create(r.Context(), input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, region)
+}
+
+// getRegions returns a page of regions, see listOptions for the query parameters.
+func (h *Handler) getRegions(w http.ResponseWriter, r *http.Request) {
+	opts, err := listOptions(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	regions, next, err := h.services.Regions.GetAll(r.Context(), opts)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeList(w, regions, next)
+}
+
+func (h *Handler) getRegion(w http.ResponseWriter, r *http.Request) {
+	id, err := urlParamID(r, "id")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	region, err := h.services.Regions.GetByID(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeVersion(w, region.Version)
+	writeJSON(w, http.StatusOK, region)
+}
+
+func (h *Handler) updateRegion(w http.ResponseWriter, r *http.Request) {
+	id, err := urlParamID(r, "id")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var input service.RegionInput
+	if err := decodeJSON(r, &input); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := h.services.Regions.Update(r.Context(), id, input, version); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) deleteRegion(w http.ResponseWriter, r *http.Request) {
+	id, err := urlParamID(r, "id")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := h.services.Regions.Delete(r.Context(), id, version); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
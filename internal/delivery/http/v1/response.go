@@ -0,0 +1,191 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"foodsharing-backend/internal/domain"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type errorResponse struct {
+	Message string `json:"message"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("cannot encode response: %v", err)
+	}
+}
+
+// writeError maps domain errors to HTTP status codes. Unknown errors are logged and hidden
+// from the client behind a generic message.
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.NotFound):
+		writeJSON(w, http.StatusNotFound, errorResponse{Message: err.Error()})
+	case errors.Is(err, domain.AlreadyExists), errors.Is(err, domain.InUse):
+		writeJSON(w, http.StatusConflict, errorResponse{Message: err.Error()})
+	case errors.Is(err, domain.Conflict):
+		writeJSON(w, http.StatusPreconditionFailed, errorResponse{Message: err.Error()})
+	case errors.Is(err, domain.PreconditionRequired):
+		writeJSON(w, http.StatusPreconditionRequired, errorResponse{Message: err.Error()})
+	case errors.Is(err, domain.InvalidInput):
+		writeJSON(w, http.StatusUnprocessableEntity, errorResponse{Message: err.Error()})
+	case errors.Is(err, domain.Unauthorized):
+		writeJSON(w, http.StatusUnauthorized, errorResponse{Message: err.Error()})
+	case errors.Is(err, domain.Forbidden):
+		writeJSON(w, http.StatusForbidden, errorResponse{Message: err.Error()})
+	case errors.Is(err, domain.TooManyAttempts):
+		var locked domain.LockedError
+		if errors.As(err, &locked) {
+			retryAfter := int(math.Ceil(time.Until(locked.Until).Seconds()))
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+		}
+		writeJSON(w, http.StatusTooManyRequests, errorResponse{Message: err.Error()})
+	default:
+		log.Printf("internal error: %v", err)
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Message: "internal server error"})
+	}
+}
+
+func decodeJSON(r *http.Request, v interface{}) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return fmt.Errorf("%w: cannot decode request body: %v", domain.InvalidInput, err)
+	}
+	return nil
+}
+
+func parseID(value string) (domain.ID, error) {
+	id, err := strconv.ParseUint(value, 10, 64)
+	if err != nil || id == 0 {
+		return 0, fmt.Errorf("%w: invalid id %q", domain.InvalidInput, value)
+	}
+	return domain.ID(id), nil
+}
+
+// writeVersion sets the ETag of a single record to its version, which clients send back
+// in If-Match when updating or deleting it.
+func writeVersion(w http.ResponseWriter, version int64) {
+	w.Header().Set("ETag", strconv.Quote(strconv.FormatInt(version, 10)))
+}
+
+// ifMatchVersion returns the version in the If-Match header. Changes of versioned records
+// must send it, so that they cannot overwrite changes they have not seen by accident. "*"
+// is the explicit opt-out and returns zero, which skips the version check. Weak tags never
+// match, as If-Match compares strongly.
+func ifMatchVersion(r *http.Request) (int64, error) {
+	value := strings.TrimSpace(r.Header.Get("If-Match"))
+	switch {
+	case value == "":
+		return 0, fmt.Errorf("%w: If-Match is required, send the ETag of the record or *",
+			domain.PreconditionRequired)
+	case value == "*":
+		return 0, nil
+	case strings.HasPrefix(value, "W/"):
+		return 0, fmt.Errorf("%w: weak If-Match %q never matches", domain.Conflict, value)
+	}
+
+	tag := strings.TrimSuffix(strings.TrimPrefix(value, `"`), `"`)
+	version, err := strconv.ParseInt(tag, 10, 64)
+	if err != nil || version <= 0 || len(tag) != len(value)-2 {
+		return 0, fmt.Errorf("%w: invalid If-Match %q", domain.InvalidInput, value)
+	}
+	return version, nil
+}
+
+func urlParamID(r *http.Request, key string) (domain.ID, error) {
+	return parseID(chi.URLParam(r, key))
+}
+
+// queryParamID returns zero when the query parameter is absent.
+func queryParamID(r *http.Request, key string) (domain.ID, error) {
+	value := r.URL.Query().Get(key)
+	if value == "" {
+		return 0, nil
+	}
+	return parseID(value)
+}
+
+// listResponse is a page of a list. NextCursor is passed as the cursor query parameter
+// to get the next page and is left out on the last page.
+type listResponse struct {
+	Items      interface{} `json:"items"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
+
+// writeList writes a page of items, which must be a slice.
+func writeList(w http.ResponseWriter, items interface{}, nextCursor string) {
+	if value := reflect.ValueOf(items); value.Kind() == reflect.Slice && value.IsNil() {
+		items = []struct{}{}
+	}
+	writeJSON(w, http.StatusOK, listResponse{Items: items, NextCursor: nextCursor})
+}
+
+// listOptions reads the query parameters of lists: cursor, limit, sort and order for
+// paging and city_id, donor_company_id, user_id, region_id, name, actor_id, entity_type,
+// entity_id, created_from and created_to for filtering. The times are in RFC 3339 format.
+func listOptions(r *http.Request) (domain.ListOptions, error) {
+	query := r.URL.Query()
+	opts := domain.ListOptions{
+		Cursor: query.Get("cursor"),
+		Sort:   query.Get("sort"),
+		Order:  domain.SortOrder(query.Get("order")),
+		Filter: domain.ListFilter{
+			Name:       query.Get("name"),
+			EntityType: domain.AuditEntity(query.Get("entity_type")),
+		},
+	}
+
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil {
+			return domain.ListOptions{}, fmt.Errorf("%w: invalid limit %q", domain.InvalidInput, value)
+		}
+		opts.Limit = limit
+	}
+
+	ids := map[string]*domain.ID{
+		"city_id":          &opts.Filter.CityID,
+		"donor_company_id": &opts.Filter.DonorCompanyID,
+		"user_id":          &opts.Filter.UserID,
+		"region_id":        &opts.Filter.RegionID,
+		"actor_id":         &opts.Filter.ActorID,
+		"entity_id":        &opts.Filter.EntityID,
+	}
+	for key, id := range ids {
+		var err error
+		if *id, err = queryParamID(r, key); err != nil {
+			return domain.ListOptions{}, err
+		}
+	}
+
+	times := map[string]**time.Time{
+		"created_from": &opts.Filter.CreatedFrom,
+		"created_to":   &opts.Filter.CreatedTo,
+	}
+	for key, t := range times {
+		value := query.Get(key)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return domain.ListOptions{}, fmt.Errorf("%w: invalid %s %q", domain.InvalidInput, key, value)
+		}
+		*t = &parsed
+	}
+
+	return opts, nil
+}
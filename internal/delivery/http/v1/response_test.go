@@ -0,0 +1,71 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"foodsharing-backend/internal/domain"
+)
+
+func TestIfMatchVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		want    int64
+		wantErr error
+	}{
+		{name: "missing", header: "", wantErr: domain.PreconditionRequired},
+		{name: "blank", header: "  ", wantErr: domain.PreconditionRequired},
+		{name: "opt-out", header: "*", want: 0},
+		{name: "version", header: `"3"`, want: 3},
+		{name: "padded version", header: ` "42" `, want: 42},
+		{name: "weak tag", header: `W/"3"`, wantErr: domain.Conflict},
+		{name: "unquoted", header: "3", wantErr: domain.InvalidInput},
+		{name: "zero", header: `"0"`, wantErr: domain.InvalidInput},
+		{name: "negative", header: `"-1"`, wantErr: domain.InvalidInput},
+		{name: "not a number", header: `"abc"`, wantErr: domain.InvalidInput},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPut, "/", nil)
+			if tt.header != "" {
+				r.Header.Set("If-Match", tt.header)
+			}
+
+			got, err := ifMatchVersion(r)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("ifMatchVersion() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ifMatchVersion() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ifMatchVersion() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteErrorVersions(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{domain.PreconditionRequired, http.StatusPreconditionRequired},
+		{domain.Conflict, http.StatusPreconditionFailed},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		writeError(w, tt.err)
+		if w.Code != tt.want {
+			t.Errorf("writeError(%v) status = %d, want %d", tt.err, w.Code, tt.want)
+		}
+	}
+}
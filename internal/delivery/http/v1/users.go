@@ -0,0 +1,309 @@
+package v1
+
+import (
+	"net/http"
+
+	"foodsharing-backend/internal/domain"
+	"foodsharing-backend/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *Handler) initUsersRoutes(router chi.Router) {
+	router.Route("/users", func(r chi.Router) {
+		r.With(h.authorize(domain.ActionCreate, domain.ResourceUsers)).Post("/", h.createUser)
+		r.With(h.authorize(domain.ActionRead, domain.ResourceUsers)).Get("/", h.getAllUsers)
+		r.Get("/{id}", h.getUser)
+		r.Put("/{id}", h.updateUser)
+		r.Get("/{id}/groups", h.getUserGroups)
+		r.Get("/{id}/permissions/explain", h.explainUserPermission)
+
+		r.Get("/{id}/sessions", h.getUserSessions)
+		r.Delete("/{id}/sessions", h.revokeAllUserSessions)
+		r.Delete("/{id}/sessions/{sessionID}", h.revokeUserSession)
+
+		r.Group(func(r chi.Router) {
+			r.Use(h.authorize(domain.ActionEdit, domain.ResourceUsers))
+			r.Delete("/{id}", h.deleteUser)
+			r.Delete("/{id}/2fa", h.resetUserTwoFactor)
+			r.Get("/{id}/lockout", h.getUserLockout)
+			r.Delete("/{id}/lockout", h.unlockUser)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(h.requireAdmin)
+			r.Get("/deleted", h.getDeletedUsers)
+			r.Post("/{id}/restore", h.restoreUser)
+		})
+	})
+}
+
+func (h *Handler) createUser(w http.ResponseWriter, r *http.Request) {
+	var input service.UserInput
+	if err := decodeJSON(r, &input); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	user, err := h.services.Users.Create(r.Context(), input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, user)
+}
+
+// getAllUsers returns a page of users, see listOptions for the query parameters.
+func (h *Handler) getAllUsers(w http.ResponseWriter, r *http.Request) {
+	opts, err := listOptions(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	users, next, err := h.services.Users.GetAll(r.Context(), opts)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeList(w, users, next)
+}
+
+// getDeletedUsers returns a page of the deleted users to admins, see listOptions for
+// the query parameters.
+func (h *Handler) getDeletedUsers(w http.ResponseWriter, r *http.Request) {
+	opts, err := listOptions(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	users, next, err := h.services.Users.GetDeleted(r.Context(), opts)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeList(w, users, next)
+}
+
+func (h *Handler) restoreUser(w http.ResponseWriter, r *http.Request) {
+	id, err := urlParamID(r, "id")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := h.services.Users.Restore(r.Context(), id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) getUser(w http.ResponseWriter, r *http.Request) {
+	id, err := urlParamID(r, "id")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	user, err := h.services.Users.GetByID(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeVersion(w, user.Version)
+	writeJSON(w, http.StatusOK, user)
+}
+
+func (h *Handler) updateUser(w http.ResponseWriter, r *http.Request) {
+	id, err := urlParamID(r, "id")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var input service.UserInput
+	if err := decodeJSON(r, &input); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := h.services.Users.Update(r.Context(), id, input, version); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) deleteUser(w http.ResponseWriter, r *http.Request) {
+	id, err := urlParamID(r, "id")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := h.services.Users.Delete(r.Context(), id, version); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) getUserGroups(w http.ResponseWriter, r *http.Request) {
+	id, err := urlParamID(r, "id")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	groups, err := h.services.Users.GetGroups(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, groups)
+}
+
+// explainUserPermission takes the action and resource to evaluate from the query along
+// with the optional city_id and owner_id of the record.
+func (h *Handler) explainUserPermission(w http.ResponseWriter, r *http.Request) {
+	id, err := urlParamID(r, "id")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	input := service.PermissionQuery{
+		Action:   domain.Action(r.URL.Query().Get("action")),
+		Resource: domain.Resource(r.URL.Query().Get("resource")),
+	}
+	if input.CityID, err = queryParamID(r, "city_id"); err != nil {
+		writeError(w, err)
+		return
+	}
+	if input.OwnerID, err = queryParamID(r, "owner_id"); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	explanation, err := h.services.Users.ExplainPermission(r.Context(), id, input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, explanation)
+}
+
+func (h *Handler) getUserSessions(w http.ResponseWriter, r *http.Request) {
+	id, err := urlParamID(r, "id")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	sessions, err := h.services.Auth.GetSessions(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, sessions)
+}
+
+func (h *Handler) revokeUserSession(w http.ResponseWriter, r *http.Request) {
+	id, err := urlParamID(r, "id")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := h.services.Auth.RevokeSession(r.Context(), id, chi.URLParam(r, "sessionID")); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) revokeAllUserSessions(w http.ResponseWriter, r *http.Request) {
+	id, err := urlParamID(r, "id")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := h.services.Auth.RevokeAllSessions(r.Context(), id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) resetUserTwoFactor(w http.ResponseWriter, r *http.Request) {
+	id, err := urlParamID(r, "id")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := h.services.Auth.ResetTwoFactor(r.Context(), id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) getUserLockout(w http.ResponseWriter, r *http.Request) {
+	id, err := urlParamID(r, "id")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	attempts, err := h.services.Auth.GetLockout(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, attempts)
+}
+
+func (h *Handler) unlockUser(w http.ResponseWriter, r *http.Request) {
+	id, err := urlParamID(r, "id")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := h.services.Auth.Unlock(r.Context(), id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -4,17 +4,17 @@ import "time"
 
 type Act struct {
 	Object
-	UserID         ID
-	DonorCompanyID ID
+	UserID         ID `json:"user_id"`
+	DonorCompanyID ID `json:"donor_company_id"`
 }
 
 type ActContent struct {
 	Object
-	ActID          ID
-	Number         int
-	Name           string
-	Count          int
-	Price          int
-	ExpirationDate time.Time
-	Comment        string
+	ActID          ID        `json:"act_id"`
+	Number         int       `json:"number"`
+	Name           string    `json:"name"`
+	Count          int       `json:"count"`
+	Price          int       `json:"price"`
+	ExpirationDate time.Time `json:"expiration_date"`
+	Comment        string    `json:"comment"`
 }
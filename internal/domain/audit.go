@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type (
+	AuditAction string
+	AuditEntity string
+)
+
+// Deleting a record that is kept for restoring is a delete, removing it for good later
+// is a purge.
+const (
+	AuditCreate  AuditAction = "create"
+	AuditUpdate  AuditAction = "update"
+	AuditDelete  AuditAction = "delete"
+	AuditRestore AuditAction = "restore"
+	AuditPurge   AuditAction = "purge"
+)
+
+// The entities of the audit log. Group permissions are identified by the group, group
+// memberships by the user and the files of acts by the act.
+const (
+	AuditUser            AuditEntity = "user"
+	AuditGroup           AuditEntity = "group"
+	AuditGroupPermission AuditEntity = "group_permission"
+	AuditMembership      AuditEntity = "membership"
+	AuditRegion          AuditEntity = "region"
+	AuditCity            AuditEntity = "city"
+	AuditDonorCompany    AuditEntity = "donor_company"
+	AuditAct             AuditEntity = "act"
+	AuditActContent      AuditEntity = "act_content"
+	AuditActFile         AuditEntity = "act_file"
+	AuditFile            AuditEntity = "file"
+)
+
+// AuditEntry is a change of a record. ActorID is zero for changes made without a signed-in
+// user, by background jobs for example. Before is empty for creations and After for
+// deletions, otherwise they hold the row as stored.
+type AuditEntry struct {
+	ID         ID              `json:"id"`
+	ActorID    ID              `json:"actor_id,omitempty"`
+	Action     AuditAction     `json:"action"`
+	EntityType AuditEntity     `json:"entity_type"`
+	EntityID   ID              `json:"entity_id"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
@@ -1,6 +1,57 @@
 package domain
 
+import (
+	"fmt"
+	"time"
+)
+
+// City is where users live and donor companies are located. Timezone is an IANA name,
+// the zone date-only fields of the records located in the city are meant in.
 type City struct {
 	Object
-	Name string
+	Name        string       `json:"name"`
+	RegionID    ID           `json:"region_id,omitempty"`
+	Timezone    string       `json:"timezone"`
+	Coordinates *Coordinates `json:"coordinates,omitempty"`
+}
+
+// Coordinates are a WGS 84 position in degrees.
+type Coordinates struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+func (c Coordinates) IsValid() bool {
+	return c.Latitude >= -90 && c.Latitude <= 90 && c.Longitude >= -180 && c.Longitude <= 180
+}
+
+// Location returns the time zone of the city.
+func (c City) Location() (*time.Location, error) {
+	location, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone of city %d: %w", c.ID, err)
+	}
+	return location, nil
+}
+
+// DateIn returns the start of the calendar day of date in the location. Date-only fields,
+// such as ActContent.ExpirationDate and DonorCompany.ContractDate, are stored without a
+// zone: the services place them in the zone of the city this way before handing them out,
+// so that they name the day of the city wherever they are read.
+func DateIn(date time.Time, location *time.Location) time.Time {
+	year, month, day := date.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, location)
+}
+
+// CitySummary is a city with the number of users and donor companies located in it.
+type CitySummary struct {
+	City
+	Users          int `json:"users"`
+	DonorCompanies int `json:"donor_companies"`
+}
+
+// Region groups cities.
+type Region struct {
+	Object
+	Name string `json:"name"`
 }
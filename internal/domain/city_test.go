@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCityLocation(t *testing.T) {
+	location, err := City{Timezone: "Asia/Vladivostok"}.Location()
+	if err != nil {
+		t.Fatalf("Location() error = %v", err)
+	}
+	if location.String() != "Asia/Vladivostok" {
+		t.Errorf("Location() = %s, want Asia/Vladivostok", location)
+	}
+
+	if _, err := (City{Timezone: "Mars/Olympus"}).Location(); err == nil {
+		t.Errorf("Location() of an unknown zone succeeded")
+	}
+}
+
+func TestDateIn(t *testing.T) {
+	vladivostok, err := time.LoadLocation("Asia/Vladivostok")
+	if err != nil {
+		t.Fatal(err)
+	}
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		date     time.Time
+		location *time.Location
+		want     string
+	}{
+		{
+			name:     "stored date east of UTC",
+			date:     time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC),
+			location: vladivostok,
+			want:     "2024-05-01T00:00:00+10:00",
+		},
+		{
+			name:     "stored date west of UTC",
+			date:     time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC),
+			location: newYork,
+			want:     "2024-05-01T00:00:00-04:00",
+		},
+		{
+			name:     "time of day is dropped",
+			date:     time.Date(2024, time.January, 31, 23, 30, 0, 0, time.UTC),
+			location: newYork,
+			want:     "2024-01-31T00:00:00-05:00",
+		},
+		{
+			name:     "day is taken in the zone of the date",
+			date:     time.Date(2024, time.May, 1, 0, 0, 0, 0, vladivostok),
+			location: time.UTC,
+			want:     "2024-05-01T00:00:00Z",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DateIn(tt.date, tt.location).Format(time.RFC3339); got != tt.want {
+				t.Errorf("DateIn() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,10 @@
+package domain
+
+import "time"
+
+type Credentials struct {
+	UserID       ID
+	PasswordHash string
+	CreatedAt    time.Time
+	UpdatedAt    *time.Time
+}
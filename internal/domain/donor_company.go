@@ -4,8 +4,8 @@ import "time"
 
 type DonorCompany struct {
 	Object
-	Name           string
-	CityID         ID
-	ContractDate   time.Time
-	ContractNumber int
+	Name           string    `json:"name"`
+	CityID         ID        `json:"city_id"`
+	ContractDate   time.Time `json:"contract_date"`
+	ContractNumber int       `json:"contract_number"`
 }
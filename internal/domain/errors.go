@@ -1,7 +1,38 @@
 package domain
 
-import "foodsharing-backend/pkg/errors"
+import (
+	"fmt"
+	"time"
+
+	"foodsharing-backend/pkg/errors"
+)
 
 const (
-	NotFound errors.Error = "record not found"
+	NotFound      errors.Error = "record not found"
+	AlreadyExists errors.Error = "record already exists"
+	InvalidInput  errors.Error = "invalid input"
+	Unauthorized  errors.Error = "unauthorized"
+	Forbidden     errors.Error = "forbidden"
+	// InUse means that the record cannot be deleted while other records refer to it.
+	InUse errors.Error = "record is in use"
+	// Conflict means that the record has changed since the version an update was based on.
+	Conflict errors.Error = "record has been changed"
+	// PreconditionRequired means that a change of a record does not name the version it is
+	// based on.
+	PreconditionRequired errors.Error = "precondition required"
+	// TooManyAttempts means that the request is blocked for a while, see LockedError.
+	TooManyAttempts errors.Error = "too many attempts"
 )
+
+// LockedError tells until when sign-ins are blocked. It matches TooManyAttempts.
+type LockedError struct {
+	Until time.Time
+}
+
+func (e LockedError) Error() string {
+	return fmt.Sprintf("%s: try again after %s", TooManyAttempts, e.Until.UTC().Format(time.RFC3339))
+}
+
+func (e LockedError) Is(target error) bool {
+	return target == TooManyAttempts
+}
@@ -22,11 +22,11 @@ const (
 
 type File struct {
 	Object
-	UserID      ID
-	Type        FileType
-	ContentType string
-	Name        string
-	Size        int64
-	Status      FileStatus
-	URL         string
+	UserID      ID         `json:"user_id"`
+	Type        FileType   `json:"type"`
+	ContentType string     `json:"content_type"`
+	Name        string     `json:"name"`
+	Size        int64      `json:"size"`
+	Status      FileStatus `json:"status"`
+	URL         string     `json:"url,omitempty"`
 }
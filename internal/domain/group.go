@@ -1,7 +1,33 @@
 package domain
 
+import "time"
+
+// Group is a set of permissions handed out to its members. A group with a ParentID
+// inherits the permissions of the parent and, transitively, of its parents:
+// EffectivePermissions is the union along the chain and is what members get.
 type Group struct {
 	Object
-	Name        string
-	Permissions Permission
+	Name                 string      `json:"name"`
+	Permissions          Permissions `json:"permissions"`
+	EffectivePermissions Permissions `json:"effective_permissions"`
+	ParentID             ID          `json:"parent_id,omitempty"`
+	// Scope limits the records the permissions apply to for the members of the group.
+	Scope Scope `json:"scope"`
+}
+
+// Membership is a group of a user. A membership with a CityID grants the permissions of
+// the group in that city only, whatever city the user lives in. A membership with an
+// ExpiresAt is temporary and no longer grants anything after that moment.
+type Membership struct {
+	Group
+	CityID    ID         `json:"city_id,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// Member is a user in a group as stored, without the details of the group.
+type Member struct {
+	GroupID   ID         `json:"group_id"`
+	UserID    ID         `json:"user_id"`
+	CityID    ID         `json:"city_id,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
 }
@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	DefaultListLimit = 50
+	MaxListLimit     = 200
+)
+
+type SortOrder string
+
+const (
+	Ascending  SortOrder = "asc"
+	Descending SortOrder = "desc"
+)
+
+// ListOptions selects a page of a list. Lists are paged by keyset: Cursor is the
+// NextCursor returned with the previous page and only fits the same Sort and Order.
+// An empty Sort orders by ID, the fields that can be sorted by depend on the list.
+type ListOptions struct {
+	Cursor string
+	Limit  int
+	Sort   string
+	Order  SortOrder
+	Filter ListFilter
+	// Restriction is set by services for users who may only see part of the list.
+	Restriction *ListRestriction
+	// Deleted lists the deleted records instead of the others, for the lists of records
+	// that can be restored.
+	Deleted bool
+}
+
+// ListFilter narrows a list down. Zero fields do not filter and every list ignores the
+// fields that do not apply to its records. CreatedFrom is inclusive, CreatedTo is not.
+type ListFilter struct {
+	CityID         ID
+	DonorCompanyID ID
+	UserID         ID
+	RegionID       ID
+	// Name is a LIKE pattern.
+	Name        string
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+
+	ActorID    ID
+	EntityType AuditEntity
+	EntityID   ID
+}
+
+// ListRestriction keeps the records of the cities and, if OwnerID is set, the records
+// owned by that user.
+type ListRestriction struct {
+	CityIDs []ID
+	OwnerID ID
+}
+
+// Normalize fills in the defaults and checks the options.
+func (o ListOptions) Normalize() (ListOptions, error) {
+	switch {
+	case o.Limit == 0:
+		o.Limit = DefaultListLimit
+	case o.Limit < 0 || o.Limit > MaxListLimit:
+		return ListOptions{}, fmt.Errorf("%w: limit must be between 1 and %d", InvalidInput, MaxListLimit)
+	}
+
+	switch o.Order {
+	case "":
+		o.Order = Ascending
+	case Ascending, Descending:
+	default:
+		return ListOptions{}, fmt.Errorf("%w: order must be %q or %q", InvalidInput, Ascending, Descending)
+	}
+
+	if o.Sort == "" {
+		o.Sort = "id"
+	}
+
+	from, to := o.Filter.CreatedFrom, o.Filter.CreatedTo
+	if from != nil && to != nil && !from.Before(*to) {
+		return ListOptions{}, fmt.Errorf("%w: created_from must be before created_to", InvalidInput)
+	}
+	return o, nil
+}
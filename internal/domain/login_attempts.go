@@ -0,0 +1,32 @@
+package domain
+
+import "time"
+
+type LoginSubject string
+
+const (
+	// LoginAccount attempts are keyed by the email they were made with, so that unknown
+	// emails are locked the same way as registered ones.
+	LoginAccount LoginSubject = "account"
+	LoginIP      LoginSubject = "ip"
+	// PasswordResetAccount and PasswordResetIP count password reset requests instead of
+	// failures. They never get a LockedUntil, the requests are refused within the window.
+	PasswordResetAccount LoginSubject = "password_reset_account"
+	PasswordResetIP      LoginSubject = "password_reset_ip"
+)
+
+// LoginAttempts counts consecutive failed sign-ins of an account or from an IP address.
+// Once there are too many of them, sign-ins are blocked until LockedUntil.
+type LoginAttempts struct {
+	Subject       LoginSubject `json:"subject"`
+	Key           string       `json:"key"`
+	Failures      int          `json:"failures"`
+	LastFailureAt time.Time    `json:"last_failure_at"`
+	// LastIP is the address the last failure came from, if known.
+	LastIP      string     `json:"last_ip,omitempty"`
+	LockedUntil *time.Time `json:"locked_until,omitempty"`
+}
+
+func (a LoginAttempts) IsLocked(now time.Time) bool {
+	return a.LockedUntil != nil && a.LockedUntil.After(now)
+}
@@ -1,91 +1,211 @@
 package domain
 
-type Permission uint64
-
-const (
-	Admin Permission = 1 << iota
-
-	CreateUser
-	ReadUser
-	EditUser
-
-	CreateAct
-	ReadAct
-	EditAct
-
-	AddCity
-	ReadCity
-	EditCity
-
-	AddCompany
-	ReadCompany
-	EditCompany
-
-	CreateGroup
-	ReadGroup
-	EditGroup
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
 )
 
-func (p Permission) IsAdmin() bool {
-	return p&Admin != 0
-}
-
-func (p Permission) canCreateUser() bool {
-	return p&CreateUser != 0
-}
+// Permission is the name of a permission, "<resource>:<action>" for everything but Admin.
+// Adding permissions for a new entity only takes new constants registered in
+// resourcePermissions.
+type Permission string
 
-func (p Permission) canReadUser() bool {
-	return p&ReadUser != 0
-}
+const (
+	Admin Permission = "admin"
 
-func (p Permission) canEditUser() bool {
-	return p&EditUser != 0
-}
+	CreateUser Permission = "users:create"
+	ReadUser   Permission = "users:read"
+	EditUser   Permission = "users:edit"
 
-func (p Permission) canCreateAct() bool {
-	return p&CreateAct != 0
-}
+	CreateAct Permission = "acts:create"
+	ReadAct   Permission = "acts:read"
+	EditAct   Permission = "acts:edit"
 
-func (p Permission) canReadAct() bool {
-	return p&ReadAct != 0
-}
+	AddCity  Permission = "cities:add"
+	ReadCity Permission = "cities:read"
+	EditCity Permission = "cities:edit"
 
-func (p Permission) canEditAct() bool {
-	return p&EditAct != 0
-}
+	AddCompany  Permission = "donor-companies:add"
+	ReadCompany Permission = "donor-companies:read"
+	EditCompany Permission = "donor-companies:edit"
 
-func (p Permission) canAddCity() bool {
-	return p&AddCity != 0
-}
-
-func (p Permission) canReadCity() bool {
-	return p&ReadCity != 0
-}
-
-func (p Permission) canEditCity() bool {
-	return p&EditCity != 0
-}
+	CreateGroup Permission = "groups:create"
+	ReadGroup   Permission = "groups:read"
+	EditGroup   Permission = "groups:edit"
+)
 
-func (p Permission) canAddCompany() bool {
-	return p&AddCompany != 0
-}
+// Privileged are the permissions that give control over data of other users.
+var Privileged = NewPermissions(Admin, EditUser, EditAct, EditCity, EditCompany, EditGroup)
 
-func (p Permission) canReadCompany() bool {
-	return p&ReadCompany != 0
-}
+// Action is what a user does with a Resource. Adding cities and companies counts as
+// ActionCreate.
+type Action string
 
-func (p Permission) canEditCompany() bool {
-	return p&EditCompany != 0
-}
+const (
+	ActionCreate Action = "create"
+	ActionRead   Action = "read"
+	ActionEdit   Action = "edit"
+)
 
-func (p Permission) canCreateGroup() bool {
-	return p&CreateGroup != 0
-}
+type Resource string
 
-func (p Permission) canReadGroup() bool {
-	return p&ReadGroup != 0
-}
+const (
+	ResourceUsers     Resource = "users"
+	ResourceActs      Resource = "acts"
+	ResourceCities    Resource = "cities"
+	ResourceCompanies Resource = "donor-companies"
+	ResourceGroups    Resource = "groups"
+)
 
-func (p Permission) canEditGroup() bool {
-	return p&EditGroup != 0
+// resourcePermissions maps every action on a resource to the permission it requires.
+var resourcePermissions = map[Resource]map[Action]Permission{
+	ResourceUsers:     {ActionCreate: CreateUser, ActionRead: ReadUser, ActionEdit: EditUser},
+	ResourceActs:      {ActionCreate: CreateAct, ActionRead: ReadAct, ActionEdit: EditAct},
+	ResourceCities:    {ActionCreate: AddCity, ActionRead: ReadCity, ActionEdit: EditCity},
+	ResourceCompanies: {ActionCreate: AddCompany, ActionRead: ReadCompany, ActionEdit: EditCompany},
+	ResourceGroups:    {ActionCreate: CreateGroup, ActionRead: ReadGroup, ActionEdit: EditGroup},
+}
+
+// PermissionFor returns the permission the action on the resource requires.
+func PermissionFor(action Action, resource Resource) (Permission, bool) {
+	permission, ok := resourcePermissions[resource][action]
+	return permission, ok
+}
+
+// IsValid reports whether the permission is known.
+func (p Permission) IsValid() bool {
+	if p == Admin {
+		return true
+	}
+	for _, actions := range resourcePermissions {
+		for _, permission := range actions {
+			if permission == p {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Permissions is a set of permissions kept sorted and free of duplicates, so it can be
+// compared and stored as is. The zero value is the empty set. It is (un)marshalled as a
+// JSON array of names.
+type Permissions []Permission
+
+func NewPermissions(permissions ...Permission) Permissions {
+	if len(permissions) == 0 {
+		return nil
+	}
+
+	set := make(Permissions, len(permissions))
+	copy(set, permissions)
+	sort.Slice(set, func(i, j int) bool { return set[i] < set[j] })
+
+	n := 1
+	for i := 1; i < len(set); i++ {
+		if set[i] != set[n-1] {
+			set[n] = set[i]
+			n++
+		}
+	}
+	return set[:n]
+}
+
+// PermissionsFromNames is the inverse of Names. It does not check that the names are known.
+func PermissionsFromNames(names []string) Permissions {
+	permissions := make([]Permission, len(names))
+	for i, name := range names {
+		permissions[i] = Permission(name)
+	}
+	return NewPermissions(permissions...)
+}
+
+func (p Permissions) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.Names())
+}
+
+func (p *Permissions) UnmarshalJSON(data []byte) error {
+	var names []Permission
+	if err := json.Unmarshal(data, &names); err != nil {
+		return err
+	}
+	*p = NewPermissions(names...)
+	return nil
+}
+
+// Validate returns InvalidInput naming the first unknown permission in the set.
+func (p Permissions) Validate() error {
+	for _, permission := range p {
+		if !permission.IsValid() {
+			return fmt.Errorf("%w: unknown permission %q", InvalidInput, permission)
+		}
+	}
+	return nil
+}
+
+// Names returns the permissions as plain strings, for storage and tokens.
+func (p Permissions) Names() []string {
+	names := make([]string, len(p))
+	for i, permission := range p {
+		names[i] = string(permission)
+	}
+	return names
+}
+
+func (p Permissions) IsAdmin() bool {
+	return p.contains(Admin)
+}
+
+// Has reports whether the permission is in the set. Admin has every permission.
+func (p Permissions) Has(permission Permission) bool {
+	return p.IsAdmin() || p.contains(permission)
+}
+
+// Union returns the permissions in either of the sets.
+func (p Permissions) Union(other Permissions) Permissions {
+	if len(other) == 0 {
+		return p
+	}
+	if len(p) == 0 {
+		return other
+	}
+	return NewPermissions(append(append(Permissions{}, p...), other...)...)
+}
+
+// Without returns the permissions of the set that are not in other.
+func (p Permissions) Without(other Permissions) Permissions {
+	var rest Permissions
+	for _, permission := range p {
+		if !other.contains(permission) {
+			rest = append(rest, permission)
+		}
+	}
+	return rest
+}
+
+// Intersects reports whether the sets have a permission in common.
+func (p Permissions) Intersects(other Permissions) bool {
+	for _, permission := range p {
+		if other.contains(permission) {
+			return true
+		}
+	}
+	return false
+}
+
+// Allows tells whether the permissions let their holder perform the action on the
+// resource. Admin allows everything, unknown combinations are never allowed.
+func (p Permissions) Allows(action Action, resource Resource) bool {
+	if p.IsAdmin() {
+		return true
+	}
+
+	permission, ok := PermissionFor(action, resource)
+	return ok && p.contains(permission)
+}
+
+func (p Permissions) contains(permission Permission) bool {
+	i := sort.Search(len(p), func(i int) bool { return p[i] >= permission })
+	return i < len(p) && p[i] == permission
 }
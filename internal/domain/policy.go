@@ -0,0 +1,76 @@
+package domain
+
+import "time"
+
+// Scope limits the records the permissions of a group apply to.
+type Scope string
+
+const (
+	// ScopeOwn covers the records of the user: their profile and the acts they created.
+	ScopeOwn Scope = "own"
+	// ScopeCity covers the records located in the city of the user.
+	ScopeCity Scope = "city"
+	ScopeAll  Scope = "all"
+)
+
+func (s Scope) IsValid() bool {
+	switch s {
+	case ScopeOwn, ScopeCity, ScopeAll:
+		return true
+	default:
+		return false
+	}
+}
+
+// Attributes describe a record for scoped permission checks: the user it belongs to and
+// the city it is located in. Zero values are matched by ScopeAll only.
+type Attributes struct {
+	OwnerID ID `json:"owner_id,omitempty"`
+	CityID  ID `json:"city_id,omitempty"`
+}
+
+func (u User) Attributes() Attributes {
+	return Attributes{OwnerID: u.ID, CityID: u.CityID}
+}
+
+// Attributes of a city: it is located in itself, so city scoped permissions cover it.
+func (c City) Attributes() Attributes {
+	return Attributes{CityID: c.ID}
+}
+
+func (c DonorCompany) Attributes() Attributes {
+	return Attributes{CityID: c.CityID}
+}
+
+// Attributes of an act: it belongs to its author and is located in the city of the
+// donor company.
+func (a Act) Attributes(company DonorCompany) Attributes {
+	return Attributes{OwnerID: a.UserID, CityID: company.CityID}
+}
+
+// GroupGrant is what a membership contributes to a permission decision: the permissions
+// of the group, inherited ones included, in the scope they end up applying to. CityID is
+// set for ScopeCity only. Applies tells whether the scope covers the evaluated record,
+// Grants whether the permissions include the required one.
+type GroupGrant struct {
+	GroupID     ID          `json:"group_id"`
+	Name        string      `json:"name"`
+	Scope       Scope       `json:"scope"`
+	CityID      ID          `json:"city_id,omitempty"`
+	ExpiresAt   *time.Time  `json:"expires_at,omitempty"`
+	Permissions Permissions `json:"permissions"`
+	Applies     bool        `json:"applies"`
+	Grants      bool        `json:"grants"`
+}
+
+// PermissionExplanation tells why a user may or may not perform an action on a resource.
+// A zero Record stands for any record, as checked before a request reaches a handler.
+type PermissionExplanation struct {
+	UserID     ID           `json:"user_id"`
+	Action     Action       `json:"action"`
+	Resource   Resource     `json:"resource"`
+	Permission Permission   `json:"permission"`
+	Record     Attributes   `json:"record"`
+	Groups     []GroupGrant `json:"groups"`
+	Allowed    bool         `json:"allowed"`
+}
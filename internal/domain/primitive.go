@@ -4,8 +4,14 @@ import "time"
 
 type ID uint64
 
+// Object holds the fields every record has. Version starts at 1 and grows with every
+// change of the record. Updates carry the version they are based on and fail with
+// Conflict when the record has changed since. DeletedAt is only set on the deleted records
+// of the types that can be restored, which are hidden from everything but their restoring.
 type Object struct {
-	ID        ID
-	CreatedAt time.Time
-	UpdatedAt *time.Time
+	ID        ID         `json:"id"`
+	Version   int64      `json:"version"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 }
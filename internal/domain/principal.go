@@ -0,0 +1,85 @@
+package domain
+
+import "context"
+
+// Principal is the authenticated user on whose behalf a request is executed. Permissions
+// apply to all records, OwnPermissions only to the records of the user and
+// CityPermissions to the records located in the city they are keyed by.
+type Principal struct {
+	UserID          ID
+	Permissions     Permissions
+	OwnPermissions  Permissions
+	CityPermissions map[ID]Permissions
+}
+
+// Grant adds the permissions to the principal in the given scope. City scoped permissions
+// apply to cityID.
+func (p *Principal) Grant(permissions Permissions, scope Scope, cityID ID) {
+	switch scope {
+	case ScopeAll:
+		p.Permissions = p.Permissions.Union(permissions)
+	case ScopeOwn:
+		p.OwnPermissions = p.OwnPermissions.Union(permissions)
+	case ScopeCity:
+		if cityID == 0 || len(permissions) == 0 {
+			return
+		}
+		if p.CityPermissions == nil {
+			p.CityPermissions = make(map[ID]Permissions)
+		}
+		p.CityPermissions[cityID] = p.CityPermissions[cityID].Union(permissions)
+	}
+}
+
+// Can reports whether the permission is granted for all records.
+func (p Principal) Can(permission Permission) bool {
+	return p.Permissions.Has(permission)
+}
+
+// CanOn reports whether the permission is granted for a record with the attributes.
+func (p Principal) CanOn(permission Permission, attrs Attributes) bool {
+	return p.PermissionsOn(attrs).Has(permission)
+}
+
+// PermissionsOn returns the permissions that apply to a record with the attributes.
+func (p Principal) PermissionsOn(attrs Attributes) Permissions {
+	permissions := p.Permissions
+	if attrs.OwnerID != 0 && attrs.OwnerID == p.UserID {
+		permissions = permissions.Union(p.OwnPermissions)
+	}
+	if attrs.CityID != 0 {
+		permissions = permissions.Union(p.CityPermissions[attrs.CityID])
+	}
+	return permissions
+}
+
+// AnyPermissions returns the permissions granted for at least some records.
+func (p Principal) AnyPermissions() Permissions {
+	permissions := p.Permissions.Union(p.OwnPermissions)
+	for _, cityPermissions := range p.CityPermissions {
+		permissions = permissions.Union(cityPermissions)
+	}
+	return permissions
+}
+
+// CitiesWith returns the cities where the permission is granted by city scoped grants.
+func (p Principal) CitiesWith(permission Permission) []ID {
+	var cities []ID
+	for cityID, permissions := range p.CityPermissions {
+		if permissions.Has(permission) {
+			cities = append(cities, cityID)
+		}
+	}
+	return cities
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(Principal)
+	return principal, ok
+}
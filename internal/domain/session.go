@@ -2,8 +2,20 @@ package domain
 
 import "time"
 
+// Session is a refresh token of a user. Every refresh rotates the token: the presented
+// session is marked as rotated and a new one is created in the same family. RefreshToken
+// is only known when the session is created or looked up by it, the database keeps a hash.
+//
+// A family corresponds to a single sign-in on a device, so FamilyID is what users see
+// as the session ID. CreatedAt is the time of the sign-in, LastUsedAt of the latest refresh.
 type Session struct {
-	UserID       ID
-	RefreshToken string
-	ExpiresAt    time.Time
+	UserID       ID         `json:"user_id"`
+	RefreshToken string     `json:"-"`
+	FamilyID     string     `json:"id"`
+	UserAgent    string     `json:"user_agent"`
+	IP           string     `json:"ip"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+	LastUsedAt   time.Time  `json:"last_used_at"`
+	RotatedAt    *time.Time `json:"rotated_at,omitempty"`
 }
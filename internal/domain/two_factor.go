@@ -0,0 +1,18 @@
+package domain
+
+import "time"
+
+// TwoFactor is the TOTP enrollment of a user. It stays pending until the user proves that
+// their authenticator app works by entering a code, only then EnabledAt is set.
+// LastUsedStep is the time step of the last accepted code, so that no code works twice.
+type TwoFactor struct {
+	UserID       ID
+	Secret       string
+	EnabledAt    *time.Time
+	LastUsedStep int64
+	CreatedAt    time.Time
+}
+
+func (t TwoFactor) IsEnabled() bool {
+	return t.EnabledAt != nil
+}
@@ -4,11 +4,17 @@ import "time"
 
 type User struct {
 	Object
-	Surname     string
-	Name        string
-	Patronymic  string
-	DateOfBirth time.Time
-	PhoneNumber string
-	Email       string
-	CityID      ID
+	Surname     string    `json:"surname"`
+	Name        string    `json:"name"`
+	Patronymic  string    `json:"patronymic"`
+	DateOfBirth time.Time `json:"date_of_birth"`
+	PhoneNumber string    `json:"phone_number"`
+	Email       string    `json:"email"`
+	CityID      ID        `json:"city_id"`
+
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+}
+
+func (u User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
 }
@@ -0,0 +1,23 @@
+package domain
+
+import "time"
+
+type TokenPurpose string
+
+const (
+	PasswordReset     TokenPurpose = "password_reset"
+	EmailVerification TokenPurpose = "email_verification"
+)
+
+// UserToken is a single-use secret mailed to the user to prove they own their email.
+// Email is the address the token was sent to. Like refresh tokens, only a hash of
+// Token is stored.
+type UserToken struct {
+	Token     string
+	UserID    ID
+	Purpose   TokenPurpose
+	Email     string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+	UsedAt    *time.Time
+}
@@ -9,15 +9,14 @@ import (
 	"foodsharing-backend/internal/domain"
 
 	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
 )
 
 type postgresActContentsRepo struct {
-	db *pgxpool.Pool
+	db DB
 }
 
-func NewActContentsRepository(pool *pgxpool.Pool) ActContents {
-	return &postgresActContentsRepo{db: pool}
+func NewActContentsRepository(db DB) ActContents {
+	return &postgresActContentsRepo{db: db}
 }
 
 const createActContentQuery = `INSERT INTO act_contents (act_id, number, name, count, price, expiration_date, comment, 
@@ -40,50 +39,65 @@ func (p *postgresActContentsRepo) Create(ctx context.Context, contents ...domain
 }
 
 const updateActContentsQuery = `UPDATE act_contents SET number = $1, name = $2, count = $3, price = $4, 
-		expiration_date = $5, comment = $6, updated_at = $7 WHERE id = $8`
+		expiration_date = $5, comment = $6, updated_at = $7, version = version + 1 WHERE id = $8 AND version = $9`
 
+// Update checks the version of every content. A batch is only undone by errors of the
+// database, so callers updating several contents run it in a transaction to have a
+// Conflict undo the other updates too.
 func (p *postgresActContentsRepo) Update(ctx context.Context, contents ...domain.ActContent) error {
 	batch := &pgx.Batch{}
 	updatedAt := time.Now()
 	for _, content := range contents {
 		batch.Queue(updateActContentsQuery, content.Number, content.Name, content.Count, content.Price,
-			content.ExpirationDate, content.Comment, updatedAt, content.ID)
+			content.ExpirationDate, content.Comment, updatedAt, content.ID, content.Version)
 	}
 
 	br := p.db.SendBatch(ctx, batch)
 	defer br.Close()
-	if _, err := br.Exec(); err != nil {
+
+	var mismatched []domain.ID
+	for _, content := range contents {
+		tag, err := br.Exec()
+		if err != nil {
+			return fmt.Errorf("cannot update act content: %w", err)
+		}
+		if tag.RowsAffected() != 1 {
+			mismatched = append(mismatched, content.ID)
+		}
+	}
+	// The connection is busy until the batch is closed.
+	if err := br.Close(); err != nil {
 		return fmt.Errorf("cannot update act content: %w", err)
 	}
+
+	if len(mismatched) > 0 {
+		return versionMismatch(ctx, p.db, "act_contents", mismatched[0])
+	}
 	return nil
 }
 
-const deleteActContentsQuery = `DELETE FROM act_contents WHERE id = $1`
+const deleteActContentQuery = `DELETE FROM act_contents WHERE id = $1 AND version = $2`
 
-func (p *postgresActContentsRepo) Delete(ctx context.Context, contentIDs ...domain.ID) error {
-	batch := &pgx.Batch{}
-	for _, id := range contentIDs {
-		batch.Queue(deleteActContentsQuery, id)
-	}
-
-	br := p.db.SendBatch(ctx, batch)
-	defer br.Close()
-	if _, err := br.Exec(); err != nil {
+func (p *postgresActContentsRepo) Delete(ctx context.Context, id domain.ID, version int64) error {
+	tag, err := p.db.Exec(ctx, deleteActContentQuery, id, version)
+	if err != nil {
 		return fmt.Errorf("cannot delete act content: %w", err)
 	}
-
+	if tag.RowsAffected() != 1 {
+		return versionMismatch(ctx, p.db, "act_contents", id)
+	}
 	return nil
 }
 
-const getActContentByIDQuery = `SELECT act_id, number, name, count, price, expiration_date, comment FROM act_contents 
-		WHERE id = $1`
+const getActContentByIDQuery = `SELECT act_id, number, name, count, price, expiration_date, comment, version, 
+       created_at, updated_at FROM act_contents WHERE id = $1`
 
 func (p *postgresActContentsRepo) GetByID(ctx context.Context, id domain.ID) (domain.ActContent, error) {
 	var content domain.ActContent
 
 	row := p.db.QueryRow(ctx, getActContentByIDQuery, id)
 	if err := row.Scan(&content.ActID, &content.Number, &content.Name, &content.Count, &content.Price,
-		&content.ExpirationDate, &content.Comment); err != nil {
+		&content.ExpirationDate, &content.Comment, &content.Version, &content.CreatedAt, &content.UpdatedAt); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return domain.ActContent{}, domain.NotFound
 		}
@@ -94,8 +108,8 @@ func (p *postgresActContentsRepo) GetByID(ctx context.Context, id domain.ID) (do
 	return content, nil
 }
 
-const getActContentsByActIDQuery = `SELECT id, number, name, count, price, expiration_date, comment, created_at,
-       updated_at FROM act_contents WHERE act_id = $1`
+const getActContentsByActIDQuery = `SELECT id, number, name, count, price, expiration_date, comment, version, 
+       created_at, updated_at FROM act_contents WHERE act_id = $1`
 
 func (p *postgresActContentsRepo) GetByActID(ctx context.Context, actID domain.ID) ([]domain.ActContent, error) {
 	var contents []domain.ActContent
@@ -108,8 +122,8 @@ func (p *postgresActContentsRepo) GetByActID(ctx context.Context, actID domain.I
 
 	for rows.Next() {
 		var content domain.ActContent
-		if err := rows.Scan(&content.ID, &content.Number, &content.Number, &content.Count, &content.Price,
-			&content.ExpirationDate, &content.Comment, &content.CreatedAt, &content.UpdatedAt); err != nil {
+		if err := rows.Scan(&content.ID, &content.Number, &content.Name, &content.Count, &content.Price,
+			&content.ExpirationDate, &content.Comment, &content.Version, &content.CreatedAt, &content.UpdatedAt); err != nil {
 			return nil, fmt.Errorf("cannot scan act content: %w", err)
 		}
 
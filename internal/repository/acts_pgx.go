@@ -9,15 +9,14 @@ import (
 	"foodsharing-backend/internal/domain"
 
 	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
 )
 
 type postgresActsRepo struct {
-	db *pgxpool.Pool
+	db DB
 }
 
-func NewActsRepository(pool *pgxpool.Pool) Acts {
-	return &postgresActsRepo{db: pool}
+func NewActsRepository(db DB) Acts {
+	return &postgresActsRepo{db: db}
 }
 
 const createActQuery = `INSERT INTO acts (user_id, donor_company_id, created_at) VALUES ($1, $2, $3) RETURNING id`
@@ -31,39 +30,94 @@ func (p *postgresActsRepo) Create(ctx context.Context, act *domain.Act) error {
 	}
 
 	act.ID = id
+	act.Version = 1
 	act.CreatedAt = createdAt
 	return nil
 }
 
-const updateActQuery = `UPDATE acts SET donor_company_id = $1, updated_at = now() WHERE id = $2`
+const updateActQuery = `UPDATE acts SET donor_company_id = $1, updated_at = now(), version = version + 1 
+		WHERE id = $2 AND version = $3`
 
 func (p *postgresActsRepo) Update(ctx context.Context, act domain.Act) error {
-	tag, err := p.db.Exec(ctx, updateActQuery, act.DonorCompanyID, act.ID)
+	tag, err := p.db.Exec(ctx, updateActQuery, act.DonorCompanyID, act.ID, act.Version)
 	if err != nil {
 		return fmt.Errorf("cannot update act: %w", err)
 	}
 	if tag.RowsAffected() != 1 {
-		return domain.NotFound
+		return versionMismatch(ctx, p.db, "acts", act.ID)
 	}
 	return nil
 }
 
-const deleteActQuery = `DELETE FROM acts WHERE id = $1`
+const deleteActQuery = `UPDATE acts SET deleted_at = now(), version = version + 1 
+		WHERE id = $1 AND version = $2 AND deleted_at IS NULL`
 
-func (p *postgresActsRepo) Delete(ctx context.Context, id domain.ID) error {
-	_, err := p.db.Exec(ctx, deleteActQuery, id)
+func (p *postgresActsRepo) Delete(ctx context.Context, id domain.ID, version int64) error {
+	tag, err := p.db.Exec(ctx, deleteActQuery, id, version)
 	if err != nil {
 		return fmt.Errorf("cannot delete act: %w", err)
 	}
+	if tag.RowsAffected() != 1 {
+		return versionMismatch(ctx, p.db, "acts", id)
+	}
 	return nil
 }
 
-const getActByIDQuery = `SELECT user_id, donor_company_id, created_at, updated_at FROM acts WHERE id = $1`
+const (
+	restoreActQuery = `UPDATE acts a SET deleted_at = NULL, version = version + 1 
+		WHERE id = $1 AND deleted_at IS NOT NULL 
+		AND EXISTS (SELECT 1 FROM donor_companies WHERE id = a.donor_company_id AND deleted_at IS NULL)`
+	deletedActExistsQuery = `SELECT EXISTS(SELECT 1 FROM acts WHERE id = $1 AND deleted_at IS NOT NULL)`
+)
+
+func (p *postgresActsRepo) Restore(ctx context.Context, id domain.ID) error {
+	tag, err := p.db.Exec(ctx, restoreActQuery, id)
+	if err != nil {
+		return fmt.Errorf("cannot restore act: %w", err)
+	}
+	if tag.RowsAffected() == 1 {
+		return nil
+	}
+
+	var exists bool
+	if err := p.db.QueryRow(ctx, deletedActExistsQuery, id).Scan(&exists); err != nil {
+		return fmt.Errorf("cannot check act: %w", err)
+	}
+	if !exists {
+		return domain.NotFound
+	}
+	return fmt.Errorf("%w: the donor company of act %d is deleted", domain.InvalidInput, id)
+}
+
+// The contents and file links of purged acts are removed with them, the files stay with
+// their users.
+const (
+	purgeableActs         = `SELECT id FROM acts WHERE deleted_at < $1`
+	purgeActFilesQuery    = `DELETE FROM files_to_acts WHERE act_id IN (` + purgeableActs + `)`
+	purgeActContentsQuery = `DELETE FROM act_contents WHERE act_id IN (` + purgeableActs + `)`
+	purgeActsQuery        = `DELETE FROM acts WHERE deleted_at < $1`
+)
+
+func (p *postgresActsRepo) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	for _, query := range []string{purgeActFilesQuery, purgeActContentsQuery} {
+		if _, err := p.db.Exec(ctx, query, deletedBefore); err != nil {
+			return 0, fmt.Errorf("cannot purge acts: %w", err)
+		}
+	}
+	tag, err := p.db.Exec(ctx, purgeActsQuery, deletedBefore)
+	if err != nil {
+		return 0, fmt.Errorf("cannot purge acts: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+const getActByIDQuery = `SELECT user_id, donor_company_id, version, created_at, updated_at FROM acts 
+		WHERE id = $1 AND deleted_at IS NULL`
 
 func (p *postgresActsRepo) GetByID(ctx context.Context, id domain.ID) (domain.Act, error) {
 	var act domain.Act
 	row := p.db.QueryRow(ctx, getActByIDQuery, id)
-	if err := row.Scan(&act.UserID, &act.DonorCompanyID, &act.CreatedAt, &act.UpdatedAt); err != nil {
+	if err := row.Scan(&act.UserID, &act.DonorCompanyID, &act.Version, &act.CreatedAt, &act.UpdatedAt); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return domain.Act{}, domain.NotFound
 		}
@@ -75,86 +129,98 @@ func (p *postgresActsRepo) GetByID(ctx context.Context, id domain.ID) (domain.Ac
 	return act, nil
 }
 
-const getActsByUserID = `SELECT id, donor_company_id, created_at, updated_at FROM acts WHERE user_id = $1`
+var actsList = listSpec{
+	columns: "a.id, a.user_id, a.donor_company_id, a.version, a.created_at, a.updated_at, a.deleted_at",
+	from:    "acts a JOIN donor_companies dc ON dc.id = a.donor_company_id",
+	id:      "a.id",
+	created: "a.created_at",
+	sorts: map[string]sortColumn{
+		"id":         {expr: "a.id", typ: "bigint"},
+		"created_at": {expr: "a.created_at", typ: "timestamptz"},
+	},
+	city:    "dc.city_id",
+	user:    "a.user_id",
+	donor:   "a.donor_company_id",
+	owner:   "a.user_id",
+	deleted: "a.deleted_at",
+}
+
+func (p *postgresActsRepo) GetAll(ctx context.Context, opts domain.ListOptions) ([]domain.Act, string, error) {
+	q, err := newListQuery(actsList, opts)
+	if err != nil {
+		return nil, "", err
+	}
 
-func (p *postgresActsRepo) GetByUserID(ctx context.Context, userID domain.ID) ([]domain.Act, error) {
 	var acts []domain.Act
-	rows, err := p.db.Query(ctx, getActsByUserID, userID)
+	rows, err := p.db.Query(ctx, q.sql(), q.args...)
 	if err != nil {
-		return nil, fmt.Errorf("cannot get acts by user id: %w", err)
+		return nil, "", fmt.Errorf("cannot get acts: %w", err)
 	}
 	defer rows.Close()
 
 	for rows.Next() {
 		var act domain.Act
-		if err := rows.Scan(&act.ID, &act.DonorCompanyID, &act.CreatedAt, &act.UpdatedAt); err != nil {
-			return nil, fmt.Errorf("cannot scan act: %w", err)
+		var key string
+		if err := rows.Scan(&act.ID, &act.UserID, &act.DonorCompanyID, &act.Version, &act.CreatedAt, &act.UpdatedAt,
+			&act.DeletedAt, &key); err != nil {
+			return nil, "", fmt.Errorf("cannot scan act: %w", err)
 		}
-		act.UserID = userID
+		q.add(act.ID, key)
 		acts = append(acts, act)
 	}
 
-	if rows.Err() != nil {
-		return nil, fmt.Errorf("cannot get acts by user id: %w", err)
+	if err := rows.Err(); err != nil {
+		return nil, "", fmt.Errorf("cannot get acts: %w", err)
 	}
 
-	return acts, nil
+	return acts[:q.size()], q.next(), nil
 }
 
-const getActsByDonorCompanyID = `SELECT id, user_id, created_at, updated_at FROM acts WHERE donor_company_id = $1`
-
-func (p *postgresActsRepo) GetByDonorCompanyID(ctx context.Context, donorCompanyID domain.ID) ([]domain.Act, error) {
+func (p *postgresActsRepo) GetByUserID(ctx context.Context, userID domain.ID) ([]domain.Act, error) {
 	var acts []domain.Act
-	rows, err := p.db.Query(ctx, getActsByDonorCompanyID, donorCompanyID)
+	opts := domain.ListOptions{Filter: domain.ListFilter{UserID: userID}}
+	err := allPages(opts, func(opts domain.ListOptions) (string, error) {
+		page, next, err := p.GetAll(ctx, opts)
+		acts = append(acts, page...)
+		return next, err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("cannot get acts by donor company id: %w", err)
+		return nil, err
 	}
-	defer rows.Close()
-
-	for rows.Next() {
-		var act domain.Act
-		if err := rows.Scan(&act.ID, &act.DonorCompanyID, &act.CreatedAt, &act.UpdatedAt); err != nil {
-			return nil, fmt.Errorf("cannot scan act: %w", err)
-		}
-		act.DonorCompanyID = donorCompanyID
-		acts = append(acts, act)
-	}
-
-	if rows.Err() != nil {
-		return nil, fmt.Errorf("cannot get acts by donor company id: %w", err)
-	}
-
 	return acts, nil
 }
 
-const getAllActsQuery = `SELECT id, user_id, donor_company_id, created_at, updated_at FROM acts`
-
-func (p *postgresActsRepo) GetAll(ctx context.Context) ([]domain.Act, error) {
+func (p *postgresActsRepo) GetByDonorCompanyID(ctx context.Context, donorCompanyID domain.ID) ([]domain.Act, error) {
 	var acts []domain.Act
-	rows, err := p.db.Query(ctx, getAllActsQuery)
+	opts := domain.ListOptions{Filter: domain.ListFilter{DonorCompanyID: donorCompanyID}}
+	err := allPages(opts, func(opts domain.ListOptions) (string, error) {
+		page, next, err := p.GetAll(ctx, opts)
+		acts = append(acts, page...)
+		return next, err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("cannot get acts by donor company id: %w", err)
-	}
-	defer rows.Close()
-
-	for rows.Next() {
-		var act domain.Act
-		if err := rows.Scan(&act.ID, &act.UserID, &act.DonorCompanyID, &act.CreatedAt, &act.UpdatedAt); err != nil {
-			return nil, fmt.Errorf("cannot scan act: %w", err)
-		}
-		acts = append(acts, act)
+		return nil, err
 	}
+	return acts, nil
+}
 
-	if rows.Err() != nil {
-		return nil, fmt.Errorf("cannot get acts by donor company id: %w", err)
+func (p *postgresActsRepo) GetByCity(ctx context.Context, cityID domain.ID) ([]domain.Act, error) {
+	var acts []domain.Act
+	opts := domain.ListOptions{Filter: domain.ListFilter{CityID: cityID}}
+	err := allPages(opts, func(opts domain.ListOptions) (string, error) {
+		page, next, err := p.GetAll(ctx, opts)
+		acts = append(acts, page...)
+		return next, err
+	})
+	if err != nil {
+		return nil, err
 	}
-
 	return acts, nil
 }
 
 const addFileToActQuery = `INSERT INTO files_to_acts(file_id, act_id) VALUES ($1, $2)`
 
-func (p *postgresActsRepo) AddFile(ctx context.Context, actID domain.ID, fileID domain.ID) error {
+func (p *postgresActsRepo) AddFile(ctx context.Context, fileID domain.ID, actID domain.ID) error {
 	_, err := p.db.Exec(ctx, addFileToActQuery, fileID, actID)
 	if err != nil {
 		return fmt.Errorf("cannot add file to act: %w", err)
@@ -162,8 +228,8 @@ func (p *postgresActsRepo) AddFile(ctx context.Context, actID domain.ID, fileID
 	return nil
 }
 
-const getActFilesQuery = `SELECT id, user_id, type, content_type, name, size, status, url, created_at, updated_at 
-		FROM files WHERE id IN (SELECT file_id FROM files_to_acts WHERE act_id = $1)`
+const getActFilesQuery = `SELECT id, user_id, type, content_type, name, size, status, COALESCE(url, ''), version, 
+		created_at, updated_at FROM files WHERE id IN (SELECT file_id FROM files_to_acts WHERE act_id = $1)`
 
 func (p *postgresActsRepo) GetActFiles(ctx context.Context, actID domain.ID) ([]domain.File, error) {
 	var files []domain.File
@@ -177,7 +243,7 @@ func (p *postgresActsRepo) GetActFiles(ctx context.Context, actID domain.ID) ([]
 	for rows.Next() {
 		var file domain.File
 		if err := rows.Scan(&file.ID, &file.UserID, &file.Type, &file.ContentType, &file.Name, &file.Size, &file.Status,
-			&file.URL, &file.CreatedAt, &file.UpdatedAt); err != nil {
+			&file.URL, &file.Version, &file.CreatedAt, &file.UpdatedAt); err != nil {
 			return nil, fmt.Errorf("cannot scan file: %w", err)
 		}
 		files = append(files, file)
@@ -190,6 +256,34 @@ func (p *postgresActsRepo) GetActFiles(ctx context.Context, actID domain.ID) ([]
 	return files, nil
 }
 
+const getFileActsQuery = `SELECT id, user_id, donor_company_id, version, created_at, updated_at FROM acts 
+		WHERE id IN (SELECT act_id FROM files_to_acts WHERE file_id = $1) AND deleted_at IS NULL`
+
+func (p *postgresActsRepo) GetFileActs(ctx context.Context, fileID domain.ID) ([]domain.Act, error) {
+	var acts []domain.Act
+
+	rows, err := p.db.Query(ctx, getFileActsQuery, fileID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get acts of file: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var act domain.Act
+		if err := rows.Scan(&act.ID, &act.UserID, &act.DonorCompanyID, &act.Version, &act.CreatedAt,
+			&act.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("cannot scan act: %w", err)
+		}
+		acts = append(acts, act)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("cannot get acts of file: %w", err)
+	}
+
+	return acts, nil
+}
+
 const removeFileFromActQuery = `DELETE FROM files_to_acts WHERE file_id = $1 AND act_id = $2`
 
 func (p *postgresActsRepo) RemoveFile(ctx context.Context, fileID domain.ID, actID domain.ID) error {
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+
+	"foodsharing-backend/internal/domain"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// The audit log is written by triggers, see the migrations. They take the user making a
+// change from the app.actor_id setting of the connection.
+const setActorQuery = `SELECT set_config('app.actor_id', $1, false)`
+
+// RecordActor makes the pool name the user in the context of every call in the setting
+// the audit triggers read. It costs a round trip every time a connection is acquired.
+func RecordActor(cfg *pgxpool.Config) {
+	cfg.BeforeAcquire = func(ctx context.Context, conn *pgx.Conn) bool {
+		var actor string
+		if principal, ok := domain.PrincipalFromContext(ctx); ok {
+			actor = strconv.FormatUint(uint64(principal.UserID), 10)
+		}
+		if _, err := conn.Exec(ctx, setActorQuery, actor); err != nil {
+			log.Printf("cannot set actor on connection: %v", err)
+			return false
+		}
+		return true
+	}
+}
+
+type postgresAuditLogRepo struct {
+	db DB
+}
+
+func NewAuditLogRepository(db DB) AuditLog {
+	return &postgresAuditLogRepo{db: db}
+}
+
+var auditLogList = listSpec{
+	columns: `id, COALESCE(actor_id, 0), action, entity_type, entity_id, before, after, created_at`,
+	from:    "audit_log",
+	id:      "id",
+	created: "created_at",
+	sorts: map[string]sortColumn{
+		"id":         {expr: "id", typ: "bigint"},
+		"created_at": {expr: "created_at", typ: "timestamptz"},
+	},
+	actor:      "actor_id",
+	entityType: "entity_type",
+	entityID:   "entity_id",
+}
+
+func (p *postgresAuditLogRepo) GetAll(ctx context.Context, opts domain.ListOptions) ([]domain.AuditEntry, string,
+	error) {
+	q, err := newListQuery(auditLogList, opts)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var entries []domain.AuditEntry
+	rows, err := p.db.Query(ctx, q.sql(), q.args...)
+	if err != nil {
+		return nil, "", fmt.Errorf("cannot get audit log: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var entry domain.AuditEntry
+		var key string
+		if err := rows.Scan(&entry.ID, &entry.ActorID, &entry.Action, &entry.EntityType, &entry.EntityID,
+			(*[]byte)(&entry.Before), (*[]byte)(&entry.After), &entry.CreatedAt, &key); err != nil {
+			return nil, "", fmt.Errorf("cannot scan audit entry: %w", err)
+		}
+		q.add(entry.ID, key)
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, "", fmt.Errorf("cannot get audit log: %w", err)
+	}
+
+	return entries[:q.size()], q.next(), nil
+}
@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"foodsharing-backend/internal/domain"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// errUnknownCity is returned when a user or a donor company refers to a city that does not
+// exist, which the foreign keys on city_id rule out.
+var errUnknownCity = fmt.Errorf("%w: city does not exist", domain.InvalidInput)
+
+type postgresCitiesRepo struct {
+	db DB
+}
+
+func NewCitiesRepository(db DB) Cities {
+	return &postgresCitiesRepo{db: db}
+}
+
+const createCityQuery = `INSERT INTO cities(name, region_id, timezone, latitude, longitude, created_at) 
+		VALUES ($1, NULLIF($2, 0), $3, $4, $5, $6) RETURNING id`
+
+func (p *postgresCitiesRepo) Create(ctx context.Context, city *domain.City) error {
+	var id domain.ID
+	createdAt := time.Now()
+
+	latitude, longitude := coordinateValues(city.Coordinates)
+	row := p.db.QueryRow(ctx, createCityQuery, city.Name, city.RegionID, city.Timezone, latitude, longitude,
+		createdAt)
+	if err := row.Scan(&id); err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("%w: city %q", domain.AlreadyExists, city.Name)
+		}
+		return fmt.Errorf("cannot create city: %w", err)
+	}
+
+	city.ID = id
+	city.Version = 1
+	city.CreatedAt = createdAt
+	return nil
+}
+
+const updateCityQuery = `UPDATE cities SET name = $1, region_id = NULLIF($2, 0), timezone = $3, latitude = $4, 
+		longitude = $5, updated_at = now(), version = version + 1 WHERE id = $6 AND version = $7`
+
+func (p *postgresCitiesRepo) Update(ctx context.Context, city domain.City) error {
+	latitude, longitude := coordinateValues(city.Coordinates)
+	tag, err := p.db.Exec(ctx, updateCityQuery, city.Name, city.RegionID, city.Timezone, latitude, longitude,
+		city.ID, city.Version)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("%w: city %q", domain.AlreadyExists, city.Name)
+		}
+		return fmt.Errorf("cannot update city: %w", err)
+	}
+	if tag.RowsAffected() != 1 {
+		return versionMismatch(ctx, p.db, "cities", city.ID)
+	}
+	return nil
+}
+
+const deleteCityQuery = `DELETE FROM cities c WHERE id = $1 AND version = $2 
+		AND NOT EXISTS (SELECT 1 FROM users WHERE city_id = c.id) 
+		AND NOT EXISTS (SELECT 1 FROM donor_companies WHERE city_id = c.id) 
+		AND NOT EXISTS (SELECT 1 FROM users_to_groups WHERE city_id = c.id)`
+
+// Delete checks the references in the statement itself, the foreign keys still catch
+// records added concurrently. Deleted users and companies count on purpose, they still
+// refer to the city until they are purged.
+func (p *postgresCitiesRepo) Delete(ctx context.Context, id domain.ID, version int64) error {
+	tag, err := p.db.Exec(ctx, deleteCityQuery, id, version)
+	if err != nil {
+		return fmt.Errorf("cannot delete city: %w", err)
+	}
+	if tag.RowsAffected() == 1 {
+		return nil
+	}
+
+	if err := versionMatches(ctx, p.db, "cities", id, version); err != nil {
+		return err
+	}
+	return fmt.Errorf("%w: the city still has users, donor companies or group members, deleted ones included",
+		domain.InUse)
+}
+
+const getCityByIDQuery = `SELECT name, COALESCE(region_id, 0), timezone, latitude, longitude, version, created_at, 
+		updated_at FROM cities WHERE id = $1`
+
+func (p *postgresCitiesRepo) GetByID(ctx context.Context, id domain.ID) (domain.City, error) {
+	var city domain.City
+	var latitude, longitude *float64
+	row := p.db.QueryRow(ctx, getCityByIDQuery, id)
+	if err := row.Scan(&city.Name, &city.RegionID, &city.Timezone, &latitude, &longitude, &city.Version, &city.CreatedAt,
+		&city.UpdatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.City{}, domain.NotFound
+		}
+		return domain.City{}, fmt.Errorf("cannot get city by id: %w", err)
+	}
+
+	city.ID = id
+	city.Coordinates = coordinates(latitude, longitude)
+	return city, nil
+}
+
+var citiesList = listSpec{
+	columns: `c.id, c.name, COALESCE(c.region_id, 0), c.timezone, c.latitude, c.longitude, c.version, c.created_at, 
+		c.updated_at, 
+		(SELECT count(*) FROM users WHERE city_id = c.id AND deleted_at IS NULL), 
+		(SELECT count(*) FROM donor_companies WHERE city_id = c.id AND deleted_at IS NULL)`,
+	from:    "cities c",
+	id:      "c.id",
+	created: "c.created_at",
+	sorts: map[string]sortColumn{
+		"id":         {expr: "c.id", typ: "bigint"},
+		"created_at": {expr: "c.created_at", typ: "timestamptz"},
+		"name":       {expr: "c.name", typ: "text"},
+	},
+	city:   "c.id",
+	region: "c.region_id",
+	name:   "c.name",
+}
+
+func (p *postgresCitiesRepo) GetAll(ctx context.Context, opts domain.ListOptions) ([]domain.CitySummary, string,
+	error) {
+	q, err := newListQuery(citiesList, opts)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var cities []domain.CitySummary
+	rows, err := p.db.Query(ctx, q.sql(), q.args...)
+	if err != nil {
+		return nil, "", fmt.Errorf("cannot get cities: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var city domain.CitySummary
+		var latitude, longitude *float64
+		var key string
+		if err := rows.Scan(&city.ID, &city.Name, &city.RegionID, &city.Timezone, &latitude, &longitude,
+			&city.Version, &city.CreatedAt, &city.UpdatedAt, &city.Users, &city.DonorCompanies, &key); err != nil {
+			return nil, "", fmt.Errorf("cannot scan city: %w", err)
+		}
+		city.Coordinates = coordinates(latitude, longitude)
+		q.add(city.ID, key)
+		cities = append(cities, city)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, "", fmt.Errorf("cannot get cities: %w", err)
+	}
+
+	return cities[:q.size()], q.next(), nil
+}
+
+// coordinateValues splits the coordinates into nullable columns.
+func coordinateValues(c *domain.Coordinates) (*float64, *float64) {
+	if c == nil {
+		return nil, nil
+	}
+	return &c.Latitude, &c.Longitude
+}
+
+func coordinates(latitude, longitude *float64) *domain.Coordinates {
+	if latitude == nil || longitude == nil {
+		return nil
+	}
+	return &domain.Coordinates{Latitude: *latitude, Longitude: *longitude}
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"foodsharing-backend/internal/domain"
+
+	"github.com/jackc/pgx/v4"
+)
+
+type postgresCredentialsRepo struct {
+	db DB
+}
+
+func NewCredentialsRepository(db DB) Credentials {
+	return &postgresCredentialsRepo{db: db}
+}
+
+const setCredentialsQuery = `INSERT INTO credentials (user_id, password_hash, created_at) VALUES ($1, $2, $3) 
+		ON CONFLICT (user_id) DO UPDATE SET password_hash = excluded.password_hash, updated_at = excluded.created_at`
+
+func (p *postgresCredentialsRepo) Set(ctx context.Context, userID domain.ID, passwordHash string) error {
+	_, err := p.db.Exec(ctx, setCredentialsQuery, userID, passwordHash, time.Now())
+	if err != nil {
+		return fmt.Errorf("cannot set credentials: %w", err)
+	}
+	return nil
+}
+
+const getCredentialsByUserIDQuery = `SELECT password_hash, created_at, updated_at FROM credentials WHERE user_id = $1`
+
+func (p *postgresCredentialsRepo) GetByUserID(ctx context.Context, userID domain.ID) (domain.Credentials, error) {
+	var credentials domain.Credentials
+	row := p.db.QueryRow(ctx, getCredentialsByUserIDQuery, userID)
+	if err := row.Scan(&credentials.PasswordHash, &credentials.CreatedAt, &credentials.UpdatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.Credentials{}, domain.NotFound
+		}
+
+		return domain.Credentials{}, fmt.Errorf("cannot get credentials: %w", err)
+	}
+
+	credentials.UserID = userID
+	return credentials, nil
+}
@@ -9,15 +9,14 @@ import (
 	"foodsharing-backend/internal/domain"
 
 	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
 )
 
 type postgresDonorCompaniesRepo struct {
-	db *pgxpool.Pool
+	db DB
 }
 
-func NewDonorCompaniesRepository(pool *pgxpool.Pool) DonorCompanies {
-	return &postgresDonorCompaniesRepo{db: pool}
+func NewDonorCompaniesRepository(db DB) DonorCompanies {
+	return &postgresDonorCompaniesRepo{db: db}
 }
 
 const createDonorCompanyQuery = `INSERT INTO donor_companies(name, city_id, contract_date, contract_number, created_at) 
@@ -30,48 +29,89 @@ func (p *postgresDonorCompaniesRepo) Create(ctx context.Context, company *domain
 	row := p.db.QueryRow(ctx, createDonorCompanyQuery, company.Name, company.CityID, company.ContractDate,
 		company.ContractNumber, createdAt)
 	if err := row.Scan(&id); err != nil {
+		if isForeignKeyViolation(err) {
+			return errUnknownCity
+		}
 		return fmt.Errorf("cannot create donor company: %w", err)
 	}
 
 	company.ID = id
+	company.Version = 1
 	company.CreatedAt = createdAt
 
 	return nil
 }
 
 const updateDonorCompanyQuery = `UPDATE donor_companies SET name = $1, city_id = $2, contract_date = $3, 
-		contract_number = $4, updated_at = now() WHERE id = $5`
+		contract_number = $4, updated_at = now(), version = version + 1 WHERE id = $5 AND version = $6`
 
 func (p *postgresDonorCompaniesRepo) Update(ctx context.Context, company domain.DonorCompany) error {
 	tag, err := p.db.Exec(ctx, updateDonorCompanyQuery, company.Name, company.CityID, company.ContractDate,
-		company.ContractNumber, company.ID)
+		company.ContractNumber, company.ID, company.Version)
 	if err != nil {
+		if isForeignKeyViolation(err) {
+			return errUnknownCity
+		}
 		return fmt.Errorf("cannot update donor company: %w", err)
 	}
 	if tag.RowsAffected() != 1 {
-		return domain.NotFound
+		return versionMismatch(ctx, p.db, "donor_companies", company.ID)
 	}
 	return nil
 }
 
-const deleteDonorCompanyQuery = `DELETE FROM donor_companies WHERE id = $1`
+const deleteDonorCompanyQuery = `UPDATE donor_companies dc SET deleted_at = now(), version = version + 1 
+		WHERE id = $1 AND version = $2 AND deleted_at IS NULL 
+		AND NOT EXISTS (SELECT 1 FROM acts WHERE donor_company_id = dc.id AND deleted_at IS NULL)`
 
-func (p *postgresDonorCompaniesRepo) Delete(ctx context.Context, id domain.ID) error {
-	_, err := p.db.Exec(ctx, deleteDonorCompanyQuery, id)
+func (p *postgresDonorCompaniesRepo) Delete(ctx context.Context, id domain.ID, version int64) error {
+	tag, err := p.db.Exec(ctx, deleteDonorCompanyQuery, id, version)
 	if err != nil {
 		return fmt.Errorf("cannot delete donor company: %w", err)
 	}
+	if tag.RowsAffected() == 1 {
+		return nil
+	}
+
+	if err := versionMatches(ctx, p.db, "donor_companies", id, version); err != nil {
+		return err
+	}
+	return fmt.Errorf("%w: donor company %d has acts", domain.InUse, id)
+}
+
+const restoreDonorCompanyQuery = `UPDATE donor_companies SET deleted_at = NULL, version = version + 1 
+		WHERE id = $1 AND deleted_at IS NOT NULL`
+
+func (p *postgresDonorCompaniesRepo) Restore(ctx context.Context, id domain.ID) error {
+	tag, err := p.db.Exec(ctx, restoreDonorCompanyQuery, id)
+	if err != nil {
+		return fmt.Errorf("cannot restore donor company: %w", err)
+	}
+	if tag.RowsAffected() != 1 {
+		return domain.NotFound
+	}
 	return nil
 }
 
-const getDonorCompanyByIDQuery = `SELECT name, city_id, contract_date, contract_number, created_at, updated_at 
-		FROM donor_companies WHERE id = $1`
+const purgeDonorCompaniesQuery = `DELETE FROM donor_companies dc WHERE deleted_at < $1 
+		AND NOT EXISTS (SELECT 1 FROM acts WHERE donor_company_id = dc.id)`
+
+func (p *postgresDonorCompaniesRepo) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	tag, err := p.db.Exec(ctx, purgeDonorCompaniesQuery, deletedBefore)
+	if err != nil {
+		return 0, fmt.Errorf("cannot purge donor companies: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+const getDonorCompanyByIDQuery = `SELECT name, city_id, contract_date, contract_number, version, created_at, updated_at 
+		FROM donor_companies WHERE id = $1 AND deleted_at IS NULL`
 
 func (p *postgresDonorCompaniesRepo) GetByID(ctx context.Context, id domain.ID) (domain.DonorCompany, error) {
 	var donor domain.DonorCompany
 	row := p.db.QueryRow(ctx, getDonorCompanyByIDQuery, id)
-	if err := row.Scan(&donor.Name, &donor.CityID, &donor.ContractDate, &donor.ContractNumber, &donor.CreatedAt,
-		&donor.UpdatedAt, id); err != nil {
+	if err := row.Scan(&donor.Name, &donor.CityID, &donor.ContractDate, &donor.ContractNumber, &donor.Version,
+		&donor.CreatedAt, &donor.UpdatedAt); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return domain.DonorCompany{}, domain.NotFound
 		}
@@ -82,59 +122,65 @@ func (p *postgresDonorCompaniesRepo) GetByID(ctx context.Context, id domain.ID)
 	return donor, nil
 }
 
-const getDonorCompaniesByCityQuery = `SELECT id, name, contract_date, contract_number, created_at, updated_at FROM 
-		donor_companies WHERE city_id LIKE $1`
+var donorCompaniesList = listSpec{
+	columns: "id, name, city_id, contract_date, contract_number, version, created_at, updated_at, deleted_at",
+	from:    "donor_companies",
+	id:      "id",
+	created: "created_at",
+	sorts: map[string]sortColumn{
+		"id":            {expr: "id", typ: "bigint"},
+		"created_at":    {expr: "created_at", typ: "timestamptz"},
+		"name":          {expr: "name", typ: "text"},
+		"contract_date": {expr: "contract_date", typ: "date"},
+	},
+	city:    "city_id",
+	name:    "name",
+	deleted: "deleted_at",
+}
 
-func (p *postgresDonorCompaniesRepo) GetByCity(ctx context.Context, cityID domain.ID) ([]domain.DonorCompany, error) {
-	var donors []domain.DonorCompany
-	rows, err := p.db.Query(ctx, getDonorCompaniesByCityQuery, cityID)
+func (p *postgresDonorCompaniesRepo) GetAll(ctx context.Context,
+	opts domain.ListOptions) ([]domain.DonorCompany, string, error) {
+	q, err := newListQuery(donorCompaniesList, opts)
 	if err != nil {
-		return nil, fmt.Errorf("cannot get donor companies by city: %w", err)
-	}
-	defer rows.Close()
-
-	for rows.Next() {
-		var donor domain.DonorCompany
-		if err := rows.Scan(&donor.ID, &donor.Name, &donor.ContractDate, &donor.ContractNumber, &donor.CreatedAt,
-			&donor.UpdatedAt); err != nil {
-			return nil, fmt.Errorf("cannot scan company: %w", err)
-		}
-
-		donor.CityID = cityID
-		donors = append(donors, donor)
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("cannot get donor companies by city: %w", err)
+		return nil, "", err
 	}
 
-	return donors, nil
-}
-
-const getAllDonorCompaniesQuery = `SELECT id, name, city_id, contract_date, contract_number, created_at, updated_at 
-		FROM donor_companies`
-
-func (p *postgresDonorCompaniesRepo) GetAll(ctx context.Context) ([]domain.DonorCompany, error) {
 	var donors []domain.DonorCompany
-	rows, err := p.db.Query(ctx, getAllDonorCompaniesQuery)
+	rows, err := p.db.Query(ctx, q.sql(), q.args...)
 	if err != nil {
-		return nil, fmt.Errorf("cannot get donor companies by city: %w", err)
+		return nil, "", fmt.Errorf("cannot get donor companies: %w", err)
 	}
 	defer rows.Close()
 
 	for rows.Next() {
 		var donor domain.DonorCompany
+		var key string
 		if err := rows.Scan(&donor.ID, &donor.Name, &donor.CityID, &donor.ContractDate, &donor.ContractNumber,
-			&donor.CreatedAt, &donor.UpdatedAt); err != nil {
-			return nil, fmt.Errorf("cannot scan company: %w", err)
+			&donor.Version, &donor.CreatedAt, &donor.UpdatedAt, &donor.DeletedAt, &key); err != nil {
+			return nil, "", fmt.Errorf("cannot scan company: %w", err)
 		}
 
+		q.add(donor.ID, key)
 		donors = append(donors, donor)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("cannot get donor companies by city: %w", err)
+		return nil, "", fmt.Errorf("cannot get donor companies: %w", err)
 	}
 
-	return donors, nil
+	return donors[:q.size()], q.next(), nil
+}
+
+func (p *postgresDonorCompaniesRepo) GetByCity(ctx context.Context, cityID domain.ID) ([]domain.DonorCompany, error) {
+	var companies []domain.DonorCompany
+	opts := domain.ListOptions{Filter: domain.ListFilter{CityID: cityID}}
+	err := allPages(opts, func(opts domain.ListOptions) (string, error) {
+		page, next, err := p.GetAll(ctx, opts)
+		companies = append(companies, page...)
+		return next, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return companies, nil
 }
@@ -9,15 +9,14 @@ import (
 	"foodsharing-backend/internal/domain"
 
 	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
 )
 
 type postgresFilesRepo struct {
-	db *pgxpool.Pool
+	db DB
 }
 
-func NewFilesRepository(pool *pgxpool.Pool) Files {
-	return &postgresFilesRepo{db: pool}
+func NewFilesRepository(db DB) Files {
+	return &postgresFilesRepo{db: db}
 }
 
 const createFileQuery = `INSERT INTO files(user_id, type, content_type, name, size, status, created_at) 
@@ -28,18 +27,20 @@ func (p *postgresFilesRepo) Create(ctx context.Context, file *domain.File) error
 	createdAt := time.Now()
 
 	row := p.db.QueryRow(ctx, createFileQuery, file.UserID, file.Type, file.ContentType, file.Name, file.Size,
-		file.Status)
+		file.Status, createdAt)
 	if err := row.Scan(&id); err != nil {
 		return fmt.Errorf("cannot create file: %w", err)
 	}
 
 	file.ID = id
+	file.Version = 1
 	file.CreatedAt = createdAt
 
 	return nil
 }
 
-const updateFileStatusQuery = `UPDATE files SET status = $1, updated_at = now() WHERE id = $2`
+const updateFileStatusQuery = `UPDATE files SET status = $1, updated_at = now(), version = version + 1 
+		WHERE id = $2`
 
 func (p *postgresFilesRepo) UpdateStatus(ctx context.Context, fileID domain.ID, status domain.FileStatus) error {
 	tag, err := p.db.Exec(ctx, updateFileStatusQuery, status, fileID)
@@ -53,15 +54,15 @@ func (p *postgresFilesRepo) UpdateStatus(ctx context.Context, fileID domain.ID,
 	return nil
 }
 
-const getFileForUploading = `UPDATE files SET status = $2, updated_at = now() 
+const getFileForUploading = `UPDATE files SET status = $2, updated_at = now(), version = version + 1 
 		WHERE id = (SELECT id FROM files WHERE status = $1 LIMIT 1) 
-		RETURNING id, user_id, type, content_type, name, size, status, created_at, updated_at`
+		RETURNING id, user_id, type, content_type, name, size, status, version, created_at, updated_at`
 
 func (p *postgresFilesRepo) GetForUploading(ctx context.Context) (domain.File, error) {
 	var file domain.File
 	row := p.db.QueryRow(ctx, getFileForUploading, domain.UploadedByClient, domain.StorageUploadInProgress)
 	if err := row.Scan(&file.ID, &file.UserID, &file.Type, &file.ContentType, &file.Name, &file.Size, &file.Status,
-		&file.CreatedAt, &file.UpdatedAt); err != nil {
+		&file.Version, &file.CreatedAt, &file.UpdatedAt); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return domain.File{}, domain.NotFound
 		}
@@ -71,7 +72,8 @@ func (p *postgresFilesRepo) GetForUploading(ctx context.Context) (domain.File, e
 	return file, nil
 }
 
-const updateFileStatusAndURLQuery = `UPDATE files SET status = $1, url = $2, updated_at = now() WHERE id = $3`
+const updateFileStatusAndURLQuery = `UPDATE files SET status = $1, url = $2, updated_at = now(), version = version + 1 
+		WHERE id = $3`
 
 func (p *postgresFilesRepo) UpdateStatusAndSetURL(ctx context.Context, fileID domain.ID, url string) error {
 	tag, err := p.db.Exec(ctx, updateFileStatusAndURLQuery, domain.UploadedToStorage, url, fileID)
@@ -86,15 +88,18 @@ func (p *postgresFilesRepo) UpdateStatusAndSetURL(ctx context.Context, fileID do
 	return nil
 }
 
-const getFileByID = `SELECT user_id, type, content_type, name, size, status, url, created_at, updated_at FROM files 
-		WHERE id = $1`
+const getFileByID = `SELECT user_id, type, content_type, name, size, status, COALESCE(url, ''), version, created_at, 
+		updated_at FROM files WHERE id = $1`
 
 func (p *postgresFilesRepo) GetByID(ctx context.Context, fileID domain.ID) (domain.File, error) {
 	var file domain.File
 
 	row := p.db.QueryRow(ctx, getFileByID, fileID)
 	if err := row.Scan(&file.UserID, &file.Type, &file.ContentType, &file.Name, &file.Size, &file.Status, &file.URL,
-		&file.CreatedAt, &file.UpdatedAt); err != nil {
+		&file.Version, &file.CreatedAt, &file.UpdatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.File{}, domain.NotFound
+		}
 		return domain.File{}, fmt.Errorf("cannot get file by id: %w", err)
 	}
 
@@ -9,63 +9,100 @@ import (
 	"foodsharing-backend/internal/domain"
 
 	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
 )
 
 type postgresGroupsRepo struct {
-	db *pgxpool.Pool
+	db DB
 }
 
-func NewGroupsRepository(pool *pgxpool.Pool) Groups {
-	return &postgresGroupsRepo{db: pool}
+func NewGroupsRepository(db DB) Groups {
+	return &postgresGroupsRepo{db: db}
 }
 
-const createGroupQuery = `INSERT INTO groups(name, permissions, created_at) VALUES ($1, $2, $3) RETURNING id`
+const createGroupQuery = `WITH created AS (
+			INSERT INTO groups(name, scope, parent_id, created_at) VALUES ($1, $2, NULLIF($3, 0), $4) RETURNING id
+		), permissions AS (
+			INSERT INTO groups_to_permissions(group_id, permission) SELECT id, unnest($5::text[]) FROM created
+		)
+		SELECT id FROM created`
 
 func (p *postgresGroupsRepo) Create(ctx context.Context, group *domain.Group) error {
 	var id domain.ID
 	createdAt := time.Now()
 
-	row := p.db.QueryRow(ctx, createGroupQuery, group.Name, group.Permissions, createdAt)
+	row := p.db.QueryRow(ctx, createGroupQuery, group.Name, group.Scope, group.ParentID, createdAt,
+		group.Permissions.Names())
 	if err := row.Scan(&id); err != nil {
 		return fmt.Errorf("cannot create group: %w", err)
 	}
 
 	group.ID = id
+	group.Version = 1
 	group.CreatedAt = createdAt
 	return nil
 }
 
-const updateGroupQuery = `UPDATE groups SET name = $1, permissions = $2, updated_at = now() WHERE id = $3`
-
+// updateGroupQuery replaces the permissions only if the group itself was updated, that is
+// if it has the expected version.
+const updateGroupQuery = `WITH updated AS (
+			UPDATE groups SET name = $1, scope = $2, parent_id = NULLIF($3, 0), updated_at = now(), 
+				version = version + 1 WHERE id = $4 AND version = $5 RETURNING id
+		), removed AS (
+			DELETE FROM groups_to_permissions 
+			WHERE group_id IN (SELECT id FROM updated) AND permission <> ALL($6)
+		), added AS (
+			INSERT INTO groups_to_permissions(group_id, permission) 
+			SELECT id, unnest($6::text[]) FROM updated ON CONFLICT DO NOTHING
+		)
+		SELECT count(*) FROM updated`
+
+// Update replaces the permissions of the group along with its fields in one statement, so
+// they succeed or fail together.
 func (p *postgresGroupsRepo) Update(ctx context.Context, group domain.Group) error {
-	tag, err := p.db.Exec(ctx, updateGroupQuery, group.Name, group.Permissions, group.ID)
-	if err != nil {
+	var updated int
+	row := p.db.QueryRow(ctx, updateGroupQuery, group.Name, group.Scope, group.ParentID, group.ID, group.Version,
+		group.Permissions.Names())
+	if err := row.Scan(&updated); err != nil {
 		return fmt.Errorf("cannot update group: %w", err)
 	}
-	if tag.RowsAffected() != 1 {
-		return domain.NotFound
+	if updated != 1 {
+		return versionMismatch(ctx, p.db, "groups", group.ID)
 	}
 	return nil
 }
 
-const deleteGroupQuery = `DELETE FROM groups WHERE id = $1`
+const deleteGroupQuery = `DELETE FROM groups g WHERE id = $1 AND version = $2 
+		AND NOT EXISTS (SELECT 1 FROM users_to_groups WHERE group_id = g.id)`
 
-func (p *postgresGroupsRepo) Delete(ctx context.Context, id domain.ID) error {
-	_, err := p.db.Exec(ctx, deleteGroupQuery, id)
+// Delete checks the memberships in the statement itself, the foreign key still catches
+// members added concurrently.
+func (p *postgresGroupsRepo) Delete(ctx context.Context, id domain.ID, version int64) error {
+	tag, err := p.db.Exec(ctx, deleteGroupQuery, id, version)
 	if err != nil {
 		return fmt.Errorf("cannot delete group: %w", err)
 	}
-	return nil
+	if tag.RowsAffected() == 1 {
+		return nil
+	}
+
+	if err := versionMatches(ctx, p.db, "groups", id, version); err != nil {
+		return err
+	}
+	return fmt.Errorf("%w: group %d has members", domain.InUse, id)
 }
 
-const getGroupByID = `SELECT name, permissions, created_at, updated_at FROM groups WHERE id = $1`
+const getGroupByID = `SELECT g.name, g.scope, COALESCE(g.parent_id, 0), g.version, g.created_at, 
+		g.updated_at, ARRAY(SELECT permission FROM groups_to_permissions WHERE group_id = g.id ORDER BY permission), 
+		ARRAY(SELECT permission FROM group_effective_permissions WHERE group_id = g.id ORDER BY permission) 
+		FROM groups g WHERE g.id = $1`
 
 func (p *postgresGroupsRepo) GetByID(ctx context.Context, id domain.ID) (domain.Group, error) {
 	var group domain.Group
+	var permissions, effective []string
 	row := p.db.QueryRow(ctx, getGroupByID, id)
 
-	if err := row.Scan(&group.Name, &group.Permissions, &group.CreatedAt, &group.UpdatedAt); err != nil {
+	if err := row.Scan(&group.Name, &group.Scope, &group.ParentID, &group.Version, &group.CreatedAt, &group.UpdatedAt,
+		&permissions, &effective); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return domain.Group{}, domain.NotFound
 		}
@@ -74,104 +111,169 @@ func (p *postgresGroupsRepo) GetByID(ctx context.Context, id domain.ID) (domain.
 	}
 
 	group.ID = id
+	group.Permissions = domain.PermissionsFromNames(permissions)
+	group.EffectivePermissions = domain.PermissionsFromNames(effective)
 	return group, nil
 }
 
-const getGroupsByName = `SELECT id, name, permissions, created_at, updated_at FROM groups WHERE name LIKE $1`
+// getGroupsByPermissionsQuery matches inherited permissions too.
+const getGroupsByPermissionsQuery = `SELECT g.id, g.name, g.scope, COALESCE(g.parent_id, 0), g.version, g.created_at, 
+		g.updated_at, ARRAY(SELECT permission FROM groups_to_permissions WHERE group_id = g.id ORDER BY permission), 
+		ARRAY(SELECT permission FROM group_effective_permissions WHERE group_id = g.id ORDER BY permission) 
+		FROM groups g 
+		WHERE EXISTS (SELECT 1 FROM group_effective_permissions e 
+			WHERE e.group_id = g.id AND e.permission = ANY($1))`
 
-func (p *postgresGroupsRepo) GetByName(ctx context.Context, name string) ([]domain.Group, error) {
+func (p *postgresGroupsRepo) GetByPermissions(ctx context.Context,
+	permissions domain.Permissions) ([]domain.Group, error) {
 	var groups []domain.Group
-	rows, err := p.db.Query(ctx, getGroupsByName, name)
+	rows, err := p.db.Query(ctx, getGroupsByPermissionsQuery, permissions.Names())
 	if err != nil {
-		return nil, fmt.Errorf("cannot get groups by name: %w", err)
+		return nil, fmt.Errorf("cannot get group by permissions: %w", err)
 	}
 	defer rows.Close()
 
 	for rows.Next() {
 		var group domain.Group
-		if err := rows.Scan(&group.ID, &group.Name, &group.Permissions, &group.CreatedAt, &group.UpdatedAt); err != nil {
+		var permissions, effective []string
+		if err := rows.Scan(&group.ID, &group.Name, &group.Scope, &group.ParentID, &group.Version, &group.CreatedAt,
+			&group.UpdatedAt, &permissions, &effective); err != nil {
 			return nil, fmt.Errorf("cannot scan group: %w", err)
 		}
+		group.Permissions = domain.PermissionsFromNames(permissions)
+		group.EffectivePermissions = domain.PermissionsFromNames(effective)
 		groups = append(groups, group)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("cannot get groups by name: %w", err)
+		return nil, fmt.Errorf("cannot get group by permissions: %w", err)
 	}
 
 	return groups, nil
 }
 
-const getGroupsByPermissionsQuery = `SELECT id, name, permissions, created_at, updated_at FROM groups 
-		WHERE permissions & $1 > 0`
+var groupsList = listSpec{
+	columns: `g.id, g.name, g.scope, COALESCE(g.parent_id, 0), g.version, g.created_at, 
+		g.updated_at, ARRAY(SELECT permission FROM groups_to_permissions WHERE group_id = g.id ORDER BY permission), 
+		ARRAY(SELECT permission FROM group_effective_permissions WHERE group_id = g.id ORDER BY permission)`,
+	from:    "groups g",
+	id:      "g.id",
+	created: "g.created_at",
+	sorts: map[string]sortColumn{
+		"id":         {expr: "g.id", typ: "bigint"},
+		"created_at": {expr: "g.created_at", typ: "timestamptz"},
+		"name":       {expr: "g.name", typ: "text"},
+	},
+	name: "g.name",
+}
+
+func (p *postgresGroupsRepo) GetAll(ctx context.Context, opts domain.ListOptions) ([]domain.Group, string, error) {
+	q, err := newListQuery(groupsList, opts)
+	if err != nil {
+		return nil, "", err
+	}
 
-func (p *postgresGroupsRepo) GetByPermissions(ctx context.Context, permission domain.Permission) ([]domain.Group, error) {
 	var groups []domain.Group
-	rows, err := p.db.Query(ctx, getGroupsByPermissionsQuery, permission)
+	rows, err := p.db.Query(ctx, q.sql(), q.args...)
 	if err != nil {
-		return nil, fmt.Errorf("cannot get group by permissions: %w", err)
+		return nil, "", fmt.Errorf("cannot get groups: %w", err)
 	}
 	defer rows.Close()
 
 	for rows.Next() {
 		var group domain.Group
-		if err := rows.Scan(&group.ID, &group.Name, &group.Permissions, &group.CreatedAt, &group.UpdatedAt); err != nil {
-			return nil, fmt.Errorf("cannot scan group: %w", err)
+		var permissions, effective []string
+		var key string
+		if err := rows.Scan(&group.ID, &group.Name, &group.Scope, &group.ParentID, &group.Version, &group.CreatedAt,
+			&group.UpdatedAt, &permissions, &effective, &key); err != nil {
+			return nil, "", fmt.Errorf("cannot scan group: %w", err)
 		}
+		group.Permissions = domain.PermissionsFromNames(permissions)
+		group.EffectivePermissions = domain.PermissionsFromNames(effective)
+		q.add(group.ID, key)
 		groups = append(groups, group)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("cannot get group by permissions: %w", err)
+		return nil, "", fmt.Errorf("cannot get groups: %w", err)
 	}
 
-	return groups, nil
+	return groups[:q.size()], q.next(), nil
 }
 
-const getAllGroupsQuery = `SELECT id, name, permissions, created_at, updated_at FROM groups`
-
-func (p *postgresGroupsRepo) GetAll(ctx context.Context) ([]domain.Group, error) {
+func (p *postgresGroupsRepo) GetByName(ctx context.Context, name string) ([]domain.Group, error) {
 	var groups []domain.Group
-	rows, err := p.db.Query(ctx, getAllGroupsQuery)
+	opts := domain.ListOptions{Filter: domain.ListFilter{Name: name}}
+	err := allPages(opts, func(opts domain.ListOptions) (string, error) {
+		page, next, err := p.GetAll(ctx, opts)
+		groups = append(groups, page...)
+		return next, err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("cannot get group by permissions: %w", err)
+		return nil, err
+	}
+	return groups, nil
+}
+
+// getAncestorsQuery walks up the parents of a group, the group itself included. It relies on
+// GroupsService keeping the parents free of cycles.
+const getAncestorsQuery = `WITH RECURSIVE ancestors(id, parent_id) AS (
+			SELECT id, parent_id FROM groups WHERE id = $1 
+			UNION 
+			SELECT g.id, g.parent_id FROM groups g JOIN ancestors a ON g.id = a.parent_id
+		)
+		SELECT id FROM ancestors`
+
+func (p *postgresGroupsRepo) GetAncestors(ctx context.Context, id domain.ID) ([]domain.ID, error) {
+	var ids []domain.ID
+	rows, err := p.db.Query(ctx, getAncestorsQuery, id)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get group ancestors: %w", err)
 	}
 	defer rows.Close()
 
 	for rows.Next() {
-		var group domain.Group
-		if err := rows.Scan(&group.ID, &group.Name, &group.Permissions, &group.CreatedAt, &group.UpdatedAt); err != nil {
-			return nil, fmt.Errorf("cannot scan group: %w", err)
+		var ancestor domain.ID
+		if err := rows.Scan(&ancestor); err != nil {
+			return nil, fmt.Errorf("cannot scan group id: %w", err)
 		}
-		groups = append(groups, group)
+		ids = append(ids, ancestor)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("cannot get group by permissions: %w", err)
+		return nil, fmt.Errorf("cannot get group ancestors: %w", err)
 	}
 
-	return groups, nil
+	return ids, nil
 }
 
-const addUserToGroupQuery = `INSERT INTO users_to_groups(user_id, group_id) VALUES ($1, $2)`
+// addUserToGroupQuery replaces temporary memberships, so they can be extended or made
+// permanent, but leaves permanent ones alone.
+const addUserToGroupQuery = `INSERT INTO users_to_groups(user_id, group_id, city_id, expires_at) 
+		VALUES ($1, $2, NULLIF($3, 0), $4) 
+		ON CONFLICT (user_id, group_id, coalesce(city_id, 0)) DO UPDATE SET expires_at = excluded.expires_at 
+		WHERE users_to_groups.expires_at IS NOT NULL`
 
-func (p *postgresGroupsRepo) AddUser(ctx context.Context, groupID domain.ID, userID domain.ID) error {
-	tag, err := p.db.Exec(ctx, addUserToGroupQuery, userID, groupID)
+func (p *postgresGroupsRepo) AddUser(ctx context.Context, member domain.Member) error {
+	tag, err := p.db.Exec(ctx, addUserToGroupQuery, member.UserID, member.GroupID, member.CityID, member.ExpiresAt)
 	if err != nil {
 		return fmt.Errorf("cannot add user to group: %w", err)
 	}
 
 	if tag.RowsAffected() != 1 {
-		return domain.NotFound
+		return fmt.Errorf("%w: user is already a member of the group", domain.AlreadyExists)
 	}
 	return nil
 }
 
-const getUserGroupsQuery = `SELECT id, name, permissions, created_at, updated_at FROM groups 
-		WHERE id IN (SELECT group_id FROM users_to_groups WHERE  user_id = $1)`
+const getUserGroupsQuery = `SELECT g.id, g.name, g.scope, COALESCE(g.parent_id, 0), g.version, g.created_at, 
+		g.updated_at, ARRAY(SELECT permission FROM groups_to_permissions WHERE group_id = g.id ORDER BY permission), 
+		ARRAY(SELECT permission FROM group_effective_permissions WHERE group_id = g.id ORDER BY permission), 
+		COALESCE(ug.city_id, 0), ug.expires_at FROM groups g JOIN users_to_groups ug ON ug.group_id = g.id 
+		WHERE ug.user_id = $1 AND (ug.expires_at IS NULL OR ug.expires_at > now())`
 
-func (p *postgresGroupsRepo) GetUserGroups(ctx context.Context, userID domain.ID) ([]domain.Group, error) {
-	var groups []domain.Group
+func (p *postgresGroupsRepo) GetUserGroups(ctx context.Context, userID domain.ID) ([]domain.Membership, error) {
+	var memberships []domain.Membership
 	rows, err := p.db.Query(ctx, getUserGroupsQuery, userID)
 	if err != nil {
 		return nil, fmt.Errorf("cannot get user groups: %w", err)
@@ -179,26 +281,45 @@ func (p *postgresGroupsRepo) GetUserGroups(ctx context.Context, userID domain.ID
 	defer rows.Close()
 
 	for rows.Next() {
-		var group domain.Group
-		if err := rows.Scan(&group.ID, &group.Name, &group.Permissions, &group.CreatedAt, &group.UpdatedAt); err != nil {
+		var membership domain.Membership
+		var permissions, effective []string
+		if err := rows.Scan(&membership.ID, &membership.Name, &membership.Scope, &membership.ParentID,
+			&membership.Version, &membership.CreatedAt, &membership.UpdatedAt, &permissions, &effective, &membership.CityID,
+			&membership.ExpiresAt); err != nil {
 			return nil, fmt.Errorf("cannot scan group: %w", err)
 		}
-		groups = append(groups, group)
+		membership.Permissions = domain.PermissionsFromNames(permissions)
+		membership.EffectivePermissions = domain.PermissionsFromNames(effective)
+		memberships = append(memberships, membership)
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("cannot get user groups: %w", err)
 	}
 
-	return groups, nil
+	return memberships, nil
 }
 
-const removeUserFromGroupQuery = `DELETE FROM users_to_groups WHERE user_id = $1 AND group_id = $2`
+const removeUserFromGroupQuery = `DELETE FROM users_to_groups WHERE user_id = $1 AND group_id = $2 
+		AND COALESCE(city_id, 0) = $3`
 
-func (p *postgresGroupsRepo) RemoveUser(ctx context.Context, groupID domain.ID, userID domain.ID) error {
-	_, err := p.db.Exec(ctx, removeUserFromGroupQuery, userID, groupID)
+func (p *postgresGroupsRepo) RemoveUser(ctx context.Context, groupID domain.ID, userID domain.ID,
+	cityID domain.ID) error {
+	tag, err := p.db.Exec(ctx, removeUserFromGroupQuery, userID, groupID, cityID)
 	if err != nil {
 		return fmt.Errorf("cannot remove user from group: %w", err)
 	}
+	if tag.RowsAffected() == 0 {
+		return domain.NotFound
+	}
+	return nil
+}
+
+const deleteExpiredMembersQuery = `DELETE FROM users_to_groups WHERE expires_at <= $1`
+
+func (p *postgresGroupsRepo) DeleteExpiredMembers(ctx context.Context, now time.Time) error {
+	if _, err := p.db.Exec(ctx, deleteExpiredMembersQuery, now); err != nil {
+		return fmt.Errorf("cannot delete expired members: %w", err)
+	}
 	return nil
 }
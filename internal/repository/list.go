@@ -0,0 +1,257 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"foodsharing-backend/internal/domain"
+)
+
+// sortColumn is an expression lists can be ordered by. Cursors carry its value as text,
+// which is cast back to typ.
+type sortColumn struct {
+	expr string
+	typ  string
+}
+
+// timestampLayouts are the ways PostgreSQL prints a timestamptz with the ISO date style,
+// the offset depends on the time zone of the session.
+var timestampLayouts = []string{
+	"2006-01-02 15:04:05.999999-07",
+	"2006-01-02 15:04:05.999999-07:00",
+	"2006-01-02 15:04:05.999999-07:00:00",
+}
+
+// checkValue makes sure a cursor value casts to typ, so that a forged cursor is rejected
+// as invalid input instead of failing the query.
+func (s sortColumn) checkValue(value string) error {
+	valid := false
+	switch s.typ {
+	case "bigint":
+		_, err := strconv.ParseInt(value, 10, 64)
+		valid = err == nil
+	case "date":
+		_, err := time.Parse("2006-01-02", value)
+		valid = err == nil
+	case "timestamptz":
+		for _, layout := range timestampLayouts {
+			if _, err := time.Parse(layout, value); err == nil {
+				valid = true
+				break
+			}
+		}
+	case "text":
+		valid = utf8.ValidString(value) && !strings.ContainsRune(value, 0)
+	}
+
+	if !valid {
+		return fmt.Errorf("%w: invalid cursor", domain.InvalidInput)
+	}
+	return nil
+}
+
+// listSpec describes how the records of a table are listed. Filters whose column is
+// empty do not apply to the table.
+type listSpec struct {
+	columns string
+	from    string
+	id      string
+	created string
+	sorts   map[string]sortColumn
+
+	city   string
+	user   string
+	donor  string
+	region string
+	name   string
+	// owner is what ListRestriction.OwnerID is matched against.
+	owner string
+	// deleted is the column marking deleted records, empty when deleted records are removed.
+	deleted string
+
+	actor      string
+	entityType string
+	entityID   string
+}
+
+// cursor is the position of the last record of a page in the order it was listed in.
+type cursor struct {
+	Sort  string           `json:"s"`
+	Order domain.SortOrder `json:"o"`
+	Value string           `json:"v"`
+	ID    domain.ID        `json:"id"`
+}
+
+func (c cursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(value string) (cursor, error) {
+	var c cursor
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err == nil {
+		err = json.Unmarshal(data, &c)
+	}
+	if err != nil {
+		return cursor{}, fmt.Errorf("%w: invalid cursor", domain.InvalidInput)
+	}
+	return c, nil
+}
+
+// listQuery builds the query of a page. It fetches one record more than the limit to
+// find out whether there is a next page. Every row ends with the sort key as text,
+// which is passed to add along with the ID of the record.
+type listQuery struct {
+	spec       listSpec
+	opts       domain.ListOptions
+	sort       sortColumn
+	conditions []string
+	args       []interface{}
+
+	keys []string
+	ids  []domain.ID
+}
+
+func newListQuery(spec listSpec, opts domain.ListOptions) (*listQuery, error) {
+	opts, err := opts.Normalize()
+	if err != nil {
+		return nil, err
+	}
+	sort, ok := spec.sorts[opts.Sort]
+	if !ok {
+		return nil, fmt.Errorf("%w: cannot sort by %q", domain.InvalidInput, opts.Sort)
+	}
+	q := &listQuery{spec: spec, opts: opts, sort: sort}
+
+	if opts.Cursor != "" {
+		c, err := decodeCursor(opts.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		if c.Sort != opts.Sort || c.Order != opts.Order {
+			return nil, fmt.Errorf("%w: cursor belongs to another order", domain.InvalidInput)
+		}
+		if err := sort.checkValue(c.Value); err != nil {
+			return nil, err
+		}
+		comparison := ">"
+		if opts.Order == domain.Descending {
+			comparison = "<"
+		}
+		q.where(fmt.Sprintf("(%s, %s) %s (%%s::%s, %%s)", sort.expr, spec.id, comparison, sort.typ), c.Value, c.ID)
+	}
+
+	switch {
+	case spec.deleted == "" && opts.Deleted:
+		return nil, fmt.Errorf("%w: %s keeps no deleted records", domain.InvalidInput, spec.from)
+	case spec.deleted == "":
+	case opts.Deleted:
+		q.where(spec.deleted + " IS NOT NULL")
+	default:
+		q.where(spec.deleted + " IS NULL")
+	}
+
+	filter := opts.Filter
+	q.whereSet(spec.city, filter.CityID)
+	q.whereSet(spec.user, filter.UserID)
+	q.whereSet(spec.donor, filter.DonorCompanyID)
+	q.whereSet(spec.region, filter.RegionID)
+	q.whereSet(spec.actor, filter.ActorID)
+	q.whereSet(spec.entityID, filter.EntityID)
+	if spec.entityType != "" && filter.EntityType != "" {
+		q.where(spec.entityType+" = %s", filter.EntityType)
+	}
+	if spec.name != "" && filter.Name != "" {
+		q.where(spec.name+" LIKE %s", filter.Name)
+	}
+	if filter.CreatedFrom != nil {
+		q.where(spec.created+" >= %s", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		q.where(spec.created+" < %s", *filter.CreatedTo)
+	}
+
+	if r := opts.Restriction; r != nil {
+		if spec.city == "" {
+			return nil, fmt.Errorf("%w: %s cannot be restricted to cities", domain.InvalidInput, spec.from)
+		}
+		cities := make([]int64, len(r.CityIDs))
+		for i, id := range r.CityIDs {
+			cities[i] = int64(id)
+		}
+		if spec.owner != "" && r.OwnerID != 0 {
+			q.where(fmt.Sprintf("(%s = ANY(%%s) OR %s = %%s)", spec.city, spec.owner), cities, r.OwnerID)
+		} else {
+			q.where(spec.city+" = ANY(%s)", cities)
+		}
+	}
+	return q, nil
+}
+
+// allPages calls page with the options of every page of the list in turn, for callers
+// that need all records. page returns the cursor of the next page.
+func allPages(opts domain.ListOptions, page func(opts domain.ListOptions) (string, error)) error {
+	opts.Limit = domain.MaxListLimit
+	for {
+		next, err := page(opts)
+		if err != nil || next == "" {
+			return err
+		}
+		opts.Cursor = next
+	}
+}
+
+// where adds a condition, the %s verbs in format are replaced by the placeholders of values.
+func (q *listQuery) where(format string, values ...interface{}) {
+	placeholders := make([]interface{}, len(values))
+	for i, value := range values {
+		q.args = append(q.args, value)
+		placeholders[i] = "$" + strconv.Itoa(len(q.args))
+	}
+	q.conditions = append(q.conditions, fmt.Sprintf(format, placeholders...))
+}
+
+func (q *listQuery) whereSet(column string, id domain.ID) {
+	if column != "" && id != 0 {
+		q.where(column+" = %s", id)
+	}
+}
+
+func (q *listQuery) sql() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "SELECT %s, %s::text FROM %s", q.spec.columns, q.sort.expr, q.spec.from)
+	if len(q.conditions) > 0 {
+		b.WriteString(" WHERE " + strings.Join(q.conditions, " AND "))
+	}
+	fmt.Fprintf(&b, " ORDER BY %s %s, %s %s LIMIT %d", q.sort.expr, q.opts.Order, q.spec.id, q.opts.Order,
+		q.opts.Limit+1)
+	return b.String()
+}
+
+func (q *listQuery) add(id domain.ID, key string) {
+	q.ids = append(q.ids, id)
+	q.keys = append(q.keys, key)
+}
+
+// size is the number of the added records that belong to the page.
+func (q *listQuery) size() int {
+	if len(q.ids) > q.opts.Limit {
+		return q.opts.Limit
+	}
+	return len(q.ids)
+}
+
+// next returns the cursor of the next page or an empty string on the last page.
+func (q *listQuery) next() string {
+	if len(q.ids) <= q.opts.Limit {
+		return ""
+	}
+	last := q.opts.Limit - 1
+	return cursor{Sort: q.opts.Sort, Order: q.opts.Order, Value: q.keys[last], ID: q.ids[last]}.encode()
+}
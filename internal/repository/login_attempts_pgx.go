@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"foodsharing-backend/internal/domain"
+
+	"github.com/jackc/pgx/v4"
+)
+
+type postgresLoginAttemptsRepo struct {
+	db DB
+}
+
+func NewLoginAttemptsRepository(db DB) LoginAttempts {
+	return &postgresLoginAttemptsRepo{db: db}
+}
+
+const getLoginAttemptsQuery = `SELECT failures, last_failure_at, locked_until, COALESCE(last_ip, '') 
+		FROM login_attempts WHERE subject = $1 AND key = $2`
+
+func (p *postgresLoginAttemptsRepo) Get(ctx context.Context, subject domain.LoginSubject,
+	key string) (domain.LoginAttempts, error) {
+	attempts := domain.LoginAttempts{Subject: subject, Key: key}
+	row := p.db.QueryRow(ctx, getLoginAttemptsQuery, subject, key)
+	if err := row.Scan(&attempts.Failures, &attempts.LastFailureAt, &attempts.LockedUntil,
+		&attempts.LastIP); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.LoginAttempts{}, domain.NotFound
+		}
+
+		return domain.LoginAttempts{}, fmt.Errorf("cannot get login attempts: %w", err)
+	}
+
+	return attempts, nil
+}
+
+const addLoginFailureQuery = `INSERT INTO login_attempts (subject, key, failures, last_failure_at, last_ip) 
+		VALUES ($1, $2, 1, $3, NULLIF($5, '')) 
+		ON CONFLICT (subject, key) DO UPDATE SET last_failure_at = excluded.last_failure_at, 
+			last_ip = excluded.last_ip, 
+			failures = CASE WHEN login_attempts.last_failure_at < $4 THEN 1 ELSE login_attempts.failures + 1 END 
+		RETURNING failures, last_failure_at, locked_until, COALESCE(last_ip, '')`
+
+func (p *postgresLoginAttemptsRepo) AddFailure(ctx context.Context, subject domain.LoginSubject, key string,
+	ip string, window time.Duration) (domain.LoginAttempts, error) {
+	now := time.Now()
+	attempts := domain.LoginAttempts{Subject: subject, Key: key}
+	row := p.db.QueryRow(ctx, addLoginFailureQuery, subject, key, now, now.Add(-window), ip)
+	if err := row.Scan(&attempts.Failures, &attempts.LastFailureAt, &attempts.LockedUntil,
+		&attempts.LastIP); err != nil {
+		return domain.LoginAttempts{}, fmt.Errorf("cannot add login failure: %w", err)
+	}
+
+	return attempts, nil
+}
+
+const lockLoginQuery = `UPDATE login_attempts SET locked_until = $3 WHERE subject = $1 AND key = $2`
+
+func (p *postgresLoginAttemptsRepo) Lock(ctx context.Context, subject domain.LoginSubject, key string,
+	until time.Time) error {
+	tag, err := p.db.Exec(ctx, lockLoginQuery, subject, key, until)
+	if err != nil {
+		return fmt.Errorf("cannot lock login: %w", err)
+	}
+	if tag.RowsAffected() != 1 {
+		return domain.NotFound
+	}
+	return nil
+}
+
+const resetLoginAttemptsQuery = `DELETE FROM login_attempts WHERE subject = $1 AND key = $2`
+
+func (p *postgresLoginAttemptsRepo) Reset(ctx context.Context, subject domain.LoginSubject, key string) error {
+	_, err := p.db.Exec(ctx, resetLoginAttemptsQuery, subject, key)
+	if err != nil {
+		return fmt.Errorf("cannot reset login attempts: %w", err)
+	}
+	return nil
+}
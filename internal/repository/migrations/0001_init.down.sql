@@ -0,0 +1,10 @@
+drop table if exists files_to_acts;
+drop table if exists files;
+drop table if exists users_to_groups;
+drop table if exists act_contents;
+drop table if exists acts;
+drop table if exists donor_companies;
+drop table if exists sessions;
+drop table if exists users;
+drop table if exists cities;
+drop table if exists groups;
@@ -1,3 +1,7 @@
+-- The schema as deploy/postgres/script.sql first defined it. The changes made to the script
+-- later follow as migrations 0002 to 0013. Like this one, they tolerate existing objects, so
+-- databases set up with any version of the script can be brought under migrations.
+
 create table if not exists groups
 (
     id          bigserial,
@@ -0,0 +1,14 @@
+-- The sessions are dropped, their tokens cannot be turned back into plain text.
+
+drop table sessions;
+
+create table sessions
+(
+    refresh_token text                     not null,
+    expires_at    timestamp with time zone not null,
+    user_id       bigint,
+    constraint sessions_pkey
+        primary key (refresh_token),
+    constraint sessions_user_id_fkey
+        foreign key (user_id) references users
+);
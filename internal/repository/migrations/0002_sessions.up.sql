@@ -0,0 +1,39 @@
+-- Sessions are rotated within families, store hashes of the refresh tokens and remember the
+-- device they were used from. Refresh tokens used to be stored in plain text. They cannot be
+-- hashed in place without trusting the leaked values, so the old sessions are dropped and
+-- users have to sign in again.
+
+do
+$$
+    begin
+        if exists(select 1
+                  from information_schema.columns
+                  where table_name = 'sessions'
+                    and column_name = 'refresh_token') then
+            drop table sessions;
+        end if;
+    end
+$$;
+
+create table if not exists sessions
+(
+    token_hash    text                     not null,
+    family_id     uuid                     not null,
+    expires_at    timestamp with time zone not null,
+    created_at    timestamp with time zone not null,
+    last_used_at  timestamp with time zone not null,
+    rotated_at    timestamp with time zone,
+    user_agent    text                     not null,
+    ip            text                     not null,
+    user_id       bigint,
+    constraint sessions_pkey
+        primary key (token_hash),
+    constraint sessions_user_id_fkey
+        foreign key (user_id) references users
+);
+
+create index if not exists sessions_family_id_index
+    on sessions (family_id);
+
+create index if not exists sessions_user_id_index
+    on sessions (user_id);
@@ -0,0 +1,12 @@
+create table if not exists credentials
+(
+    user_id       bigint                   not null,
+    password_hash text                     not null,
+    created_at    timestamp with time zone not null,
+    updated_at    timestamp with time zone,
+    constraint credentials_pkey
+        primary key (user_id),
+    constraint credentials_user_id_fkey
+        foreign key (user_id) references users
+            on delete cascade
+);
@@ -0,0 +1,4 @@
+drop table user_tokens;
+
+alter table users
+    drop column email_verified_at;
@@ -0,0 +1,20 @@
+-- Single-use tokens of the password reset and email verification flows.
+
+alter table users
+    add column if not exists email_verified_at timestamp with time zone;
+
+create table if not exists user_tokens
+(
+    token_hash text                     not null,
+    user_id    bigint                   not null,
+    purpose    text                     not null,
+    email      text                     not null,
+    expires_at timestamp with time zone not null,
+    created_at timestamp with time zone not null,
+    used_at    timestamp with time zone,
+    constraint user_tokens_pkey
+        primary key (token_hash),
+    constraint user_tokens_user_id_fkey
+        foreign key (user_id) references users
+            on delete cascade
+);
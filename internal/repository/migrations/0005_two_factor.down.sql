@@ -0,0 +1,3 @@
+drop table recovery_codes;
+
+drop table two_factor;
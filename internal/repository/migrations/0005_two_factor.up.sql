@@ -0,0 +1,28 @@
+create table if not exists two_factor
+(
+    user_id        bigint                   not null,
+    secret         text                     not null,
+    enabled_at     timestamp with time zone,
+    last_used_step bigint                   not null,
+    created_at     timestamp with time zone not null,
+    constraint two_factor_pkey
+        primary key (user_id),
+    constraint two_factor_user_id_fkey
+        foreign key (user_id) references users
+            on delete cascade
+);
+
+create table if not exists recovery_codes
+(
+    code_hash text   not null,
+    user_id   bigint not null,
+    used_at   timestamp with time zone,
+    constraint recovery_codes_pkey
+        primary key (code_hash),
+    constraint recovery_codes_user_id_fkey
+        foreign key (user_id) references two_factor
+            on delete cascade
+);
+
+create index if not exists recovery_codes_user_id_index
+    on recovery_codes (user_id);
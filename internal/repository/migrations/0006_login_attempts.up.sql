@@ -0,0 +1,10 @@
+create table if not exists login_attempts
+(
+    subject         text                     not null,
+    key             text                     not null,
+    failures        integer                  not null,
+    last_failure_at timestamp with time zone not null,
+    locked_until    timestamp with time zone,
+    constraint login_attempts_pkey
+        primary key (subject, key)
+);
@@ -0,0 +1,2 @@
+alter table groups
+    drop column scope;
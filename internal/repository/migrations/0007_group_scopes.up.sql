@@ -0,0 +1,5 @@
+-- The scope of a group limits its permissions to own records, the city of the member or
+-- all records.
+
+alter table groups
+    add column if not exists scope text default 'all'::text not null;
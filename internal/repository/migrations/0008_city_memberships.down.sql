@@ -0,0 +1,17 @@
+-- Memberships limited to cities become global ones, duplicates are dropped.
+
+drop index users_to_groups_uindex;
+
+delete
+from users_to_groups a
+    using users_to_groups b
+where a.user_id = b.user_id
+  and a.group_id = b.group_id
+  and coalesce(a.city_id, 0) > coalesce(b.city_id, 0);
+
+alter table users_to_groups
+    drop column city_id;
+
+alter table users_to_groups
+    add constraint users_to_groups_pkey
+        primary key (user_id, group_id);
@@ -0,0 +1,12 @@
+-- A membership may be limited to a city, so a user may be a member of the same group once
+-- globally and once per city.
+
+alter table users_to_groups
+    add column if not exists city_id bigint
+        constraint users_to_groups_city_id_fkey references cities;
+
+alter table users_to_groups
+    drop constraint if exists users_to_groups_pkey;
+
+create unique index if not exists users_to_groups_uindex
+    on users_to_groups (user_id, group_id, coalesce(city_id, 0));
@@ -0,0 +1,2 @@
+alter table users_to_groups
+    drop column expires_at;
@@ -0,0 +1,2 @@
+alter table users_to_groups
+    add column if not exists expires_at timestamp with time zone;
@@ -0,0 +1,2 @@
+alter table groups
+    drop column parent_id;
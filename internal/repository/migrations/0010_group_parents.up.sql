@@ -0,0 +1,10 @@
+-- Groups inherit the permissions of their parents. The view of the inherited permissions
+-- came along with this change, it reads them from the bitmask and was replaced by
+-- group_effective_permissions in 0011, which also drops it. It is left out here so that
+-- databases set up with a later version of the script, which has no bitmask, can be
+-- migrated too.
+
+alter table groups
+    add column if not exists parent_id bigint
+        constraint groups_parent_id_fkey references groups
+            on delete set null;
@@ -0,0 +1,24 @@
+-- The names are converted back to the bits they stand for. Permissions added since the
+-- bitmask was dropped have no bit and are lost.
+
+drop view group_effective_permissions;
+
+alter table groups
+    add column permissions bigint not null default 0;
+
+update groups g
+set permissions = coalesce((select bit_or(1::bigint << (p.bit - 1)::integer)
+                            from groups_to_permissions gp
+                                     join unnest(array ['admin',
+                                'users:create', 'users:read', 'users:edit',
+                                'acts:create', 'acts:read', 'acts:edit',
+                                'cities:add', 'cities:read', 'cities:edit',
+                                'donor-companies:add', 'donor-companies:read', 'donor-companies:edit',
+                                'groups:create', 'groups:read', 'groups:edit']) with ordinality as p(name, bit)
+                                          on p.name = gp.permission
+                            where gp.group_id = g.id), 0);
+
+alter table groups
+    alter column permissions drop default;
+
+drop table groups_to_permissions;
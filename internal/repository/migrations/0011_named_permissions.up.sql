@@ -0,0 +1,54 @@
+create table if not exists groups_to_permissions
+(
+    group_id   bigint not null,
+    permission text   not null,
+    constraint groups_to_permissions_pkey
+        primary key (group_id, permission),
+    constraint groups_to_permissions_group_id_fkey
+        foreign key (group_id) references groups
+            on delete cascade
+);
+
+-- Permissions used to be a bitmask in groups.permissions. The bits are converted to the
+-- names they stood for, in the order they were declared, and the column is dropped.
+drop view if exists group_permissions;
+
+do
+$$
+    begin
+        if exists(select 1
+                  from information_schema.columns
+                  where table_name = 'groups'
+                    and column_name = 'permissions') then
+            insert into groups_to_permissions (group_id, permission)
+            select g.id, p.name
+            from groups g
+                     join unnest(array ['admin',
+                'users:create', 'users:read', 'users:edit',
+                'acts:create', 'acts:read', 'acts:edit',
+                'cities:add', 'cities:read', 'cities:edit',
+                'donor-companies:add', 'donor-companies:read', 'donor-companies:edit',
+                'groups:create', 'groups:read', 'groups:edit']) with ordinality as p(name, bit)
+                          on g.permissions & (1::bigint << (p.bit - 1)::integer) <> 0
+            on conflict do nothing;
+
+            alter table groups
+                drop column permissions;
+        end if;
+    end
+$$;
+
+-- Permissions of every group including the ones inherited from its parents. The service
+-- keeps the parents free of cycles.
+create or replace view group_effective_permissions as
+with recursive chain(group_id, id, parent_id) as (
+    select id, id, parent_id
+    from groups
+    union
+    select c.group_id, g.id, g.parent_id
+    from chain c
+             join groups g on g.id = c.parent_id
+)
+select distinct c.group_id, gp.permission
+from chain c
+         join groups_to_permissions gp on gp.group_id = c.id;
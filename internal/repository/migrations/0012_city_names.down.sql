@@ -0,0 +1 @@
+drop index cities_name_uindex;
@@ -0,0 +1,25 @@
+-- Cities are told apart by their names, so no two may differ only in case.
+
+-- Merging cities means moving their users, donor companies and group memberships, which is
+-- not for a migration to decide. It stops and names the cities instead, rename or merge them
+-- and run it again.
+do
+$$
+    declare
+        duplicates text;
+    begin
+        select string_agg(name, ', ')
+        into duplicates
+        from (select lower(name) as name
+              from cities
+              group by lower(name)
+              having count(*) > 1) d;
+
+        if duplicates is not null then
+            raise exception 'several cities share the names %, resolve them before migrating', duplicates;
+        end if;
+    end
+$$;
+
+create unique index if not exists cities_name_uindex
+    on cities (lower(name));
@@ -0,0 +1,7 @@
+alter table cities
+    drop column longitude,
+    drop column latitude,
+    drop column timezone,
+    drop column region_id;
+
+drop table regions;
@@ -0,0 +1,34 @@
+-- Cities are grouped into regions and carry the time zone their dates are read in.
+
+create table if not exists regions
+(
+    id         bigserial,
+    name       text                     not null,
+    created_at timestamp with time zone not null,
+    updated_at timestamp with time zone,
+    constraint regions_pkey
+        primary key (id)
+);
+
+create unique index if not exists regions_name_uindex
+    on regions (lower(name));
+
+alter table cities
+    add column if not exists region_id bigint
+        constraint cities_region_id_fkey references regions,
+    add column if not exists timezone  text default 'UTC'::text not null,
+    add column if not exists latitude  double precision,
+    add column if not exists longitude double precision;
+
+do
+$$
+    begin
+        if not exists(select 1
+                      from pg_constraint
+                      where conname = 'cities_coordinates_check') then
+            alter table cities
+                add constraint cities_coordinates_check
+                    check ((latitude is null) = (longitude is null));
+        end if;
+    end
+$$;
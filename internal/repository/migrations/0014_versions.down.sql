@@ -0,0 +1,23 @@
+alter table files
+    drop column version;
+
+alter table act_contents
+    drop column version;
+
+alter table acts
+    drop column version;
+
+alter table donor_companies
+    drop column version;
+
+alter table users
+    drop column version;
+
+alter table cities
+    drop column version;
+
+alter table regions
+    drop column version;
+
+alter table groups
+    drop column version;
@@ -0,0 +1,26 @@
+-- Every change of a record increments its version, updates based on an older version
+-- are rejected.
+
+alter table groups
+    add column version bigint not null default 1;
+
+alter table regions
+    add column version bigint not null default 1;
+
+alter table cities
+    add column version bigint not null default 1;
+
+alter table users
+    add column version bigint not null default 1;
+
+alter table donor_companies
+    add column version bigint not null default 1;
+
+alter table acts
+    add column version bigint not null default 1;
+
+alter table act_contents
+    add column version bigint not null default 1;
+
+alter table files
+    add column version bigint not null default 1;
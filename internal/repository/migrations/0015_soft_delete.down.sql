@@ -0,0 +1,16 @@
+-- Records deleted since are not removed, they become visible again.
+
+drop index acts_deleted_at_index;
+
+drop index donor_companies_deleted_at_index;
+
+drop index users_deleted_at_index;
+
+alter table acts
+    drop column deleted_at;
+
+alter table donor_companies
+    drop column deleted_at;
+
+alter table users
+    drop column deleted_at;
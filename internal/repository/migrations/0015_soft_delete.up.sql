@@ -0,0 +1,20 @@
+-- Users, donor companies and acts are marked as deleted instead of being removed, so they
+-- can be restored until they are purged after the retention period.
+
+alter table users
+    add column deleted_at timestamp with time zone;
+
+alter table donor_companies
+    add column deleted_at timestamp with time zone;
+
+alter table acts
+    add column deleted_at timestamp with time zone;
+
+create index users_deleted_at_index
+    on users (deleted_at) where deleted_at is not null;
+
+create index donor_companies_deleted_at_index
+    on donor_companies (deleted_at) where deleted_at is not null;
+
+create index acts_deleted_at_index
+    on acts (deleted_at) where deleted_at is not null;
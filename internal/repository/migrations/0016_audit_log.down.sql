@@ -0,0 +1,27 @@
+drop trigger files_audit on files;
+
+drop trigger files_to_acts_audit on files_to_acts;
+
+drop trigger act_contents_audit on act_contents;
+
+drop trigger acts_audit on acts;
+
+drop trigger donor_companies_audit on donor_companies;
+
+drop trigger cities_audit on cities;
+
+drop trigger regions_audit on regions;
+
+drop trigger users_to_groups_audit on users_to_groups;
+
+drop trigger groups_to_permissions_audit on groups_to_permissions;
+
+drop trigger groups_audit on groups;
+
+drop trigger users_audit on users;
+
+drop function audit_change();
+
+drop table audit_log;
+
+drop function audit_log_append_only();
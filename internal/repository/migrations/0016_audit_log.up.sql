@@ -0,0 +1,149 @@
+-- Every change of the data is recorded by triggers, along with the user who made it. The
+-- application names the user in the app.actor_id setting of the connection. The tables of
+-- sessions, credentials and the like are left out, they hold secrets and change on every
+-- sign-in.
+
+create table audit_log
+(
+    id          bigserial,
+    actor_id    bigint,
+    action      text                     not null,
+    entity_type text                     not null,
+    entity_id   bigint                   not null,
+    before      jsonb,
+    after       jsonb,
+    created_at  timestamp with time zone not null,
+    constraint audit_log_pkey
+        primary key (id)
+);
+
+create index audit_log_entity_index
+    on audit_log (entity_type, entity_id);
+
+create index audit_log_actor_id_index
+    on audit_log (actor_id);
+
+create index audit_log_created_at_index
+    on audit_log (created_at);
+
+-- Records are appended only. Actor IDs are kept without a foreign key so that the log
+-- outlives purged users.
+create function audit_log_append_only() returns trigger
+    language plpgsql as
+$$
+begin
+    raise exception 'audit_log is append-only';
+end
+$$;
+
+create trigger audit_log_append_only
+    before update or delete or truncate
+    on audit_log
+    for each statement
+execute procedure audit_log_append_only();
+
+-- audit_change records a row change of an entity whose type is the first argument of the
+-- trigger and whose ID is in the column named by the second one. Marking a row as deleted
+-- is a delete and unmarking it a restore, removing a row marked as deleted is a purge.
+create function audit_change() returns trigger
+    language plpgsql as
+$$
+declare
+    old_row jsonb;
+    new_row jsonb;
+    change  text;
+begin
+    if tg_op = 'INSERT' then
+        change := 'create';
+        new_row := to_jsonb(new);
+    elsif tg_op = 'UPDATE' then
+        change := 'update';
+        old_row := to_jsonb(old);
+        new_row := to_jsonb(new);
+        if old_row = new_row then
+            return null;
+        elsif old_row ->> 'deleted_at' is null and new_row ->> 'deleted_at' is not null then
+            change := 'delete';
+        elsif old_row ->> 'deleted_at' is not null and new_row ->> 'deleted_at' is null then
+            change := 'restore';
+        end if;
+    else
+        change := 'delete';
+        old_row := to_jsonb(old);
+        if old_row ->> 'deleted_at' is not null then
+            change := 'purge';
+        end if;
+    end if;
+
+    insert into audit_log (actor_id, action, entity_type, entity_id, before, after, created_at)
+    values (nullif(current_setting('app.actor_id', true), '')::bigint, change, tg_argv[0],
+            (coalesce(new_row, old_row) ->> tg_argv[1])::bigint, old_row, new_row, clock_timestamp());
+    return null;
+end
+$$;
+
+create trigger users_audit
+    after insert or update or delete
+    on users
+    for each row
+execute procedure audit_change('user', 'id');
+
+create trigger groups_audit
+    after insert or update or delete
+    on groups
+    for each row
+execute procedure audit_change('group', 'id');
+
+create trigger groups_to_permissions_audit
+    after insert or update or delete
+    on groups_to_permissions
+    for each row
+execute procedure audit_change('group_permission', 'group_id');
+
+create trigger users_to_groups_audit
+    after insert or update or delete
+    on users_to_groups
+    for each row
+execute procedure audit_change('membership', 'user_id');
+
+create trigger regions_audit
+    after insert or update or delete
+    on regions
+    for each row
+execute procedure audit_change('region', 'id');
+
+create trigger cities_audit
+    after insert or update or delete
+    on cities
+    for each row
+execute procedure audit_change('city', 'id');
+
+create trigger donor_companies_audit
+    after insert or update or delete
+    on donor_companies
+    for each row
+execute procedure audit_change('donor_company', 'id');
+
+create trigger acts_audit
+    after insert or update or delete
+    on acts
+    for each row
+execute procedure audit_change('act', 'id');
+
+create trigger act_contents_audit
+    after insert or update or delete
+    on act_contents
+    for each row
+execute procedure audit_change('act_content', 'id');
+
+create trigger files_to_acts_audit
+    after insert or update or delete
+    on files_to_acts
+    for each row
+execute procedure audit_change('act_file', 'act_id');
+
+create trigger files_audit
+    after insert or update or delete
+    on files
+    for each row
+execute procedure audit_change('file', 'id');
@@ -0,0 +1,27 @@
+-- Emails identify users when they sign in, so no two live users may share one regardless
+-- of its case. Deleted users keep their email until they are purged.
+
+-- Which of two accounts sharing an email is the real one is not for a migration to decide,
+-- so the migration stops and names the emails. Change or delete all but one of each and run
+-- it again.
+do
+$$
+    declare
+        duplicates text;
+    begin
+        select string_agg(email, ', ')
+        into duplicates
+        from (select lower(email) as email
+              from users
+              where deleted_at is null
+              group by lower(email)
+              having count(*) > 1) d;
+
+        if duplicates is not null then
+            raise exception 'several users share the emails %, resolve them before migrating', duplicates;
+        end if;
+    end
+$$;
+
+create unique index if not exists users_email_uindex
+    on users (lower(email)) where deleted_at is null;
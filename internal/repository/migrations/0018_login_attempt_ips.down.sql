@@ -0,0 +1,2 @@
+alter table login_attempts
+    drop column if exists last_ip;
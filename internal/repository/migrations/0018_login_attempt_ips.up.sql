@@ -0,0 +1,4 @@
+-- The address of the last failure, so that unlocking an account can lift the lock of the
+-- address it was attacked from as well.
+alter table login_attempts
+    add column if not exists last_ip text;
@@ -0,0 +1,9 @@
+// Package migrations holds the versioned schema of the database. A change to the schema is
+// a new pair of NNNN_name.up.sql and NNNN_name.down.sql files, applied migrations are
+// never edited.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
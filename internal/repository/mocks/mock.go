@@ -7,11 +7,50 @@ package mock_repository
 import (
 	context "context"
 	domain "foodsharing-backend/internal/domain"
+	repository "foodsharing-backend/internal/repository"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 )
 
+// MockTxManager is a mock of TxManager interface.
+type MockTxManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockTxManagerMockRecorder
+}
+
+// MockTxManagerMockRecorder is the mock recorder for MockTxManager.
+type MockTxManagerMockRecorder struct {
+	mock *MockTxManager
+}
+
+// NewMockTxManager creates a new mock instance.
+func NewMockTxManager(ctrl *gomock.Controller) *MockTxManager {
+	mock := &MockTxManager{ctrl: ctrl}
+	mock.recorder = &MockTxManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTxManager) EXPECT() *MockTxManagerMockRecorder {
+	return m.recorder
+}
+
+// WithinTx mocks base method.
+func (m *MockTxManager) WithinTx(ctx context.Context, fn func(*repository.Repositories) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithinTx", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WithinTx indicates an expected call of WithinTx.
+func (mr *MockTxManagerMockRecorder) WithinTx(ctx, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithinTx", reflect.TypeOf((*MockTxManager)(nil).WithinTx), ctx, fn)
+}
+
 // MockUsers is a mock of Users interface.
 type MockUsers struct {
 	ctrl     *gomock.Controller
@@ -50,32 +89,48 @@ func (mr *MockUsersMockRecorder) Create(ctx, user interface{}) *gomock.Call {
 }
 
 // Delete mocks base method.
-func (m *MockUsers) Delete(ctx context.Context, id domain.ID) error {
+func (m *MockUsers) Delete(ctx context.Context, id domain.ID, version int64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret := m.ctrl.Call(m, "Delete", ctx, id, version)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockUsersMockRecorder) Delete(ctx, id interface{}) *gomock.Call {
+func (mr *MockUsersMockRecorder) Delete(ctx, id, version interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockUsers)(nil).Delete), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockUsers)(nil).Delete), ctx, id, version)
 }
 
 // GetAll mocks base method.
-func (m *MockUsers) GetAll(ctx context.Context) ([]domain.User, error) {
+func (m *MockUsers) GetAll(ctx context.Context, opts domain.ListOptions) ([]domain.User, string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAll", ctx)
+	ret := m.ctrl.Call(m, "GetAll", ctx, opts)
+	ret0, _ := ret[0].([]domain.User)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetAll indicates an expected call of GetAll.
+func (mr *MockUsersMockRecorder) GetAll(ctx, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockUsers)(nil).GetAll), ctx, opts)
+}
+
+// GetByCity mocks base method.
+func (m *MockUsers) GetByCity(ctx context.Context, cityID domain.ID) ([]domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByCity", ctx, cityID)
 	ret0, _ := ret[0].([]domain.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetAll indicates an expected call of GetAll.
-func (mr *MockUsersMockRecorder) GetAll(ctx interface{}) *gomock.Call {
+// GetByCity indicates an expected call of GetByCity.
+func (mr *MockUsersMockRecorder) GetByCity(ctx, cityID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockUsers)(nil).GetAll), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByCity", reflect.TypeOf((*MockUsers)(nil).GetByCity), ctx, cityID)
 }
 
 // GetByEmail mocks base method.
@@ -108,6 +163,35 @@ func (mr *MockUsersMockRecorder) GetByID(ctx, id interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockUsers)(nil).GetByID), ctx, id)
 }
 
+// Purge mocks base method.
+func (m *MockUsers) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", ctx, deletedBefore)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockUsersMockRecorder) Purge(ctx, deletedBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockUsers)(nil).Purge), ctx, deletedBefore)
+}
+
+// Restore mocks base method.
+func (m *MockUsers) Restore(ctx context.Context, id domain.ID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockUsersMockRecorder) Restore(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockUsers)(nil).Restore), ctx, id)
+}
+
 // Update mocks base method.
 func (m *MockUsers) Update(ctx context.Context, user domain.User) error {
 	m.ctrl.T.Helper()
@@ -122,6 +206,20 @@ func (mr *MockUsersMockRecorder) Update(ctx, user interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockUsers)(nil).Update), ctx, user)
 }
 
+// VerifyEmail mocks base method.
+func (m *MockUsers) VerifyEmail(ctx context.Context, id domain.ID, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyEmail", ctx, id, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VerifyEmail indicates an expected call of VerifyEmail.
+func (mr *MockUsersMockRecorder) VerifyEmail(ctx, id, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyEmail", reflect.TypeOf((*MockUsers)(nil).VerifyEmail), ctx, id, email)
+}
+
 // MockGroups is a mock of Groups interface.
 type MockGroups struct {
 	ctrl     *gomock.Controller
@@ -141,177 +239,834 @@ func NewMockGroups(ctrl *gomock.Controller) *MockGroups {
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockGroups) EXPECT() *MockGroupsMockRecorder {
+func (m *MockGroups) EXPECT() *MockGroupsMockRecorder {
+	return m.recorder
+}
+
+// AddUser mocks base method.
+func (m *MockGroups) AddUser(ctx context.Context, member domain.Member) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddUser", ctx, member)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddUser indicates an expected call of AddUser.
+func (mr *MockGroupsMockRecorder) AddUser(ctx, member interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddUser", reflect.TypeOf((*MockGroups)(nil).AddUser), ctx, member)
+}
+
+// Create mocks base method.
+func (m *MockGroups) Create(ctx context.Context, group *domain.Group) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, group)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockGroupsMockRecorder) Create(ctx, group interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockGroups)(nil).Create), ctx, group)
+}
+
+// Delete mocks base method.
+func (m *MockGroups) Delete(ctx context.Context, id domain.ID, version int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id, version)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockGroupsMockRecorder) Delete(ctx, id, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockGroups)(nil).Delete), ctx, id, version)
+}
+
+// DeleteExpiredMembers mocks base method.
+func (m *MockGroups) DeleteExpiredMembers(ctx context.Context, now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredMembers", ctx, now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteExpiredMembers indicates an expected call of DeleteExpiredMembers.
+func (mr *MockGroupsMockRecorder) DeleteExpiredMembers(ctx, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredMembers", reflect.TypeOf((*MockGroups)(nil).DeleteExpiredMembers), ctx, now)
+}
+
+// GetAll mocks base method.
+func (m *MockGroups) GetAll(ctx context.Context, opts domain.ListOptions) ([]domain.Group, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll", ctx, opts)
+	ret0, _ := ret[0].([]domain.Group)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetAll indicates an expected call of GetAll.
+func (mr *MockGroupsMockRecorder) GetAll(ctx, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockGroups)(nil).GetAll), ctx, opts)
+}
+
+// GetAncestors mocks base method.
+func (m *MockGroups) GetAncestors(ctx context.Context, id domain.ID) ([]domain.ID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAncestors", ctx, id)
+	ret0, _ := ret[0].([]domain.ID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAncestors indicates an expected call of GetAncestors.
+func (mr *MockGroupsMockRecorder) GetAncestors(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAncestors", reflect.TypeOf((*MockGroups)(nil).GetAncestors), ctx, id)
+}
+
+// GetByID mocks base method.
+func (m *MockGroups) GetByID(ctx context.Context, id domain.ID) (domain.Group, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, id)
+	ret0, _ := ret[0].(domain.Group)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockGroupsMockRecorder) GetByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockGroups)(nil).GetByID), ctx, id)
+}
+
+// GetByName mocks base method.
+func (m *MockGroups) GetByName(ctx context.Context, name string) ([]domain.Group, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByName", ctx, name)
+	ret0, _ := ret[0].([]domain.Group)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByName indicates an expected call of GetByName.
+func (mr *MockGroupsMockRecorder) GetByName(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByName", reflect.TypeOf((*MockGroups)(nil).GetByName), ctx, name)
+}
+
+// GetByPermissions mocks base method.
+func (m *MockGroups) GetByPermissions(ctx context.Context, permissions domain.Permissions) ([]domain.Group, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByPermissions", ctx, permissions)
+	ret0, _ := ret[0].([]domain.Group)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByPermissions indicates an expected call of GetByPermissions.
+func (mr *MockGroupsMockRecorder) GetByPermissions(ctx, permissions interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByPermissions", reflect.TypeOf((*MockGroups)(nil).GetByPermissions), ctx, permissions)
+}
+
+// GetUserGroups mocks base method.
+func (m *MockGroups) GetUserGroups(ctx context.Context, userID domain.ID) ([]domain.Membership, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserGroups", ctx, userID)
+	ret0, _ := ret[0].([]domain.Membership)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserGroups indicates an expected call of GetUserGroups.
+func (mr *MockGroupsMockRecorder) GetUserGroups(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserGroups", reflect.TypeOf((*MockGroups)(nil).GetUserGroups), ctx, userID)
+}
+
+// RemoveUser mocks base method.
+func (m *MockGroups) RemoveUser(ctx context.Context, groupID, userID, cityID domain.ID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveUser", ctx, groupID, userID, cityID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveUser indicates an expected call of RemoveUser.
+func (mr *MockGroupsMockRecorder) RemoveUser(ctx, groupID, userID, cityID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveUser", reflect.TypeOf((*MockGroups)(nil).RemoveUser), ctx, groupID, userID, cityID)
+}
+
+// Update mocks base method.
+func (m *MockGroups) Update(ctx context.Context, group domain.Group) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, group)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockGroupsMockRecorder) Update(ctx, group interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockGroups)(nil).Update), ctx, group)
+}
+
+// MockSessions is a mock of Sessions interface.
+type MockSessions struct {
+	ctrl     *gomock.Controller
+	recorder *MockSessionsMockRecorder
+}
+
+// MockSessionsMockRecorder is the mock recorder for MockSessions.
+type MockSessionsMockRecorder struct {
+	mock *MockSessions
+}
+
+// NewMockSessions creates a new mock instance.
+func NewMockSessions(ctrl *gomock.Controller) *MockSessions {
+	mock := &MockSessions{ctrl: ctrl}
+	mock.recorder = &MockSessionsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSessions) EXPECT() *MockSessionsMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockSessions) Create(ctx context.Context, session domain.Session) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, session)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockSessionsMockRecorder) Create(ctx, session interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockSessions)(nil).Create), ctx, session)
+}
+
+// Delete mocks base method.
+func (m *MockSessions) Delete(ctx context.Context, refreshToken string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, refreshToken)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockSessionsMockRecorder) Delete(ctx, refreshToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockSessions)(nil).Delete), ctx, refreshToken)
+}
+
+// DeleteByUserID mocks base method.
+func (m *MockSessions) DeleteByUserID(ctx context.Context, userID domain.ID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByUserID", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteByUserID indicates an expected call of DeleteByUserID.
+func (mr *MockSessionsMockRecorder) DeleteByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByUserID", reflect.TypeOf((*MockSessions)(nil).DeleteByUserID), ctx, userID)
+}
+
+// DeleteFamily mocks base method.
+func (m *MockSessions) DeleteFamily(ctx context.Context, userID domain.ID, familyID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFamily", ctx, userID, familyID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteFamily indicates an expected call of DeleteFamily.
+func (mr *MockSessionsMockRecorder) DeleteFamily(ctx, userID, familyID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFamily", reflect.TypeOf((*MockSessions)(nil).DeleteFamily), ctx, userID, familyID)
+}
+
+// GetByFamily mocks base method.
+func (m *MockSessions) GetByFamily(ctx context.Context, familyID string) ([]domain.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByFamily", ctx, familyID)
+	ret0, _ := ret[0].([]domain.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByFamily indicates an expected call of GetByFamily.
+func (mr *MockSessionsMockRecorder) GetByFamily(ctx, familyID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByFamily", reflect.TypeOf((*MockSessions)(nil).GetByFamily), ctx, familyID)
+}
+
+// GetByRefreshToken mocks base method.
+func (m *MockSessions) GetByRefreshToken(ctx context.Context, refreshToken string) (domain.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByRefreshToken", ctx, refreshToken)
+	ret0, _ := ret[0].(domain.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByRefreshToken indicates an expected call of GetByRefreshToken.
+func (mr *MockSessionsMockRecorder) GetByRefreshToken(ctx, refreshToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByRefreshToken", reflect.TypeOf((*MockSessions)(nil).GetByRefreshToken), ctx, refreshToken)
+}
+
+// GetByUserID mocks base method.
+func (m *MockSessions) GetByUserID(ctx context.Context, userID domain.ID) ([]domain.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByUserID", ctx, userID)
+	ret0, _ := ret[0].([]domain.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByUserID indicates an expected call of GetByUserID.
+func (mr *MockSessionsMockRecorder) GetByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByUserID", reflect.TypeOf((*MockSessions)(nil).GetByUserID), ctx, userID)
+}
+
+// Rotate mocks base method.
+func (m *MockSessions) Rotate(ctx context.Context, refreshToken string, next domain.Session) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rotate", ctx, refreshToken, next)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Rotate indicates an expected call of Rotate.
+func (mr *MockSessionsMockRecorder) Rotate(ctx, refreshToken, next interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rotate", reflect.TypeOf((*MockSessions)(nil).Rotate), ctx, refreshToken, next)
+}
+
+// MockCredentials is a mock of Credentials interface.
+type MockCredentials struct {
+	ctrl     *gomock.Controller
+	recorder *MockCredentialsMockRecorder
+}
+
+// MockCredentialsMockRecorder is the mock recorder for MockCredentials.
+type MockCredentialsMockRecorder struct {
+	mock *MockCredentials
+}
+
+// NewMockCredentials creates a new mock instance.
+func NewMockCredentials(ctrl *gomock.Controller) *MockCredentials {
+	mock := &MockCredentials{ctrl: ctrl}
+	mock.recorder = &MockCredentialsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCredentials) EXPECT() *MockCredentialsMockRecorder {
+	return m.recorder
+}
+
+// GetByUserID mocks base method.
+func (m *MockCredentials) GetByUserID(ctx context.Context, userID domain.ID) (domain.Credentials, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByUserID", ctx, userID)
+	ret0, _ := ret[0].(domain.Credentials)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByUserID indicates an expected call of GetByUserID.
+func (mr *MockCredentialsMockRecorder) GetByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByUserID", reflect.TypeOf((*MockCredentials)(nil).GetByUserID), ctx, userID)
+}
+
+// Set mocks base method.
+func (m *MockCredentials) Set(ctx context.Context, userID domain.ID, passwordHash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Set", ctx, userID, passwordHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Set indicates an expected call of Set.
+func (mr *MockCredentialsMockRecorder) Set(ctx, userID, passwordHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockCredentials)(nil).Set), ctx, userID, passwordHash)
+}
+
+// MockUserTokens is a mock of UserTokens interface.
+type MockUserTokens struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserTokensMockRecorder
+}
+
+// MockUserTokensMockRecorder is the mock recorder for MockUserTokens.
+type MockUserTokensMockRecorder struct {
+	mock *MockUserTokens
+}
+
+// NewMockUserTokens creates a new mock instance.
+func NewMockUserTokens(ctrl *gomock.Controller) *MockUserTokens {
+	mock := &MockUserTokens{ctrl: ctrl}
+	mock.recorder = &MockUserTokensMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUserTokens) EXPECT() *MockUserTokensMockRecorder {
+	return m.recorder
+}
+
+// Consume mocks base method.
+func (m *MockUserTokens) Consume(ctx context.Context, purpose domain.TokenPurpose, token string) (domain.UserToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Consume", ctx, purpose, token)
+	ret0, _ := ret[0].(domain.UserToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Consume indicates an expected call of Consume.
+func (mr *MockUserTokensMockRecorder) Consume(ctx, purpose, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Consume", reflect.TypeOf((*MockUserTokens)(nil).Consume), ctx, purpose, token)
+}
+
+// Create mocks base method.
+func (m *MockUserTokens) Create(ctx context.Context, token domain.UserToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockUserTokensMockRecorder) Create(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockUserTokens)(nil).Create), ctx, token)
+}
+
+// DeleteByUserID mocks base method.
+func (m *MockUserTokens) DeleteByUserID(ctx context.Context, userID domain.ID, purpose domain.TokenPurpose) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByUserID", ctx, userID, purpose)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteByUserID indicates an expected call of DeleteByUserID.
+func (mr *MockUserTokensMockRecorder) DeleteByUserID(ctx, userID, purpose interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByUserID", reflect.TypeOf((*MockUserTokens)(nil).DeleteByUserID), ctx, userID, purpose)
+}
+
+// MockTwoFactor is a mock of TwoFactor interface.
+type MockTwoFactor struct {
+	ctrl     *gomock.Controller
+	recorder *MockTwoFactorMockRecorder
+}
+
+// MockTwoFactorMockRecorder is the mock recorder for MockTwoFactor.
+type MockTwoFactorMockRecorder struct {
+	mock *MockTwoFactor
+}
+
+// NewMockTwoFactor creates a new mock instance.
+func NewMockTwoFactor(ctrl *gomock.Controller) *MockTwoFactor {
+	mock := &MockTwoFactor{ctrl: ctrl}
+	mock.recorder = &MockTwoFactorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTwoFactor) EXPECT() *MockTwoFactorMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockTwoFactor) Delete(ctx context.Context, userID domain.ID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockTwoFactorMockRecorder) Delete(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockTwoFactor)(nil).Delete), ctx, userID)
+}
+
+// Enable mocks base method.
+func (m *MockTwoFactor) Enable(ctx context.Context, userID domain.ID, step int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enable", ctx, userID, step)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Enable indicates an expected call of Enable.
+func (mr *MockTwoFactorMockRecorder) Enable(ctx, userID, step interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enable", reflect.TypeOf((*MockTwoFactor)(nil).Enable), ctx, userID, step)
+}
+
+// GetByUserID mocks base method.
+func (m *MockTwoFactor) GetByUserID(ctx context.Context, userID domain.ID) (domain.TwoFactor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByUserID", ctx, userID)
+	ret0, _ := ret[0].(domain.TwoFactor)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByUserID indicates an expected call of GetByUserID.
+func (mr *MockTwoFactorMockRecorder) GetByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByUserID", reflect.TypeOf((*MockTwoFactor)(nil).GetByUserID), ctx, userID)
+}
+
+// Set mocks base method.
+func (m *MockTwoFactor) Set(ctx context.Context, userID domain.ID, secret string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Set", ctx, userID, secret)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Set indicates an expected call of Set.
+func (mr *MockTwoFactorMockRecorder) Set(ctx, userID, secret interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockTwoFactor)(nil).Set), ctx, userID, secret)
+}
+
+// SetRecoveryCodes mocks base method.
+func (m *MockTwoFactor) SetRecoveryCodes(ctx context.Context, userID domain.ID, codes []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetRecoveryCodes", ctx, userID, codes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetRecoveryCodes indicates an expected call of SetRecoveryCodes.
+func (mr *MockTwoFactorMockRecorder) SetRecoveryCodes(ctx, userID, codes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRecoveryCodes", reflect.TypeOf((*MockTwoFactor)(nil).SetRecoveryCodes), ctx, userID, codes)
+}
+
+// UseRecoveryCode mocks base method.
+func (m *MockTwoFactor) UseRecoveryCode(ctx context.Context, userID domain.ID, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRecoveryCode", ctx, userID, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode.
+func (mr *MockTwoFactorMockRecorder) UseRecoveryCode(ctx, userID, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockTwoFactor)(nil).UseRecoveryCode), ctx, userID, code)
+}
+
+// UseStep mocks base method.
+func (m *MockTwoFactor) UseStep(ctx context.Context, userID domain.ID, step int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseStep", ctx, userID, step)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UseStep indicates an expected call of UseStep.
+func (mr *MockTwoFactorMockRecorder) UseStep(ctx, userID, step interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseStep", reflect.TypeOf((*MockTwoFactor)(nil).UseStep), ctx, userID, step)
+}
+
+// MockLoginAttempts is a mock of LoginAttempts interface.
+type MockLoginAttempts struct {
+	ctrl     *gomock.Controller
+	recorder *MockLoginAttemptsMockRecorder
+}
+
+// MockLoginAttemptsMockRecorder is the mock recorder for MockLoginAttempts.
+type MockLoginAttemptsMockRecorder struct {
+	mock *MockLoginAttempts
+}
+
+// NewMockLoginAttempts creates a new mock instance.
+func NewMockLoginAttempts(ctrl *gomock.Controller) *MockLoginAttempts {
+	mock := &MockLoginAttempts{ctrl: ctrl}
+	mock.recorder = &MockLoginAttemptsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLoginAttempts) EXPECT() *MockLoginAttemptsMockRecorder {
+	return m.recorder
+}
+
+// AddFailure mocks base method.
+func (m *MockLoginAttempts) AddFailure(ctx context.Context, subject domain.LoginSubject, key, ip string, window time.Duration) (domain.LoginAttempts, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddFailure", ctx, subject, key, ip, window)
+	ret0, _ := ret[0].(domain.LoginAttempts)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddFailure indicates an expected call of AddFailure.
+func (mr *MockLoginAttemptsMockRecorder) AddFailure(ctx, subject, key, ip, window interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddFailure", reflect.TypeOf((*MockLoginAttempts)(nil).AddFailure), ctx, subject, key, ip, window)
+}
+
+// Get mocks base method.
+func (m *MockLoginAttempts) Get(ctx context.Context, subject domain.LoginSubject, key string) (domain.LoginAttempts, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, subject, key)
+	ret0, _ := ret[0].(domain.LoginAttempts)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockLoginAttemptsMockRecorder) Get(ctx, subject, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockLoginAttempts)(nil).Get), ctx, subject, key)
+}
+
+// Lock mocks base method.
+func (m *MockLoginAttempts) Lock(ctx context.Context, subject domain.LoginSubject, key string, until time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Lock", ctx, subject, key, until)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Lock indicates an expected call of Lock.
+func (mr *MockLoginAttemptsMockRecorder) Lock(ctx, subject, key, until interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Lock", reflect.TypeOf((*MockLoginAttempts)(nil).Lock), ctx, subject, key, until)
+}
+
+// Reset mocks base method.
+func (m *MockLoginAttempts) Reset(ctx context.Context, subject domain.LoginSubject, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reset", ctx, subject, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reset indicates an expected call of Reset.
+func (mr *MockLoginAttemptsMockRecorder) Reset(ctx, subject, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reset", reflect.TypeOf((*MockLoginAttempts)(nil).Reset), ctx, subject, key)
+}
+
+// MockCities is a mock of Cities interface.
+type MockCities struct {
+	ctrl     *gomock.Controller
+	recorder *MockCitiesMockRecorder
+}
+
+// MockCitiesMockRecorder is the mock recorder for MockCities.
+type MockCitiesMockRecorder struct {
+	mock *MockCities
+}
+
+// NewMockCities creates a new mock instance.
+func NewMockCities(ctrl *gomock.Controller) *MockCities {
+	mock := &MockCities{ctrl: ctrl}
+	mock.recorder = &MockCitiesMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCities) EXPECT() *MockCitiesMockRecorder {
 	return m.recorder
 }
 
 // Create mocks base method.
-func (m *MockGroups) Create(ctx context.Context, group *domain.Group) error {
+func (m *MockCities) Create(ctx context.Context, city *domain.City) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", ctx, group)
+	ret := m.ctrl.Call(m, "Create", ctx, city)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockGroupsMockRecorder) Create(ctx, group interface{}) *gomock.Call {
+func (mr *MockCitiesMockRecorder) Create(ctx, city interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockGroups)(nil).Create), ctx, group)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockCities)(nil).Create), ctx, city)
 }
 
 // Delete mocks base method.
-func (m *MockGroups) Delete(ctx context.Context, id domain.ID) error {
+func (m *MockCities) Delete(ctx context.Context, id domain.ID, version int64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret := m.ctrl.Call(m, "Delete", ctx, id, version)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockGroupsMockRecorder) Delete(ctx, id interface{}) *gomock.Call {
+func (mr *MockCitiesMockRecorder) Delete(ctx, id, version interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockGroups)(nil).Delete), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockCities)(nil).Delete), ctx, id, version)
 }
 
 // GetAll mocks base method.
-func (m *MockGroups) GetAll(ctx context.Context) ([]domain.Group, error) {
+func (m *MockCities) GetAll(ctx context.Context, opts domain.ListOptions) ([]domain.CitySummary, string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAll", ctx)
-	ret0, _ := ret[0].([]domain.Group)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "GetAll", ctx, opts)
+	ret0, _ := ret[0].([]domain.CitySummary)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
 }
 
 // GetAll indicates an expected call of GetAll.
-func (mr *MockGroupsMockRecorder) GetAll(ctx interface{}) *gomock.Call {
+func (mr *MockCitiesMockRecorder) GetAll(ctx, opts interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockGroups)(nil).GetAll), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockCities)(nil).GetAll), ctx, opts)
 }
 
 // GetByID mocks base method.
-func (m *MockGroups) GetByID(ctx context.Context, id domain.ID) (domain.Group, error) {
+func (m *MockCities) GetByID(ctx context.Context, id domain.ID) (domain.City, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetByID", ctx, id)
-	ret0, _ := ret[0].(domain.Group)
+	ret0, _ := ret[0].(domain.City)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetByID indicates an expected call of GetByID.
-func (mr *MockGroupsMockRecorder) GetByID(ctx, id interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockGroups)(nil).GetByID), ctx, id)
-}
-
-// GetByName mocks base method.
-func (m *MockGroups) GetByName(ctx context.Context, name string) ([]domain.Group, error) {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetByName", ctx, name)
-	ret0, _ := ret[0].([]domain.Group)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
-}
-
-// GetByName indicates an expected call of GetByName.
-func (mr *MockGroupsMockRecorder) GetByName(ctx, name interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByName", reflect.TypeOf((*MockGroups)(nil).GetByName), ctx, name)
-}
-
-// GetByPermissions mocks base method.
-func (m *MockGroups) GetByPermissions(ctx context.Context, permission domain.Permission) ([]domain.Group, error) {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetByPermissions", ctx, permission)
-	ret0, _ := ret[0].([]domain.Group)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
-}
-
-// GetByPermissions indicates an expected call of GetByPermissions.
-func (mr *MockGroupsMockRecorder) GetByPermissions(ctx, permission interface{}) *gomock.Call {
+func (mr *MockCitiesMockRecorder) GetByID(ctx, id interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByPermissions", reflect.TypeOf((*MockGroups)(nil).GetByPermissions), ctx, permission)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockCities)(nil).GetByID), ctx, id)
 }
 
 // Update mocks base method.
-func (m *MockGroups) Update(ctx context.Context, group domain.Group) error {
+func (m *MockCities) Update(ctx context.Context, city domain.City) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Update", ctx, group)
+	ret := m.ctrl.Call(m, "Update", ctx, city)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Update indicates an expected call of Update.
-func (mr *MockGroupsMockRecorder) Update(ctx, group interface{}) *gomock.Call {
+func (mr *MockCitiesMockRecorder) Update(ctx, city interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockGroups)(nil).Update), ctx, group)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockCities)(nil).Update), ctx, city)
 }
 
-// MockSessions is a mock of Sessions interface.
-type MockSessions struct {
+// MockRegions is a mock of Regions interface.
+type MockRegions struct {
 	ctrl     *gomock.Controller
-	recorder *MockSessionsMockRecorder
+	recorder *MockRegionsMockRecorder
 }
 
-// MockSessionsMockRecorder is the mock recorder for MockSessions.
-type MockSessionsMockRecorder struct {
-	mock *MockSessions
+// MockRegionsMockRecorder is the mock recorder for MockRegions.
+type MockRegionsMockRecorder struct {
+	mock *MockRegions
 }
 
-// NewMockSessions creates a new mock instance.
-func NewMockSessions(ctrl *gomock.Controller) *MockSessions {
-	mock := &MockSessions{ctrl: ctrl}
-	mock.recorder = &MockSessionsMockRecorder{mock}
+// NewMockRegions creates a new mock instance.
+func NewMockRegions(ctrl *gomock.Controller) *MockRegions {
+	mock := &MockRegions{ctrl: ctrl}
+	mock.recorder = &MockRegionsMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockSessions) EXPECT() *MockSessionsMockRecorder {
+func (m *MockRegions) EXPECT() *MockRegionsMockRecorder {
 	return m.recorder
 }
 
 // Create mocks base method.
-func (m *MockSessions) Create(ctx context.Context, session domain.Session) error {
+func (m *MockRegions) Create(ctx context.Context, region *domain.Region) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", ctx, session)
+	ret := m.ctrl.Call(m, "Create", ctx, region)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockSessionsMockRecorder) Create(ctx, session interface{}) *gomock.Call {
+func (mr *MockRegionsMockRecorder) Create(ctx, region interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockSessions)(nil).Create), ctx, session)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRegions)(nil).Create), ctx, region)
 }
 
-// GetByRefreshToken mocks base method.
-func (m *MockSessions) GetByRefreshToken(ctx context.Context, refreshToken string) (domain.Session, error) {
+// Delete mocks base method.
+func (m *MockRegions) Delete(ctx context.Context, id domain.ID, version int64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetByRefreshToken", ctx, refreshToken)
-	ret0, _ := ret[0].(domain.Session)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "Delete", ctx, id, version)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// GetByRefreshToken indicates an expected call of GetByRefreshToken.
-func (mr *MockSessionsMockRecorder) GetByRefreshToken(ctx, refreshToken interface{}) *gomock.Call {
+// Delete indicates an expected call of Delete.
+func (mr *MockRegionsMockRecorder) Delete(ctx, id, version interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByRefreshToken", reflect.TypeOf((*MockSessions)(nil).GetByRefreshToken), ctx, refreshToken)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockRegions)(nil).Delete), ctx, id, version)
 }
 
-// GetByUserID mocks base method.
-func (m *MockSessions) GetByUserID(ctx context.Context, userID domain.ID) ([]domain.Session, error) {
+// GetAll mocks base method.
+func (m *MockRegions) GetAll(ctx context.Context, opts domain.ListOptions) ([]domain.Region, string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetByUserID", ctx, userID)
-	ret0, _ := ret[0].([]domain.Session)
+	ret := m.ctrl.Call(m, "GetAll", ctx, opts)
+	ret0, _ := ret[0].([]domain.Region)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetAll indicates an expected call of GetAll.
+func (mr *MockRegionsMockRecorder) GetAll(ctx, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockRegions)(nil).GetAll), ctx, opts)
+}
+
+// GetByID mocks base method.
+func (m *MockRegions) GetByID(ctx context.Context, id domain.ID) (domain.Region, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, id)
+	ret0, _ := ret[0].(domain.Region)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetByUserID indicates an expected call of GetByUserID.
-func (mr *MockSessionsMockRecorder) GetByUserID(ctx, userID interface{}) *gomock.Call {
+// GetByID indicates an expected call of GetByID.
+func (mr *MockRegionsMockRecorder) GetByID(ctx, id interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByUserID", reflect.TypeOf((*MockSessions)(nil).GetByUserID), ctx, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockRegions)(nil).GetByID), ctx, id)
+}
+
+// Update mocks base method.
+func (m *MockRegions) Update(ctx context.Context, region domain.Region) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, region)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockRegionsMockRecorder) Update(ctx, region interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockRegions)(nil).Update), ctx, region)
 }
 
 // MockDonorCompanies is a mock of DonorCompanies interface.
@@ -352,36 +1107,37 @@ func (mr *MockDonorCompaniesMockRecorder) Create(ctx, company interface{}) *gomo
 }
 
 // Delete mocks base method.
-func (m *MockDonorCompanies) Delete(ctx context.Context, id domain.ID) error {
+func (m *MockDonorCompanies) Delete(ctx context.Context, id domain.ID, version int64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret := m.ctrl.Call(m, "Delete", ctx, id, version)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockDonorCompaniesMockRecorder) Delete(ctx, id interface{}) *gomock.Call {
+func (mr *MockDonorCompaniesMockRecorder) Delete(ctx, id, version interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockDonorCompanies)(nil).Delete), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockDonorCompanies)(nil).Delete), ctx, id, version)
 }
 
 // GetAll mocks base method.
-func (m *MockDonorCompanies) GetAll(ctx context.Context) ([]domain.DonorCompany, error) {
+func (m *MockDonorCompanies) GetAll(ctx context.Context, opts domain.ListOptions) ([]domain.DonorCompany, string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAll", ctx)
+	ret := m.ctrl.Call(m, "GetAll", ctx, opts)
 	ret0, _ := ret[0].([]domain.DonorCompany)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
 }
 
 // GetAll indicates an expected call of GetAll.
-func (mr *MockDonorCompaniesMockRecorder) GetAll(ctx interface{}) *gomock.Call {
+func (mr *MockDonorCompaniesMockRecorder) GetAll(ctx, opts interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockDonorCompanies)(nil).GetAll), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockDonorCompanies)(nil).GetAll), ctx, opts)
 }
 
 // GetByCity mocks base method.
-func (m *MockDonorCompanies) GetByCity(ctx context.Context, cityID string) ([]domain.DonorCompany, error) {
+func (m *MockDonorCompanies) GetByCity(ctx context.Context, cityID domain.ID) ([]domain.DonorCompany, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetByCity", ctx, cityID)
 	ret0, _ := ret[0].([]domain.DonorCompany)
@@ -410,6 +1166,35 @@ func (mr *MockDonorCompaniesMockRecorder) GetByID(ctx, id interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockDonorCompanies)(nil).GetByID), ctx, id)
 }
 
+// Purge mocks base method.
+func (m *MockDonorCompanies) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", ctx, deletedBefore)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockDonorCompaniesMockRecorder) Purge(ctx, deletedBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockDonorCompanies)(nil).Purge), ctx, deletedBefore)
+}
+
+// Restore mocks base method.
+func (m *MockDonorCompanies) Restore(ctx context.Context, id domain.ID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockDonorCompaniesMockRecorder) Restore(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockDonorCompanies)(nil).Restore), ctx, id)
+}
+
 // Update mocks base method.
 func (m *MockDonorCompanies) Update(ctx context.Context, company domain.DonorCompany) error {
 	m.ctrl.T.Helper()
@@ -447,6 +1232,20 @@ func (m *MockActs) EXPECT() *MockActsMockRecorder {
 	return m.recorder
 }
 
+// AddFile mocks base method.
+func (m *MockActs) AddFile(ctx context.Context, fileID, actID domain.ID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddFile", ctx, fileID, actID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddFile indicates an expected call of AddFile.
+func (mr *MockActsMockRecorder) AddFile(ctx, fileID, actID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddFile", reflect.TypeOf((*MockActs)(nil).AddFile), ctx, fileID, actID)
+}
+
 // Create mocks base method.
 func (m *MockActs) Create(ctx context.Context, act *domain.Act) error {
 	m.ctrl.T.Helper()
@@ -462,32 +1261,63 @@ func (mr *MockActsMockRecorder) Create(ctx, act interface{}) *gomock.Call {
 }
 
 // Delete mocks base method.
-func (m *MockActs) Delete(ctx context.Context, id domain.ID) error {
+func (m *MockActs) Delete(ctx context.Context, id domain.ID, version int64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret := m.ctrl.Call(m, "Delete", ctx, id, version)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockActsMockRecorder) Delete(ctx, id interface{}) *gomock.Call {
+func (mr *MockActsMockRecorder) Delete(ctx, id, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockActs)(nil).Delete), ctx, id, version)
+}
+
+// GetActFiles mocks base method.
+func (m *MockActs) GetActFiles(ctx context.Context, actID domain.ID) ([]domain.File, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActFiles", ctx, actID)
+	ret0, _ := ret[0].([]domain.File)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActFiles indicates an expected call of GetActFiles.
+func (mr *MockActsMockRecorder) GetActFiles(ctx, actID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockActs)(nil).Delete), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActFiles", reflect.TypeOf((*MockActs)(nil).GetActFiles), ctx, actID)
 }
 
 // GetAll mocks base method.
-func (m *MockActs) GetAll(ctx context.Context) ([]domain.Act, error) {
+func (m *MockActs) GetAll(ctx context.Context, opts domain.ListOptions) ([]domain.Act, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll", ctx, opts)
+	ret0, _ := ret[0].([]domain.Act)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetAll indicates an expected call of GetAll.
+func (mr *MockActsMockRecorder) GetAll(ctx, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockActs)(nil).GetAll), ctx, opts)
+}
+
+// GetByCity mocks base method.
+func (m *MockActs) GetByCity(ctx context.Context, cityID domain.ID) ([]domain.Act, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAll", ctx)
+	ret := m.ctrl.Call(m, "GetByCity", ctx, cityID)
 	ret0, _ := ret[0].([]domain.Act)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetAll indicates an expected call of GetAll.
-func (mr *MockActsMockRecorder) GetAll(ctx interface{}) *gomock.Call {
+// GetByCity indicates an expected call of GetByCity.
+func (mr *MockActsMockRecorder) GetByCity(ctx, cityID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockActs)(nil).GetAll), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByCity", reflect.TypeOf((*MockActs)(nil).GetByCity), ctx, cityID)
 }
 
 // GetByDonorCompanyID mocks base method.
@@ -535,6 +1365,64 @@ func (mr *MockActsMockRecorder) GetByUserID(ctx, userID interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByUserID", reflect.TypeOf((*MockActs)(nil).GetByUserID), ctx, userID)
 }
 
+// GetFileActs mocks base method.
+func (m *MockActs) GetFileActs(ctx context.Context, fileID domain.ID) ([]domain.Act, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFileActs", ctx, fileID)
+	ret0, _ := ret[0].([]domain.Act)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFileActs indicates an expected call of GetFileActs.
+func (mr *MockActsMockRecorder) GetFileActs(ctx, fileID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFileActs", reflect.TypeOf((*MockActs)(nil).GetFileActs), ctx, fileID)
+}
+
+// Purge mocks base method.
+func (m *MockActs) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", ctx, deletedBefore)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockActsMockRecorder) Purge(ctx, deletedBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockActs)(nil).Purge), ctx, deletedBefore)
+}
+
+// RemoveFile mocks base method.
+func (m *MockActs) RemoveFile(ctx context.Context, fileID, actID domain.ID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveFile", ctx, fileID, actID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveFile indicates an expected call of RemoveFile.
+func (mr *MockActsMockRecorder) RemoveFile(ctx, fileID, actID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveFile", reflect.TypeOf((*MockActs)(nil).RemoveFile), ctx, fileID, actID)
+}
+
+// Restore mocks base method.
+func (m *MockActs) Restore(ctx context.Context, id domain.ID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockActsMockRecorder) Restore(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockActs)(nil).Restore), ctx, id)
+}
+
 // Update mocks base method.
 func (m *MockActs) Update(ctx context.Context, act domain.Act) error {
 	m.ctrl.T.Helper()
@@ -592,22 +1480,17 @@ func (mr *MockActContentsMockRecorder) Create(ctx interface{}, contents ...inter
 }
 
 // Delete mocks base method.
-func (m *MockActContents) Delete(ctx context.Context, contentIDs ...domain.ID) error {
+func (m *MockActContents) Delete(ctx context.Context, id domain.ID, version int64) error {
 	m.ctrl.T.Helper()
-	varargs := []interface{}{ctx}
-	for _, a := range contentIDs {
-		varargs = append(varargs, a)
-	}
-	ret := m.ctrl.Call(m, "Delete", varargs...)
+	ret := m.ctrl.Call(m, "Delete", ctx, id, version)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockActContentsMockRecorder) Delete(ctx interface{}, contentIDs ...interface{}) *gomock.Call {
+func (mr *MockActContentsMockRecorder) Delete(ctx, id, version interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	varargs := append([]interface{}{ctx}, contentIDs...)
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockActContents)(nil).Delete), varargs...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockActContents)(nil).Delete), ctx, id, version)
 }
 
 // GetByActID mocks base method.
@@ -658,3 +1541,137 @@ func (mr *MockActContentsMockRecorder) Update(ctx interface{}, contents ...inter
 	varargs := append([]interface{}{ctx}, contents...)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockActContents)(nil).Update), varargs...)
 }
+
+// MockFiles is a mock of Files interface.
+type MockFiles struct {
+	ctrl     *gomock.Controller
+	recorder *MockFilesMockRecorder
+}
+
+// MockFilesMockRecorder is the mock recorder for MockFiles.
+type MockFilesMockRecorder struct {
+	mock *MockFiles
+}
+
+// NewMockFiles creates a new mock instance.
+func NewMockFiles(ctrl *gomock.Controller) *MockFiles {
+	mock := &MockFiles{ctrl: ctrl}
+	mock.recorder = &MockFilesMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFiles) EXPECT() *MockFilesMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockFiles) Create(ctx context.Context, file *domain.File) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, file)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockFilesMockRecorder) Create(ctx, file interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockFiles)(nil).Create), ctx, file)
+}
+
+// GetByID mocks base method.
+func (m *MockFiles) GetByID(ctx context.Context, fileID domain.ID) (domain.File, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, fileID)
+	ret0, _ := ret[0].(domain.File)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockFilesMockRecorder) GetByID(ctx, fileID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockFiles)(nil).GetByID), ctx, fileID)
+}
+
+// GetForUploading mocks base method.
+func (m *MockFiles) GetForUploading(ctx context.Context) (domain.File, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetForUploading", ctx)
+	ret0, _ := ret[0].(domain.File)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetForUploading indicates an expected call of GetForUploading.
+func (mr *MockFilesMockRecorder) GetForUploading(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetForUploading", reflect.TypeOf((*MockFiles)(nil).GetForUploading), ctx)
+}
+
+// UpdateStatus mocks base method.
+func (m *MockFiles) UpdateStatus(ctx context.Context, fileID domain.ID, status domain.FileStatus) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStatus", ctx, fileID, status)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateStatus indicates an expected call of UpdateStatus.
+func (mr *MockFilesMockRecorder) UpdateStatus(ctx, fileID, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatus", reflect.TypeOf((*MockFiles)(nil).UpdateStatus), ctx, fileID, status)
+}
+
+// UpdateStatusAndSetURL mocks base method.
+func (m *MockFiles) UpdateStatusAndSetURL(ctx context.Context, fileID domain.ID, url string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStatusAndSetURL", ctx, fileID, url)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateStatusAndSetURL indicates an expected call of UpdateStatusAndSetURL.
+func (mr *MockFilesMockRecorder) UpdateStatusAndSetURL(ctx, fileID, url interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatusAndSetURL", reflect.TypeOf((*MockFiles)(nil).UpdateStatusAndSetURL), ctx, fileID, url)
+}
+
+// MockAuditLog is a mock of AuditLog interface.
+type MockAuditLog struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditLogMockRecorder
+}
+
+// MockAuditLogMockRecorder is the mock recorder for MockAuditLog.
+type MockAuditLogMockRecorder struct {
+	mock *MockAuditLog
+}
+
+// NewMockAuditLog creates a new mock instance.
+func NewMockAuditLog(ctrl *gomock.Controller) *MockAuditLog {
+	mock := &MockAuditLog{ctrl: ctrl}
+	mock.recorder = &MockAuditLogMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditLog) EXPECT() *MockAuditLogMockRecorder {
+	return m.recorder
+}
+
+// GetAll mocks base method.
+func (m *MockAuditLog) GetAll(ctx context.Context, opts domain.ListOptions) ([]domain.AuditEntry, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll", ctx, opts)
+	ret0, _ := ret[0].([]domain.AuditEntry)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetAll indicates an expected call of GetAll.
+func (mr *MockAuditLogMockRecorder) GetAll(ctx, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockAuditLog)(nil).GetAll), ctx, opts)
+}
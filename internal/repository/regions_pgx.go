@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"foodsharing-backend/internal/domain"
+
+	"github.com/jackc/pgx/v4"
+)
+
+type postgresRegionsRepo struct {
+	db DB
+}
+
+func NewRegionsRepository(db DB) Regions {
+	return &postgresRegionsRepo{db: db}
+}
+
+const createRegionQuery = `INSERT INTO regions(name, created_at) VALUES ($1, $2) ON CONFLICT DO NOTHING RETURNING id`
+
+func (p *postgresRegionsRepo) Create(ctx context.Context, region *domain.Region) error {
+	var id domain.ID
+	createdAt := time.Now()
+
+	row := p.db.QueryRow(ctx, createRegionQuery, region.Name, createdAt)
+	if err := row.Scan(&id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("%w: region %q", domain.AlreadyExists, region.Name)
+		}
+		return fmt.Errorf("cannot create region: %w", err)
+	}
+
+	region.ID = id
+	region.Version = 1
+	region.CreatedAt = createdAt
+	return nil
+}
+
+const updateRegionQuery = `UPDATE regions SET name = $1, updated_at = now(), version = version + 1 
+		WHERE id = $2 AND version = $3`
+
+func (p *postgresRegionsRepo) Update(ctx context.Context, region domain.Region) error {
+	tag, err := p.db.Exec(ctx, updateRegionQuery, region.Name, region.ID, region.Version)
+	if err != nil {
+		return fmt.Errorf("cannot update region: %w", err)
+	}
+	if tag.RowsAffected() != 1 {
+		return versionMismatch(ctx, p.db, "regions", region.ID)
+	}
+	return nil
+}
+
+const deleteRegionQuery = `DELETE FROM regions r WHERE id = $1 AND version = $2 
+		AND NOT EXISTS (SELECT 1 FROM cities WHERE region_id = r.id)`
+
+func (p *postgresRegionsRepo) Delete(ctx context.Context, id domain.ID, version int64) error {
+	tag, err := p.db.Exec(ctx, deleteRegionQuery, id, version)
+	if err != nil {
+		return fmt.Errorf("cannot delete region: %w", err)
+	}
+	if tag.RowsAffected() == 1 {
+		return nil
+	}
+
+	if err := versionMatches(ctx, p.db, "regions", id, version); err != nil {
+		return err
+	}
+	return fmt.Errorf("%w: the region still has cities", domain.InUse)
+}
+
+const getRegionByIDQuery = `SELECT name, version, created_at, updated_at FROM regions WHERE id = $1`
+
+func (p *postgresRegionsRepo) GetByID(ctx context.Context, id domain.ID) (domain.Region, error) {
+	var region domain.Region
+	row := p.db.QueryRow(ctx, getRegionByIDQuery, id)
+	if err := row.Scan(&region.Name, &region.Version, &region.CreatedAt, &region.UpdatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.Region{}, domain.NotFound
+		}
+		return domain.Region{}, fmt.Errorf("cannot get region by id: %w", err)
+	}
+
+	region.ID = id
+	return region, nil
+}
+
+var regionsList = listSpec{
+	columns: "id, name, version, created_at, updated_at",
+	from:    "regions",
+	id:      "id",
+	created: "created_at",
+	sorts: map[string]sortColumn{
+		"id":         {expr: "id", typ: "bigint"},
+		"created_at": {expr: "created_at", typ: "timestamptz"},
+		"name":       {expr: "name", typ: "text"},
+	},
+	name: "name",
+}
+
+func (p *postgresRegionsRepo) GetAll(ctx context.Context, opts domain.ListOptions) ([]domain.Region, string, error) {
+	q, err := newListQuery(regionsList, opts)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var regions []domain.Region
+	rows, err := p.db.Query(ctx, q.sql(), q.args...)
+	if err != nil {
+		return nil, "", fmt.Errorf("cannot get regions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var region domain.Region
+		var key string
+		if err := rows.Scan(&region.ID, &region.Name, &region.Version, &region.CreatedAt, &region.UpdatedAt,
+			&key); err != nil {
+			return nil, "", fmt.Errorf("cannot scan region: %w", err)
+		}
+		q.add(region.ID, key)
+		regions = append(regions, region)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, "", fmt.Errorf("cannot get regions: %w", err)
+	}
+
+	return regions[:q.size()], q.next(), nil
+}
@@ -2,8 +2,10 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"foodsharing-backend/internal/domain"
+	"foodsharing-backend/pkg/hash"
 
 	"github.com/jackc/pgx/v4/pgxpool"
 )
@@ -11,88 +13,254 @@ import (
 //go:generate mockgen -source=repository.go -destination=mocks/mock.go
 
 type Repositories struct {
+	// TxManager runs functions in a transaction. The one of repositories bound to a
+	// transaction starts savepoints.
+	TxManager TxManager
+
 	Users          Users
 	Groups         Groups
+	Cities         Cities
+	Regions        Regions
 	Sessions       Sessions
+	Credentials    Credentials
+	UserTokens     UserTokens
+	TwoFactor      TwoFactor
+	LoginAttempts  LoginAttempts
 	DonorCompanies DonorCompanies
 	Acts           Acts
 	ActContents    ActContents
 	Files          Files
+	AuditLog       AuditLog
+}
+
+func NewRepositories(pool *pgxpool.Pool, tokenHasher hash.TokenHasher) *Repositories {
+	return newRepositories(pool, tokenHasher, NewTxManager(pool, tokenHasher))
 }
 
-func NewRepositories(pool *pgxpool.Pool) *Repositories {
+func newRepositories(db DB, tokenHasher hash.TokenHasher, txManager TxManager) *Repositories {
 	return &Repositories{
-		Users:          NewUsersRepository(pool),
-		Groups:         NewGroupsRepository(pool),
-		Sessions:       NewSessionsRepository(pool),
-		DonorCompanies: NewDonorCompaniesRepository(pool),
-		Acts:           NewActsRepository(pool),
-		ActContents:    NewActContentsRepository(pool),
-		Files:          NewFilesRepository(pool),
+		TxManager:      txManager,
+		Users:          NewUsersRepository(db),
+		Groups:         NewGroupsRepository(db),
+		Cities:         NewCitiesRepository(db),
+		Regions:        NewRegionsRepository(db),
+		Sessions:       NewSessionsRepository(db, tokenHasher),
+		Credentials:    NewCredentialsRepository(db),
+		UserTokens:     NewUserTokensRepository(db, tokenHasher),
+		TwoFactor:      NewTwoFactorRepository(db, tokenHasher),
+		LoginAttempts:  NewLoginAttemptsRepository(db),
+		DonorCompanies: NewDonorCompaniesRepository(db),
+		Acts:           NewActsRepository(db),
+		ActContents:    NewActContentsRepository(db),
+		Files:          NewFilesRepository(db),
+		AuditLog:       NewAuditLogRepository(db),
 	}
 }
 
+type TxManager interface {
+	// WithinTx calls fn with the repositories bound to a transaction, which is committed if
+	// fn returns nil and rolled back otherwise. On a serialization failure or a deadlock the
+	// transaction is run again, so fn must not have effects outside the database.
+	WithinTx(ctx context.Context, fn func(repos *Repositories) error) error
+}
+
 type Users interface {
+	// Create and Update return AlreadyExists when another user has the email, compared
+	// without regard to case.
 	Create(ctx context.Context, user *domain.User) error
 	Update(ctx context.Context, user domain.User) error
-	Delete(ctx context.Context, id domain.ID) error
+	// Delete marks the user as deleted. Deleted users are left out everywhere but in the
+	// lists of deleted records until they are restored or purged. It returns Conflict if
+	// the user no longer has version, as do the Delete methods of the other records.
+	Delete(ctx context.Context, id domain.ID, version int64) error
+	// Restore returns NotFound unless the user is deleted and AlreadyExists when another
+	// user has taken the email since.
+	Restore(ctx context.Context, id domain.ID) error
+	// Purge removes the users deleted before the time for good, except the ones acts or files
+	// refer to, and returns their number. It runs several statements and belongs in a transaction.
+	Purge(ctx context.Context, deletedBefore time.Time) (int64, error)
+	// VerifyEmail marks the email of the user as verified unless it has changed since.
+	VerifyEmail(ctx context.Context, id domain.ID, email string) error
 
 	GetByID(ctx context.Context, id domain.ID) (domain.User, error)
 	GetByEmail(ctx context.Context, email string) (domain.User, error)
-	GetAll(ctx context.Context) ([]domain.User, error)
+	// GetByCity returns all users of the city, the same as paging through GetAll.
+	GetByCity(ctx context.Context, cityID domain.ID) ([]domain.User, error)
+	// GetAll returns a page of users and the cursor of the next page. Users can be sorted by
+	// id, created_at and surname and filtered by city and creation time.
+	GetAll(ctx context.Context, opts domain.ListOptions) ([]domain.User, string, error)
 }
 
 type Groups interface {
 	Create(ctx context.Context, group *domain.Group) error
 	Update(ctx context.Context, group domain.Group) error
-	Delete(ctx context.Context, id domain.ID) error
+	// Delete returns InUse while the group has members.
+	Delete(ctx context.Context, id domain.ID, version int64) error
 
 	GetByID(ctx context.Context, id domain.ID) (domain.Group, error)
+	// GetByPermissions returns the groups that grant, directly or by inheritance, any of the
+	// permissions.
+	GetByPermissions(ctx context.Context, permissions domain.Permissions) ([]domain.Group, error)
+	// GetByName returns all groups whose name matches the LIKE pattern, see Users.GetByCity.
 	GetByName(ctx context.Context, name string) ([]domain.Group, error)
-	GetByPermissions(ctx context.Context, permission domain.Permission) ([]domain.Group, error)
-	GetAll(ctx context.Context) ([]domain.Group, error)
+	// GetAll returns a page of groups and the cursor of the next page. Groups can be sorted by
+	// id, created_at and name and filtered by name and creation time.
+	GetAll(ctx context.Context, opts domain.ListOptions) ([]domain.Group, string, error)
+	// GetAncestors returns the ID of the group followed by the IDs of its parents up the chain.
+	GetAncestors(ctx context.Context, id domain.ID) ([]domain.ID, error)
 
-	AddUser(ctx context.Context, groupID domain.ID, userID domain.ID) error
-	GetUserGroups(ctx context.Context, userID domain.ID) ([]domain.Group, error)
-	RemoveUser(ctx context.Context, groupID domain.ID, userID domain.ID) error
+	// AddUser puts the user into the group, limited to the city unless CityID is zero. A
+	// temporary membership in the same city is replaced, a permanent one is AlreadyExists.
+	AddUser(ctx context.Context, member domain.Member) error
+	// GetUserGroups returns the memberships of the user that have not expired.
+	GetUserGroups(ctx context.Context, userID domain.ID) ([]domain.Membership, error)
+	// RemoveUser returns NotFound when the user is not a member of the group in the city.
+	RemoveUser(ctx context.Context, groupID domain.ID, userID domain.ID, cityID domain.ID) error
+	// DeleteExpiredMembers removes the memberships that expired by now. The audit log records
+	// each of them without an actor, as it does for all changes made by the system.
+	DeleteExpiredMembers(ctx context.Context, now time.Time) error
 }
 
 type Sessions interface {
 	Create(ctx context.Context, session domain.Session) error
+	// Rotate marks the session of refreshToken as rotated and creates next in the same family,
+	// keeping the creation time of the family. It returns NotFound if the session does not
+	// exist or has already been rotated.
+	Rotate(ctx context.Context, refreshToken string, next domain.Session) error
+	Delete(ctx context.Context, refreshToken string) error
+	DeleteFamily(ctx context.Context, userID domain.ID, familyID string) error
+	DeleteByUserID(ctx context.Context, userID domain.ID) error
 
 	GetByRefreshToken(ctx context.Context, refreshToken string) (domain.Session, error)
 	GetByUserID(ctx context.Context, userID domain.ID) ([]domain.Session, error)
+	GetByFamily(ctx context.Context, familyID string) ([]domain.Session, error)
+}
+
+type Credentials interface {
+	// Set creates or replaces the password hash of the user.
+	Set(ctx context.Context, userID domain.ID, passwordHash string) error
+	GetByUserID(ctx context.Context, userID domain.ID) (domain.Credentials, error)
+}
+
+type UserTokens interface {
+	Create(ctx context.Context, token domain.UserToken) error
+	// Consume marks an unused, unexpired token as used and returns it, so every token
+	// works only once.
+	Consume(ctx context.Context, purpose domain.TokenPurpose, token string) (domain.UserToken, error)
+	DeleteByUserID(ctx context.Context, userID domain.ID, purpose domain.TokenPurpose) error
+}
+
+type TwoFactor interface {
+	// Set starts a new pending enrollment of the user, replacing an unconfirmed one. It returns
+	// AlreadyExists if two-factor authentication is already enabled.
+	Set(ctx context.Context, userID domain.ID, secret string) error
+	// Enable confirms a pending enrollment with the time step of the first accepted code.
+	Enable(ctx context.Context, userID domain.ID, step int64) error
+	// UseStep records the time step of an accepted code. It returns NotFound if a code of
+	// the same or a later step has already been used.
+	UseStep(ctx context.Context, userID domain.ID, step int64) error
+	// Delete disables two-factor authentication together with the recovery codes.
+	Delete(ctx context.Context, userID domain.ID) error
+	GetByUserID(ctx context.Context, userID domain.ID) (domain.TwoFactor, error)
+
+	// SetRecoveryCodes replaces all recovery codes of the user.
+	SetRecoveryCodes(ctx context.Context, userID domain.ID, codes []string) error
+	UseRecoveryCode(ctx context.Context, userID domain.ID, code string) error
+}
+
+type LoginAttempts interface {
+	Get(ctx context.Context, subject domain.LoginSubject, key string) (domain.LoginAttempts, error)
+	// AddFailure counts a failed sign-in from ip, which may be empty, and returns the updated
+	// attempts. Failures older than window are forgotten.
+	AddFailure(ctx context.Context, subject domain.LoginSubject, key string, ip string,
+		window time.Duration) (domain.LoginAttempts, error)
+	Lock(ctx context.Context, subject domain.LoginSubject, key string, until time.Time) error
+	// Reset forgets all failures and lifts the lock.
+	Reset(ctx context.Context, subject domain.LoginSubject, key string) error
+}
+
+type Cities interface {
+	// Create and Update return AlreadyExists when another city has the name, ignoring case.
+	Create(ctx context.Context, city *domain.City) error
+	Update(ctx context.Context, city domain.City) error
+	// Delete returns InUse while users, donor companies or group memberships refer to the city,
+	// deleted users and companies included until they are purged.
+	Delete(ctx context.Context, id domain.ID, version int64) error
+
+	GetByID(ctx context.Context, id domain.ID) (domain.City, error)
+	// GetAll returns a page of cities and the cursor of the next page. Cities can be sorted by
+	// id, created_at and name and filtered by name, region and creation time.
+	GetAll(ctx context.Context, opts domain.ListOptions) ([]domain.CitySummary, string, error)
+}
+
+type Regions interface {
+	// Create returns AlreadyExists when a region with the same name, ignoring case, exists.
+	Create(ctx context.Context, region *domain.Region) error
+	Update(ctx context.Context, region domain.Region) error
+	// Delete returns InUse while cities belong to the region.
+	Delete(ctx context.Context, id domain.ID, version int64) error
+
+	GetByID(ctx context.Context, id domain.ID) (domain.Region, error)
+	// GetAll returns a page of regions and the cursor of the next page. Regions can be sorted
+	// by id, created_at and name and filtered by name and creation time.
+	GetAll(ctx context.Context, opts domain.ListOptions) ([]domain.Region, string, error)
 }
 
 type DonorCompanies interface {
 	Create(ctx context.Context, company *domain.DonorCompany) error
 	Update(ctx context.Context, company domain.DonorCompany) error
-	Delete(ctx context.Context, id domain.ID) error
+	// Delete marks the company as deleted, see Users.Delete. It returns InUse while acts
+	// that are not deleted refer to the company.
+	Delete(ctx context.Context, id domain.ID, version int64) error
+	// Restore returns NotFound unless the company is deleted.
+	Restore(ctx context.Context, id domain.ID) error
+	// Purge removes the companies deleted before the time for good, except the ones acts
+	// refer to, and returns their number.
+	Purge(ctx context.Context, deletedBefore time.Time) (int64, error)
 
 	GetByID(ctx context.Context, id domain.ID) (domain.DonorCompany, error)
+	// GetByCity returns all companies of the city, see Users.GetByCity.
 	GetByCity(ctx context.Context, cityID domain.ID) ([]domain.DonorCompany, error)
-	GetAll(ctx context.Context) ([]domain.DonorCompany, error)
+	// GetAll returns a page of companies and the cursor of the next page. Companies can be
+	// sorted by id, created_at, name and contract_date and filtered by name, city and creation
+	// time.
+	GetAll(ctx context.Context, opts domain.ListOptions) ([]domain.DonorCompany, string, error)
 }
 
 type Acts interface {
 	Create(ctx context.Context, act *domain.Act) error
 	Update(ctx context.Context, act domain.Act) error
-	Delete(ctx context.Context, id domain.ID) error
+	// Delete marks the act as deleted, see Users.Delete.
+	Delete(ctx context.Context, id domain.ID, version int64) error
+	// Restore returns NotFound unless the act is deleted and InvalidInput while its donor
+	// company is deleted.
+	Restore(ctx context.Context, id domain.ID) error
+	// Purge removes the acts deleted before the time for good along with their contents and
+	// returns their number. It runs several statements and belongs in a transaction.
+	Purge(ctx context.Context, deletedBefore time.Time) (int64, error)
 
 	GetByID(ctx context.Context, id domain.ID) (domain.Act, error)
+	// GetByUserID, GetByDonorCompanyID and GetByCity return all acts of the user, the company
+	// or the companies located in the city, see Users.GetByCity.
 	GetByUserID(ctx context.Context, userID domain.ID) ([]domain.Act, error)
 	GetByDonorCompanyID(ctx context.Context, donorCompanyID domain.ID) ([]domain.Act, error)
-	GetAll(ctx context.Context) ([]domain.Act, error)
+	GetByCity(ctx context.Context, cityID domain.ID) ([]domain.Act, error)
+	// GetAll returns a page of acts and the cursor of the next page. Acts can be sorted by id
+	// and created_at and filtered by user, donor company, the city of the company and creation
+	// time. A restriction keeps the acts of the user too.
+	GetAll(ctx context.Context, opts domain.ListOptions) ([]domain.Act, string, error)
 
 	AddFile(ctx context.Context, fileID domain.ID, actID domain.ID) error
 	GetActFiles(ctx context.Context, actID domain.ID) ([]domain.File, error)
+	// GetFileActs returns the acts the file is attached to, leaving deleted acts out.
+	GetFileActs(ctx context.Context, fileID domain.ID) ([]domain.Act, error)
 	RemoveFile(ctx context.Context, fileID domain.ID, actID domain.ID) error
 }
 
 type ActContents interface {
 	Create(ctx context.Context, contents ...domain.ActContent) error
 	Update(ctx context.Context, contents ...domain.ActContent) error
-	Delete(ctx context.Context, contentIDs ...domain.ID) error
+	Delete(ctx context.Context, id domain.ID, version int64) error
 
 	GetByID(ctx context.Context, id domain.ID) (domain.ActContent, error)
 	GetByActID(ctx context.Context, actID domain.ID) ([]domain.ActContent, error)
@@ -105,3 +273,11 @@ type Files interface {
 	UpdateStatusAndSetURL(ctx context.Context, fileID domain.ID, url string) error
 	GetByID(ctx context.Context, fileID domain.ID) (domain.File, error)
 }
+
+// AuditLog is written by the database itself on every change of the audited tables, see
+// RecordActor.
+type AuditLog interface {
+	// GetAll returns a page of the log and the cursor of the next page. Entries can be sorted
+	// by id and created_at and filtered by actor, entity and creation time.
+	GetAll(ctx context.Context, opts domain.ListOptions) ([]domain.AuditEntry, string, error)
+}
@@ -4,38 +4,101 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"foodsharing-backend/internal/domain"
+	"foodsharing-backend/pkg/hash"
 
 	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
 )
 
+// postgresSessionsRepo never stores refresh tokens as is: every token is hashed before
+// it reaches the database, lookups hash the presented value the same way.
 type postgresSessionsRepo struct {
-	db *pgxpool.Pool
+	db     DB
+	hasher hash.TokenHasher
 }
 
-func NewSessionsRepository(pool *pgxpool.Pool) Sessions {
-	return &postgresSessionsRepo{db: pool}
+func NewSessionsRepository(db DB, hasher hash.TokenHasher) Sessions {
+	return &postgresSessionsRepo{db: db, hasher: hasher}
 }
 
-const createSessionQuery = `INSERT INTO sessions (user_id, refresh_token, expires_at) VALUES ($1, $2, $3)`
+const createSessionQuery = `INSERT INTO sessions (user_id, token_hash, family_id, user_agent, ip, expires_at, 
+		created_at, last_used_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $7)`
 
 func (p *postgresSessionsRepo) Create(ctx context.Context, session domain.Session) error {
-	_, err := p.db.Exec(ctx, createSessionQuery, session.UserID, session.RefreshToken, session.ExpiresAt)
+	_, err := p.db.Exec(ctx, createSessionQuery, session.UserID, p.hasher.Hash(session.RefreshToken),
+		session.FamilyID, session.UserAgent, session.IP, session.ExpiresAt, time.Now())
 	if err != nil {
 		return fmt.Errorf("cannot create session: %w", err)
 	}
 	return nil
 }
 
-const getSessionByRefreshTokenQuery = `SELECT user_id, expires_at FROM sessions 
-		WHERE refresh_token = $1 AND expires_at > now()`
+const rotateSessionQuery = `WITH rotated AS (
+			UPDATE sessions SET rotated_at = now() 
+			WHERE token_hash = $1 AND rotated_at IS NULL AND expires_at > now() 
+			RETURNING user_id, family_id, created_at
+		)
+		INSERT INTO sessions (user_id, token_hash, family_id, user_agent, ip, expires_at, created_at, last_used_at) 
+		SELECT user_id, $2, family_id, $3, $4, $5, created_at, $6 FROM rotated`
+
+func (p *postgresSessionsRepo) Rotate(ctx context.Context, refreshToken string, next domain.Session) error {
+	tag, err := p.db.Exec(ctx, rotateSessionQuery, p.hasher.Hash(refreshToken), p.hasher.Hash(next.RefreshToken),
+		next.UserAgent, next.IP, next.ExpiresAt, time.Now())
+	if err != nil {
+		return fmt.Errorf("cannot rotate session: %w", err)
+	}
+	if tag.RowsAffected() != 1 {
+		return domain.NotFound
+	}
+	return nil
+}
+
+const deleteSessionQuery = `DELETE FROM sessions WHERE token_hash = $1`
+
+func (p *postgresSessionsRepo) Delete(ctx context.Context, refreshToken string) error {
+	tag, err := p.db.Exec(ctx, deleteSessionQuery, p.hasher.Hash(refreshToken))
+	if err != nil {
+		return fmt.Errorf("cannot delete session: %w", err)
+	}
+	if tag.RowsAffected() != 1 {
+		return domain.NotFound
+	}
+	return nil
+}
+
+const deleteSessionFamilyQuery = `DELETE FROM sessions WHERE user_id = $1 AND family_id = $2`
+
+func (p *postgresSessionsRepo) DeleteFamily(ctx context.Context, userID domain.ID, familyID string) error {
+	tag, err := p.db.Exec(ctx, deleteSessionFamilyQuery, userID, familyID)
+	if err != nil {
+		return fmt.Errorf("cannot delete session family: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.NotFound
+	}
+	return nil
+}
+
+const deleteUserSessionsQuery = `DELETE FROM sessions WHERE user_id = $1`
+
+func (p *postgresSessionsRepo) DeleteByUserID(ctx context.Context, userID domain.ID) error {
+	_, err := p.db.Exec(ctx, deleteUserSessionsQuery, userID)
+	if err != nil {
+		return fmt.Errorf("cannot delete user sessions: %w", err)
+	}
+	return nil
+}
+
+const getSessionByRefreshTokenQuery = `SELECT user_id, family_id, user_agent, ip, expires_at, created_at, 
+		last_used_at, rotated_at FROM sessions WHERE token_hash = $1 AND expires_at > now()`
 
 func (p *postgresSessionsRepo) GetByRefreshToken(ctx context.Context, refreshToken string) (domain.Session, error) {
 	var session domain.Session
-	row := p.db.QueryRow(ctx, getSessionByRefreshTokenQuery, refreshToken)
-	if err := row.Scan(session.UserID, session.ExpiresAt); err != nil {
+	row := p.db.QueryRow(ctx, getSessionByRefreshTokenQuery, p.hasher.Hash(refreshToken))
+	if err := row.Scan(&session.UserID, &session.FamilyID, &session.UserAgent, &session.IP, &session.ExpiresAt,
+		&session.CreatedAt, &session.LastUsedAt, &session.RotatedAt); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return domain.Session{}, domain.NotFound
 		}
@@ -47,8 +110,8 @@ func (p *postgresSessionsRepo) GetByRefreshToken(ctx context.Context, refreshTok
 	return session, nil
 }
 
-const getSessionsByUserID = `SELECT refresh_token, expires_at FROM sessions 
-		WHERE user_id = $1 AND expires_at > now()`
+const getSessionsByUserID = `SELECT family_id, user_agent, ip, expires_at, created_at, last_used_at FROM sessions 
+		WHERE user_id = $1 AND rotated_at IS NULL AND expires_at > now() ORDER BY last_used_at DESC`
 
 func (p *postgresSessionsRepo) GetByUserID(ctx context.Context, userID domain.ID) ([]domain.Session, error) {
 	var sessions []domain.Session
@@ -60,16 +123,45 @@ func (p *postgresSessionsRepo) GetByUserID(ctx context.Context, userID domain.ID
 
 	for rows.Next() {
 		var session domain.Session
-		if err := rows.Scan(&session.RefreshToken, &session.ExpiresAt); err != nil {
+		if err := rows.Scan(&session.FamilyID, &session.UserAgent, &session.IP, &session.ExpiresAt,
+			&session.CreatedAt, &session.LastUsedAt); err != nil {
 			return nil, fmt.Errorf("cannot scan session: %w", err)
 		}
 		session.UserID = userID
 		sessions = append(sessions, session)
 	}
 
-	if rows.Err() != nil {
+	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("cannot get user sessions: %w", err)
 	}
 
 	return sessions, nil
 }
+
+const getSessionsByFamilyQuery = `SELECT user_id, user_agent, ip, expires_at, created_at, last_used_at, rotated_at 
+		FROM sessions WHERE family_id = $1 ORDER BY last_used_at`
+
+func (p *postgresSessionsRepo) GetByFamily(ctx context.Context, familyID string) ([]domain.Session, error) {
+	var sessions []domain.Session
+	rows, err := p.db.Query(ctx, getSessionsByFamilyQuery, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get session family: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var session domain.Session
+		if err := rows.Scan(&session.UserID, &session.UserAgent, &session.IP, &session.ExpiresAt,
+			&session.CreatedAt, &session.LastUsedAt, &session.RotatedAt); err != nil {
+			return nil, fmt.Errorf("cannot scan session: %w", err)
+		}
+		session.FamilyID = familyID
+		sessions = append(sessions, session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("cannot get session family: %w", err)
+	}
+
+	return sessions, nil
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"foodsharing-backend/internal/domain"
+	"foodsharing-backend/pkg/hash"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// postgresTwoFactorRepo keeps TOTP secrets as is because codes cannot be checked without
+// them, recovery codes are hashed the same way refresh tokens are.
+type postgresTwoFactorRepo struct {
+	db     DB
+	hasher hash.TokenHasher
+}
+
+func NewTwoFactorRepository(db DB, hasher hash.TokenHasher) TwoFactor {
+	return &postgresTwoFactorRepo{db: db, hasher: hasher}
+}
+
+const setTwoFactorQuery = `INSERT INTO two_factor (user_id, secret, last_used_step, created_at) VALUES ($1, $2, 0, $3) 
+		ON CONFLICT (user_id) DO UPDATE SET secret = excluded.secret, enabled_at = NULL, last_used_step = 0, 
+		created_at = excluded.created_at WHERE two_factor.enabled_at IS NULL`
+
+func (p *postgresTwoFactorRepo) Set(ctx context.Context, userID domain.ID, secret string) error {
+	tag, err := p.db.Exec(ctx, setTwoFactorQuery, userID, secret, time.Now())
+	if err != nil {
+		return fmt.Errorf("cannot set two-factor secret: %w", err)
+	}
+	if tag.RowsAffected() != 1 {
+		return fmt.Errorf("%w: two-factor authentication is already enabled", domain.AlreadyExists)
+	}
+	return nil
+}
+
+const enableTwoFactorQuery = `UPDATE two_factor SET enabled_at = now(), last_used_step = $2 
+		WHERE user_id = $1 AND enabled_at IS NULL AND last_used_step < $2`
+
+func (p *postgresTwoFactorRepo) Enable(ctx context.Context, userID domain.ID, step int64) error {
+	tag, err := p.db.Exec(ctx, enableTwoFactorQuery, userID, step)
+	if err != nil {
+		return fmt.Errorf("cannot enable two-factor authentication: %w", err)
+	}
+	if tag.RowsAffected() != 1 {
+		return domain.NotFound
+	}
+	return nil
+}
+
+const useTwoFactorStepQuery = `UPDATE two_factor SET last_used_step = $2 
+		WHERE user_id = $1 AND enabled_at IS NOT NULL AND last_used_step < $2`
+
+func (p *postgresTwoFactorRepo) UseStep(ctx context.Context, userID domain.ID, step int64) error {
+	tag, err := p.db.Exec(ctx, useTwoFactorStepQuery, userID, step)
+	if err != nil {
+		return fmt.Errorf("cannot use two-factor code: %w", err)
+	}
+	if tag.RowsAffected() != 1 {
+		return domain.NotFound
+	}
+	return nil
+}
+
+const deleteTwoFactorQuery = `DELETE FROM two_factor WHERE user_id = $1`
+
+func (p *postgresTwoFactorRepo) Delete(ctx context.Context, userID domain.ID) error {
+	tag, err := p.db.Exec(ctx, deleteTwoFactorQuery, userID)
+	if err != nil {
+		return fmt.Errorf("cannot delete two-factor authentication: %w", err)
+	}
+	if tag.RowsAffected() != 1 {
+		return domain.NotFound
+	}
+	return nil
+}
+
+const getTwoFactorByUserIDQuery = `SELECT secret, enabled_at, last_used_step, created_at FROM two_factor 
+		WHERE user_id = $1`
+
+func (p *postgresTwoFactorRepo) GetByUserID(ctx context.Context, userID domain.ID) (domain.TwoFactor, error) {
+	var twoFactor domain.TwoFactor
+	row := p.db.QueryRow(ctx, getTwoFactorByUserIDQuery, userID)
+	if err := row.Scan(&twoFactor.Secret, &twoFactor.EnabledAt, &twoFactor.LastUsedStep,
+		&twoFactor.CreatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.TwoFactor{}, domain.NotFound
+		}
+
+		return domain.TwoFactor{}, fmt.Errorf("cannot get two-factor authentication: %w", err)
+	}
+
+	twoFactor.UserID = userID
+	return twoFactor, nil
+}
+
+const (
+	deleteRecoveryCodesQuery = `DELETE FROM recovery_codes WHERE user_id = $1`
+	createRecoveryCodeQuery  = `INSERT INTO recovery_codes (code_hash, user_id) VALUES ($1, $2)`
+)
+
+// SetRecoveryCodes sends all statements in one batch, which postgres runs as a single
+// transaction, so the old codes are never lost without the new ones being stored.
+func (p *postgresTwoFactorRepo) SetRecoveryCodes(ctx context.Context, userID domain.ID, codes []string) error {
+	batch := &pgx.Batch{}
+	batch.Queue(deleteRecoveryCodesQuery, userID)
+	for _, code := range codes {
+		batch.Queue(createRecoveryCodeQuery, p.hasher.Hash(code), userID)
+	}
+
+	br := p.db.SendBatch(ctx, batch)
+	defer br.Close()
+	for i := 0; i < batch.Len(); i++ {
+		if _, err := br.Exec(); err != nil {
+			return fmt.Errorf("cannot set recovery codes: %w", err)
+		}
+	}
+	return nil
+}
+
+const useRecoveryCodeQuery = `UPDATE recovery_codes SET used_at = now() 
+		WHERE code_hash = $1 AND user_id = $2 AND used_at IS NULL`
+
+func (p *postgresTwoFactorRepo) UseRecoveryCode(ctx context.Context, userID domain.ID, code string) error {
+	tag, err := p.db.Exec(ctx, useRecoveryCodeQuery, p.hasher.Hash(code), userID)
+	if err != nil {
+		return fmt.Errorf("cannot use recovery code: %w", err)
+	}
+	if tag.RowsAffected() != 1 {
+		return domain.NotFound
+	}
+	return nil
+}
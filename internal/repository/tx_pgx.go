@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"foodsharing-backend/pkg/hash"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// DB is what the repositories run their queries on: either the pool or a transaction.
+type DB interface {
+	Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+}
+
+// maxTxAttempts limits how many times a transaction is run when it keeps failing to
+// serialize.
+const maxTxAttempts = 3
+
+// SQLSTATE codes of the failures that go away when the transaction is run again.
+const (
+	serializationFailure = "40001"
+	deadlockDetected     = "40P01"
+)
+
+// postgresTxManager starts serializable transactions on the pool. Inside a transaction tx
+// is set and the transactions it starts are savepoints of tx.
+type postgresTxManager struct {
+	pool        *pgxpool.Pool
+	tx          pgx.Tx
+	tokenHasher hash.TokenHasher
+}
+
+func NewTxManager(pool *pgxpool.Pool, tokenHasher hash.TokenHasher) TxManager {
+	return &postgresTxManager{pool: pool, tokenHasher: tokenHasher}
+}
+
+func (m *postgresTxManager) WithinTx(ctx context.Context, fn func(repos *Repositories) error) error {
+	if m.tx != nil {
+		// A failed savepoint leaves the outer transaction usable, but a serialization
+		// failure aborts all of it, so only the outermost transaction is retried.
+		return m.tx.BeginFunc(ctx, m.bind(fn))
+	}
+
+	var err error
+	for attempt := 0; attempt < maxTxAttempts; attempt++ {
+		err = m.pool.BeginTxFunc(ctx, pgx.TxOptions{IsoLevel: pgx.Serializable}, m.bind(fn))
+		if !isRetryable(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// bind adapts fn to receive the repositories bound to the transaction.
+func (m *postgresTxManager) bind(fn func(repos *Repositories) error) func(pgx.Tx) error {
+	return func(tx pgx.Tx) error {
+		manager := &postgresTxManager{pool: m.pool, tx: tx, tokenHasher: m.tokenHasher}
+		return fn(newRepositories(tx, m.tokenHasher, manager))
+	}
+}
+
+func isRetryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == serializationFailure || pgErr.Code == deadlockDetected
+}
+
+// uniqueViolation is the SQLSTATE code of an insert or update that breaks a unique index.
+const uniqueViolation = "23505"
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation
+}
+
+// foreignKeyViolation is the SQLSTATE code of an insert or update that refers to a record
+// that does not exist.
+const foreignKeyViolation = "23503"
+
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation
+}
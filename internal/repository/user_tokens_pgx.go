@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"foodsharing-backend/internal/domain"
+	"foodsharing-backend/pkg/hash"
+
+	"github.com/jackc/pgx/v4"
+)
+
+type postgresUserTokensRepo struct {
+	db     DB
+	hasher hash.TokenHasher
+}
+
+func NewUserTokensRepository(db DB, hasher hash.TokenHasher) UserTokens {
+	return &postgresUserTokensRepo{db: db, hasher: hasher}
+}
+
+const createUserTokenQuery = `INSERT INTO user_tokens (token_hash, user_id, purpose, email, expires_at, created_at) 
+		VALUES ($1, $2, $3, $4, $5, $6)`
+
+func (p *postgresUserTokensRepo) Create(ctx context.Context, token domain.UserToken) error {
+	_, err := p.db.Exec(ctx, createUserTokenQuery, p.hasher.Hash(token.Token), token.UserID, token.Purpose,
+		token.Email, token.ExpiresAt, time.Now())
+	if err != nil {
+		return fmt.Errorf("cannot create user token: %w", err)
+	}
+	return nil
+}
+
+const consumeUserTokenQuery = `UPDATE user_tokens SET used_at = now() 
+		WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > now() 
+		RETURNING user_id, email, expires_at, created_at, used_at`
+
+func (p *postgresUserTokensRepo) Consume(ctx context.Context, purpose domain.TokenPurpose,
+	token string) (domain.UserToken, error) {
+	userToken := domain.UserToken{Token: token, Purpose: purpose}
+	row := p.db.QueryRow(ctx, consumeUserTokenQuery, p.hasher.Hash(token), purpose)
+	if err := row.Scan(&userToken.UserID, &userToken.Email, &userToken.ExpiresAt, &userToken.CreatedAt,
+		&userToken.UsedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.UserToken{}, domain.NotFound
+		}
+
+		return domain.UserToken{}, fmt.Errorf("cannot consume user token: %w", err)
+	}
+
+	return userToken, nil
+}
+
+const deleteUserTokensQuery = `DELETE FROM user_tokens WHERE user_id = $1 AND purpose = $2`
+
+func (p *postgresUserTokensRepo) DeleteByUserID(ctx context.Context, userID domain.ID,
+	purpose domain.TokenPurpose) error {
+	_, err := p.db.Exec(ctx, deleteUserTokensQuery, userID, purpose)
+	if err != nil {
+		return fmt.Errorf("cannot delete user tokens: %w", err)
+	}
+	return nil
+}
@@ -9,15 +9,18 @@ import (
 	"foodsharing-backend/internal/domain"
 
 	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
 )
 
+// errEmailTaken is returned when an email clashes with the one of another user, which the
+// users_email_uindex index rules out.
+var errEmailTaken = fmt.Errorf("%w: email is already taken", domain.AlreadyExists)
+
 type postgresUsersRepo struct {
-	db *pgxpool.Pool
+	db DB
 }
 
-func NewUsersRepository(pool *pgxpool.Pool) Users {
-	return &postgresUsersRepo{db: pool}
+func NewUsersRepository(db DB) Users {
+	return &postgresUsersRepo{db: db}
 }
 
 const createUserQuery = `INSERT INTO users(surname, name, patronymic, date_of_birth, phone_number, email, city_id, created_at) 
@@ -32,51 +35,135 @@ func (p *postgresUsersRepo) Create(ctx context.Context, user *domain.User) error
 		if errors.Is(err, pgx.ErrNoRows) {
 			return domain.NotFound
 		}
+		if isUniqueViolation(err) {
+			return errEmailTaken
+		}
+		if isForeignKeyViolation(err) {
+			return errUnknownCity
+		}
 
 		return fmt.Errorf("cannot create user: %w", err)
 	}
 
+	user.Version = 1
 	user.CreatedAt = createdAt
 	user.ID = id
 	return nil
 }
 
+// updateUserQuery drops the email verification when the email changes.
 const updateUserQuery = `UPDATE users SET surname = $1, name = $2, patronymic = $3, date_of_birth = $4, 
-		phone_number = $5, email = $6, updated_at = now() WHERE id = $7`
+		phone_number = $5, email = $6, city_id = $7, updated_at = now(), version = version + 1, 
+		email_verified_at = CASE WHEN email = $6 THEN email_verified_at END WHERE id = $8 AND version = $9`
 
 func (p *postgresUsersRepo) Update(ctx context.Context, user domain.User) error {
 	tag, err := p.db.Exec(ctx, updateUserQuery, user.Surname, user.Name, user.Patronymic, user.DateOfBirth,
-		user.PhoneNumber, user.Email, user.ID)
+		user.PhoneNumber, user.Email, user.CityID, user.ID, user.Version)
 	if err != nil {
+		if isUniqueViolation(err) {
+			return errEmailTaken
+		}
+		if isForeignKeyViolation(err) {
+			return errUnknownCity
+		}
 		return fmt.Errorf("cannot update user: %w", err)
 	}
 	if tag.RowsAffected() != 1 {
-		return domain.NotFound
+		return versionMismatch(ctx, p.db, "users", user.ID)
 	}
 	return nil
 }
 
-const deleteUserQuery = `DELETE FROM users WHERE id = $1`
+const deleteUserQuery = `UPDATE users SET deleted_at = now(), version = version + 1 
+		WHERE id = $1 AND version = $2 AND deleted_at IS NULL`
 
-func (p *postgresUsersRepo) Delete(ctx context.Context, id domain.ID) error {
-	tag, err := p.db.Exec(ctx, deleteUserQuery, id)
+func (p *postgresUsersRepo) Delete(ctx context.Context, id domain.ID, version int64) error {
+	tag, err := p.db.Exec(ctx, deleteUserQuery, id, version)
 	if err != nil {
 		return fmt.Errorf("cannot delete user: %w", err)
 	}
+	if tag.RowsAffected() != 1 {
+		return versionMismatch(ctx, p.db, "users", id)
+	}
+	return nil
+}
+
+const (
+	restoreUserQuery = `UPDATE users u SET deleted_at = NULL, version = version + 1 
+		WHERE id = $1 AND deleted_at IS NOT NULL 
+		AND NOT EXISTS (SELECT 1 FROM users WHERE lower(email) = lower(u.email) AND deleted_at IS NULL)`
+	deletedUserExistsQuery = `SELECT EXISTS(SELECT 1 FROM users WHERE id = $1 AND deleted_at IS NOT NULL)`
+)
+
+func (p *postgresUsersRepo) Restore(ctx context.Context, id domain.ID) error {
+	tag, err := p.db.Exec(ctx, restoreUserQuery, id)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return errEmailTaken
+		}
+		return fmt.Errorf("cannot restore user: %w", err)
+	}
+	if tag.RowsAffected() == 1 {
+		return nil
+	}
+
+	var exists bool
+	if err := p.db.QueryRow(ctx, deletedUserExistsQuery, id).Scan(&exists); err != nil {
+		return fmt.Errorf("cannot check user: %w", err)
+	}
+	if !exists {
+		return domain.NotFound
+	}
+	return errEmailTaken
+}
+
+// Users are kept while acts or files refer to them. Their sessions and memberships go with
+// them, the rest of their data is removed by the foreign keys.
+const (
+	purgeableUsers = `SELECT id FROM users u WHERE deleted_at < $1 
+		AND NOT EXISTS (SELECT 1 FROM acts WHERE user_id = u.id) 
+		AND NOT EXISTS (SELECT 1 FROM files WHERE user_id = u.id)`
+	purgeUserSessionsQuery = `DELETE FROM sessions WHERE user_id IN (` + purgeableUsers + `)`
+	purgeUserMembersQuery  = `DELETE FROM users_to_groups WHERE user_id IN (` + purgeableUsers + `)`
+	purgeUsersQuery        = `DELETE FROM users WHERE id IN (` + purgeableUsers + `)`
+)
+
+func (p *postgresUsersRepo) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	for _, query := range []string{purgeUserSessionsQuery, purgeUserMembersQuery} {
+		if _, err := p.db.Exec(ctx, query, deletedBefore); err != nil {
+			return 0, fmt.Errorf("cannot purge users: %w", err)
+		}
+	}
+	tag, err := p.db.Exec(ctx, purgeUsersQuery, deletedBefore)
+	if err != nil {
+		return 0, fmt.Errorf("cannot purge users: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+const verifyUserEmailQuery = `UPDATE users SET email_verified_at = now(), version = version + 1 
+		WHERE id = $1 AND email = $2 AND deleted_at IS NULL`
+
+func (p *postgresUsersRepo) VerifyEmail(ctx context.Context, id domain.ID, email string) error {
+	tag, err := p.db.Exec(ctx, verifyUserEmailQuery, id, email)
+	if err != nil {
+		return fmt.Errorf("cannot verify user email: %w", err)
+	}
 	if tag.RowsAffected() != 1 {
 		return domain.NotFound
 	}
 	return nil
 }
 
-const getUserByIDQuery = `SELECT surname, name, patronymic, date_of_birth, phone_number, email, created_at, updated_at
-		FROM users WHERE id = $1`
+const getUserByIDQuery = `SELECT surname, name, patronymic, date_of_birth, phone_number, email, city_id, 
+		email_verified_at, version, created_at, updated_at FROM users WHERE id = $1 AND deleted_at IS NULL`
 
 func (p *postgresUsersRepo) GetByID(ctx context.Context, id domain.ID) (domain.User, error) {
 	var user domain.User
 	row := p.db.QueryRow(ctx, getUserByIDQuery, id)
 	if err := row.Scan(&user.Surname, &user.Name, &user.Patronymic,
-		&user.DateOfBirth, &user.PhoneNumber, &user.Email, &user.CreatedAt, &user.UpdatedAt); err != nil {
+		&user.DateOfBirth, &user.PhoneNumber, &user.Email, &user.CityID, &user.EmailVerifiedAt, &user.Version,
+		&user.CreatedAt, &user.UpdatedAt); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return domain.User{}, domain.NotFound
 		}
@@ -88,14 +175,17 @@ func (p *postgresUsersRepo) GetByID(ctx context.Context, id domain.ID) (domain.U
 	return user, nil
 }
 
-const getUserByEmailQuery = `SELECT id, surname, name, patronymic, date_of_birth, phone_number, created_at, updated_at 
-		FROM users WHERE email = $1`
+// getUserByEmailQuery ignores the case of the email, as users_email_uindex does.
+const getUserByEmailQuery = `SELECT id, surname, name, patronymic, date_of_birth, phone_number, email, city_id, 
+		email_verified_at, version, created_at, updated_at FROM users 
+		WHERE lower(email) = lower($1) AND deleted_at IS NULL`
 
 func (p *postgresUsersRepo) GetByEmail(ctx context.Context, email string) (domain.User, error) {
 	var user domain.User
 	row := p.db.QueryRow(ctx, getUserByEmailQuery, email)
 	if err := row.Scan(&user.ID, &user.Surname, &user.Name, &user.Patronymic,
-		&user.DateOfBirth, &user.PhoneNumber, &user.CreatedAt, &user.UpdatedAt); err != nil {
+		&user.DateOfBirth, &user.PhoneNumber, &user.Email, &user.CityID, &user.EmailVerifiedAt, &user.Version,
+		&user.CreatedAt, &user.UpdatedAt); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return domain.User{}, domain.NotFound
 		}
@@ -103,33 +193,66 @@ func (p *postgresUsersRepo) GetByEmail(ctx context.Context, email string) (domai
 		return domain.User{}, fmt.Errorf("cannot get user by email: %w", err)
 	}
 
-	user.Email = email
 	return user, nil
 }
 
-const getAllUsersQuery = `SELECT id, surname, name, patronymic, date_of_birth, phone_number, email, created_at, 
-		updated_at FROM users`
+var usersList = listSpec{
+	columns: `id, surname, name, patronymic, date_of_birth, phone_number, email, city_id, email_verified_at, 
+		version, created_at, updated_at, deleted_at`,
+	from:    "users",
+	id:      "id",
+	created: "created_at",
+	sorts: map[string]sortColumn{
+		"id":         {expr: "id", typ: "bigint"},
+		"created_at": {expr: "created_at", typ: "timestamptz"},
+		"surname":    {expr: "surname", typ: "text"},
+	},
+	city:    "city_id",
+	deleted: "deleted_at",
+}
+
+func (p *postgresUsersRepo) GetAll(ctx context.Context, opts domain.ListOptions) ([]domain.User, string, error) {
+	q, err := newListQuery(usersList, opts)
+	if err != nil {
+		return nil, "", err
+	}
 
-func (p *postgresUsersRepo) GetAll(ctx context.Context) ([]domain.User, error) {
 	var users []domain.User
-	rows, err := p.db.Query(ctx, getAllUsersQuery)
+	rows, err := p.db.Query(ctx, q.sql(), q.args...)
 	if err != nil {
-		return nil, fmt.Errorf("cannot get all users: %w", err)
+		return nil, "", fmt.Errorf("cannot get users: %w", err)
 	}
 	defer rows.Close()
 
 	for rows.Next() {
 		var user domain.User
+		var key string
 		if err := rows.Scan(&user.ID, &user.Surname, &user.Name, &user.Patronymic,
-			&user.DateOfBirth, &user.PhoneNumber, &user.Email, &user.CreatedAt, &user.UpdatedAt); err != nil {
-			return nil, fmt.Errorf("cannot scan user: %w", err)
+			&user.DateOfBirth, &user.PhoneNumber, &user.Email, &user.CityID, &user.EmailVerifiedAt, &user.Version,
+			&user.CreatedAt, &user.UpdatedAt, &user.DeletedAt, &key); err != nil {
+			return nil, "", fmt.Errorf("cannot scan user: %w", err)
 		}
+		q.add(user.ID, key)
 		users = append(users, user)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("cannot get all users: %w", err)
+		return nil, "", fmt.Errorf("cannot get users: %w", err)
 	}
 
+	return users[:q.size()], q.next(), nil
+}
+
+func (p *postgresUsersRepo) GetByCity(ctx context.Context, cityID domain.ID) ([]domain.User, error) {
+	var users []domain.User
+	opts := domain.ListOptions{Filter: domain.ListFilter{CityID: cityID}}
+	err := allPages(opts, func(opts domain.ListOptions) (string, error) {
+		page, next, err := p.GetAll(ctx, opts)
+		users = append(users, page...)
+		return next, err
+	})
+	if err != nil {
+		return nil, err
+	}
 	return users, nil
 }
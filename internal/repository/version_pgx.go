@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"foodsharing-backend/internal/domain"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// versionMismatch tells why an update of the record in table matched no row: Conflict if
+// the record has changed since the expected version, NotFound if it is gone.
+func versionMismatch(ctx context.Context, db DB, table string, id domain.ID) error {
+	var exists bool
+	query := fmt.Sprintf("SELECT EXISTS(SELECT 1 FROM %s WHERE id = $1)", table)
+	if err := db.QueryRow(ctx, query, id).Scan(&exists); err != nil {
+		return fmt.Errorf("cannot check %s %d: %w", table, id, err)
+	}
+	if exists {
+		return domain.Conflict
+	}
+	return domain.NotFound
+}
+
+// versionMatches checks the record in table against the expected version after a statement
+// guarded by further conditions matched no row. It fails like versionMismatch when the
+// version is to blame and returns nil when one of the other conditions is.
+func versionMatches(ctx context.Context, db DB, table string, id domain.ID, version int64) error {
+	var current int64
+	query := fmt.Sprintf("SELECT version FROM %s WHERE id = $1", table)
+	if err := db.QueryRow(ctx, query, id).Scan(&current); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.NotFound
+		}
+		return fmt.Errorf("cannot check %s %d: %w", table, id, err)
+	}
+	if current != version {
+		return domain.Conflict
+	}
+	return nil
+}
@@ -0,0 +1,428 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"foodsharing-backend/internal/domain"
+	"foodsharing-backend/internal/repository"
+)
+
+type ActsService struct {
+	repo      repository.Acts
+	contents  repository.ActContents
+	companies repository.DonorCompanies
+	cities    repository.Cities
+	files     repository.Files
+	users     repository.Users
+	txManager repository.TxManager
+}
+
+func NewActsService(repo repository.Acts, contents repository.ActContents, companies repository.DonorCompanies,
+	cities repository.Cities, files repository.Files, users repository.Users,
+	txManager repository.TxManager) *ActsService {
+	return &ActsService{
+		repo:      repo,
+		contents:  contents,
+		companies: companies,
+		cities:    cities,
+		files:     files,
+		users:     users,
+		txManager: txManager,
+	}
+}
+
+func (i ActInput) validate() error {
+	if i.DonorCompanyID == 0 {
+		return fmt.Errorf("%w: donor_company_id is required", domain.InvalidInput)
+	}
+	for _, content := range i.Contents {
+		if err := content.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (i ActUpdateInput) validate() error {
+	if i.DonorCompanyID == 0 {
+		return fmt.Errorf("%w: donor_company_id is required", domain.InvalidInput)
+	}
+	return nil
+}
+
+func (i ActContentInput) validate() error {
+	if i.Name == "" {
+		return fmt.Errorf("%w: name is required", domain.InvalidInput)
+	}
+	if i.Count <= 0 {
+		return fmt.Errorf("%w: count must be positive", domain.InvalidInput)
+	}
+	if i.Price < 0 {
+		return fmt.Errorf("%w: price cannot be negative", domain.InvalidInput)
+	}
+	if i.ExpirationDate.IsZero() {
+		return fmt.Errorf("%w: expiration_date is required", domain.InvalidInput)
+	}
+	return nil
+}
+
+func (i ActContentInput) toDomain(actID domain.ID) domain.ActContent {
+	return domain.ActContent{
+		ActID:          actID,
+		Number:         i.Number,
+		Name:           i.Name,
+		Count:          i.Count,
+		Price:          i.Price,
+		ExpirationDate: i.ExpirationDate,
+		Comment:        i.Comment,
+	}
+}
+
+// Create stores the act on behalf of the current user along with its contents and
+// attaches the given files to it, all in one transaction. Only users with a verified email
+// may create acts.
+func (s *ActsService) Create(ctx context.Context, input ActInput) (domain.Act, error) {
+	principal, ok := domain.PrincipalFromContext(ctx)
+	if !ok {
+		return domain.Act{}, domain.Unauthorized
+	}
+	if err := input.validate(); err != nil {
+		return domain.Act{}, err
+	}
+
+	company, err := s.getDonorCompany(ctx, input.DonorCompanyID)
+	if err != nil {
+		return domain.Act{}, err
+	}
+	act := domain.Act{
+		UserID:         principal.UserID,
+		DonorCompanyID: input.DonorCompanyID,
+	}
+	if !principal.CanOn(domain.CreateAct, act.Attributes(company)) {
+		return domain.Act{}, domain.Forbidden
+	}
+
+	if err := s.checkEmailVerified(ctx, principal.UserID); err != nil {
+		return domain.Act{}, err
+	}
+	for _, fileID := range input.FileIDs {
+		if err := s.checkFile(ctx, principal, fileID); err != nil {
+			return domain.Act{}, err
+		}
+	}
+
+	err = s.txManager.WithinTx(ctx, func(repos *repository.Repositories) error {
+		if err := repos.Acts.Create(ctx, &act); err != nil {
+			return err
+		}
+
+		if len(input.Contents) > 0 {
+			contents := make([]domain.ActContent, 0, len(input.Contents))
+			for _, content := range input.Contents {
+				contents = append(contents, content.toDomain(act.ID))
+			}
+			if err := repos.ActContents.Create(ctx, contents...); err != nil {
+				return err
+			}
+		}
+
+		for _, fileID := range input.FileIDs {
+			if err := repos.Acts.AddFile(ctx, fileID, act.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return domain.Act{}, err
+	}
+
+	return act, nil
+}
+
+// Update needs the EditAct permission for the act both with its current and its new
+// donor company, so acts cannot be moved out of or into cities the user cannot edit.
+func (s *ActsService) Update(ctx context.Context, id domain.ID, input ActUpdateInput, version int64) error {
+	if err := input.validate(); err != nil {
+		return err
+	}
+
+	act, principal, err := s.editAct(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := checkVersion(version, act.Version); err != nil {
+		return err
+	}
+
+	company, err := s.getDonorCompany(ctx, input.DonorCompanyID)
+	if err != nil {
+		return err
+	}
+	act.DonorCompanyID = input.DonorCompanyID
+	if !principal.CanOn(domain.EditAct, act.Attributes(company)) {
+		return domain.Forbidden
+	}
+
+	return s.repo.Update(ctx, act)
+}
+
+func (s *ActsService) Delete(ctx context.Context, id domain.ID, version int64) error {
+	act, _, err := s.editAct(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := checkVersion(version, act.Version); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, id, act.Version)
+}
+
+func (s *ActsService) Restore(ctx context.Context, id domain.ID) error {
+	if _, err := authorize(ctx, domain.Admin); err != nil {
+		return err
+	}
+	return s.repo.Restore(ctx, id)
+}
+
+func (s *ActsService) GetByID(ctx context.Context, id domain.ID) (domain.Act, error) {
+	return s.readAct(ctx, id)
+}
+
+// GetAll lists the acts matching the filter. Users who may not read all acts see their own
+// acts and the acts in the cities they may read acts in.
+func (s *ActsService) GetAll(ctx context.Context, opts domain.ListOptions) ([]domain.Act, string, error) {
+	principal, ok := domain.PrincipalFromContext(ctx)
+	if !ok {
+		return nil, "", domain.Unauthorized
+	}
+
+	opts.Restriction = nil
+	if !principal.Can(domain.ReadAct) {
+		opts.Restriction = &domain.ListRestriction{
+			CityIDs: principal.CitiesWith(domain.ReadAct),
+			OwnerID: principal.UserID,
+		}
+	}
+	opts.Deleted = false
+	return s.repo.GetAll(ctx, opts)
+}
+
+func (s *ActsService) GetDeleted(ctx context.Context, opts domain.ListOptions) ([]domain.Act, string, error) {
+	opts, err := listDeleted(ctx, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	return s.repo.GetAll(ctx, opts)
+}
+
+// GetContents places the expiration dates in the zone of the city of the donor company.
+func (s *ActsService) GetContents(ctx context.Context, actID domain.ID) ([]domain.ActContent, error) {
+	act, err := s.readAct(ctx, actID)
+	if err != nil {
+		return nil, err
+	}
+	contents, err := s.contents.GetByActID(ctx, actID)
+	if err != nil {
+		return nil, err
+	}
+
+	company, err := s.getDonorCompany(ctx, act.DonorCompanyID)
+	if err != nil {
+		return nil, err
+	}
+	location, err := newCityLocations(s.cities).get(ctx, company.CityID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range contents {
+		contents[i].ExpirationDate = domain.DateIn(contents[i].ExpirationDate, location)
+	}
+	return contents, nil
+}
+
+func (s *ActsService) AddContents(ctx context.Context, actID domain.ID, inputs ...ActContentInput) error {
+	if len(inputs) == 0 {
+		return fmt.Errorf("%w: at least one content is required", domain.InvalidInput)
+	}
+
+	contents := make([]domain.ActContent, 0, len(inputs))
+	for _, input := range inputs {
+		if err := input.validate(); err != nil {
+			return err
+		}
+		contents = append(contents, input.toDomain(actID))
+	}
+
+	if _, _, err := s.editAct(ctx, actID); err != nil {
+		return err
+	}
+
+	return s.contents.Create(ctx, contents...)
+}
+
+func (s *ActsService) UpdateContent(ctx context.Context, actID domain.ID, contentID domain.ID,
+	input ActContentInput, version int64) error {
+	if err := input.validate(); err != nil {
+		return err
+	}
+	if _, _, err := s.editAct(ctx, actID); err != nil {
+		return err
+	}
+	current, err := s.getContent(ctx, actID, contentID)
+	if err != nil {
+		return err
+	}
+	if err := checkVersion(version, current.Version); err != nil {
+		return err
+	}
+
+	content := input.toDomain(actID)
+	content.ID = contentID
+	content.Version = current.Version
+	return s.contents.Update(ctx, content)
+}
+
+func (s *ActsService) DeleteContent(ctx context.Context, actID domain.ID, contentID domain.ID,
+	version int64) error {
+	if _, _, err := s.editAct(ctx, actID); err != nil {
+		return err
+	}
+	content, err := s.getContent(ctx, actID, contentID)
+	if err != nil {
+		return err
+	}
+	if err := checkVersion(version, content.Version); err != nil {
+		return err
+	}
+	return s.contents.Delete(ctx, contentID, content.Version)
+}
+
+func (s *ActsService) GetFiles(ctx context.Context, actID domain.ID) ([]domain.File, error) {
+	if _, err := s.readAct(ctx, actID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetActFiles(ctx, actID)
+}
+
+func (s *ActsService) AddFile(ctx context.Context, actID domain.ID, fileID domain.ID) error {
+	_, principal, err := s.editAct(ctx, actID)
+	if err != nil {
+		return err
+	}
+	if err := s.checkFile(ctx, principal, fileID); err != nil {
+		return err
+	}
+	return s.repo.AddFile(ctx, fileID, actID)
+}
+
+func (s *ActsService) RemoveFile(ctx context.Context, actID domain.ID, fileID domain.ID) error {
+	if _, _, err := s.editAct(ctx, actID); err != nil {
+		return err
+	}
+	return s.repo.RemoveFile(ctx, fileID, actID)
+}
+
+// readAct loads the act if the current user may see it: either the act is their own or
+// the user holds the ReadAct permission in its scope.
+func (s *ActsService) readAct(ctx context.Context, id domain.ID) (domain.Act, error) {
+	principal, ok := domain.PrincipalFromContext(ctx)
+	if !ok {
+		return domain.Act{}, domain.Unauthorized
+	}
+
+	act, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return domain.Act{}, err
+	}
+	if act.UserID == principal.UserID {
+		return act, nil
+	}
+
+	attrs, err := s.attributes(ctx, act)
+	if err != nil {
+		return domain.Act{}, err
+	}
+	if !principal.CanOn(domain.ReadAct, attrs) {
+		return domain.Act{}, domain.Forbidden
+	}
+	return act, nil
+}
+
+// editAct loads the act if the current user holds the EditAct permission in its scope.
+func (s *ActsService) editAct(ctx context.Context, id domain.ID) (domain.Act, domain.Principal, error) {
+	principal, ok := domain.PrincipalFromContext(ctx)
+	if !ok {
+		return domain.Act{}, domain.Principal{}, domain.Unauthorized
+	}
+
+	act, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return domain.Act{}, domain.Principal{}, err
+	}
+
+	attrs, err := s.attributes(ctx, act)
+	if err != nil {
+		return domain.Act{}, domain.Principal{}, err
+	}
+	if !principal.CanOn(domain.EditAct, attrs) {
+		return domain.Act{}, domain.Principal{}, domain.Forbidden
+	}
+	return act, principal, nil
+}
+
+func (s *ActsService) attributes(ctx context.Context, act domain.Act) (domain.Attributes, error) {
+	company, err := s.companies.GetByID(ctx, act.DonorCompanyID)
+	if err != nil {
+		return domain.Attributes{}, err
+	}
+	return act.Attributes(company), nil
+}
+
+func (s *ActsService) checkEmailVerified(ctx context.Context, userID domain.ID) error {
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !user.IsEmailVerified() {
+		return fmt.Errorf("%w: email is not verified", domain.Forbidden)
+	}
+	return nil
+}
+
+func (s *ActsService) getDonorCompany(ctx context.Context, id domain.ID) (domain.DonorCompany, error) {
+	company, err := s.companies.GetByID(ctx, id)
+	if err != nil {
+		return domain.DonorCompany{}, fmt.Errorf("cannot find donor company %d: %w", id, err)
+	}
+	return company, nil
+}
+
+// getContent returns the content, making sure it belongs to the act.
+func (s *ActsService) getContent(ctx context.Context, actID domain.ID, contentID domain.ID) (domain.ActContent, error) {
+	content, err := s.contents.GetByID(ctx, contentID)
+	if err != nil {
+		return domain.ActContent{}, err
+	}
+	if content.ActID != actID {
+		return domain.ActContent{}, domain.NotFound
+	}
+	return content, nil
+}
+
+// checkFile makes sure the file has reached the storage and, unless the user is an
+// admin, was uploaded by the user themselves.
+func (s *ActsService) checkFile(ctx context.Context, principal domain.Principal, fileID domain.ID) error {
+	file, err := s.files.GetByID(ctx, fileID)
+	if err != nil {
+		return fmt.Errorf("cannot find file %d: %w", fileID, err)
+	}
+	if file.UserID != principal.UserID && !principal.Permissions.IsAdmin() {
+		return fmt.Errorf("%w: file %d belongs to another user", domain.Forbidden, fileID)
+	}
+	if file.Status != domain.UploadedToStorage {
+		return fmt.Errorf("%w: file %d is not uploaded yet", domain.InvalidInput, fileID)
+	}
+	return nil
+}
@@ -0,0 +1,281 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"foodsharing-backend/internal/domain"
+	"foodsharing-backend/internal/repository"
+	mock_repository "foodsharing-backend/internal/repository/mocks"
+
+	"github.com/golang/mock/gomock"
+)
+
+type actsMocks struct {
+	repo      *mock_repository.MockActs
+	contents  *mock_repository.MockActContents
+	companies *mock_repository.MockDonorCompanies
+	cities    *mock_repository.MockCities
+	files     *mock_repository.MockFiles
+	users     *mock_repository.MockUsers
+	txManager *mock_repository.MockTxManager
+}
+
+func newActsService(t *testing.T) (*ActsService, actsMocks) {
+	ctrl := gomock.NewController(t)
+	m := actsMocks{
+		repo:      mock_repository.NewMockActs(ctrl),
+		contents:  mock_repository.NewMockActContents(ctrl),
+		companies: mock_repository.NewMockDonorCompanies(ctrl),
+		cities:    mock_repository.NewMockCities(ctrl),
+		files:     mock_repository.NewMockFiles(ctrl),
+		users:     mock_repository.NewMockUsers(ctrl),
+		txManager: mock_repository.NewMockTxManager(ctrl),
+	}
+	return NewActsService(m.repo, m.contents, m.companies, m.cities, m.files, m.users, m.txManager), m
+}
+
+func TestActsServiceGetContentsInCityZone(t *testing.T) {
+	s, m := newActsService(t)
+	act := domain.Act{Object: domain.Object{ID: 5}, UserID: 1, DonorCompanyID: 7}
+	company := domain.DonorCompany{Object: domain.Object{ID: 7}, CityID: vladivostok.ID}
+	stored := time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)
+
+	m.repo.EXPECT().GetByID(gomock.Any(), act.ID).Return(act, nil)
+	m.contents.EXPECT().GetByActID(gomock.Any(), act.ID).Return([]domain.ActContent{
+		{Object: domain.Object{ID: 1}, ActID: act.ID, ExpirationDate: stored},
+		{Object: domain.Object{ID: 2}, ActID: act.ID, ExpirationDate: stored.AddDate(0, 0, 1)},
+	}, nil)
+	m.companies.EXPECT().GetByID(gomock.Any(), company.ID).Return(company, nil)
+	m.cities.EXPECT().GetByID(gomock.Any(), vladivostok.ID).Return(vladivostok, nil)
+
+	contents, err := s.GetContents(asUser(1), act.ID)
+	if err != nil {
+		t.Fatalf("GetContents() error = %v", err)
+	}
+	want := []string{"2024-05-01T00:00:00+10:00", "2024-05-02T00:00:00+10:00"}
+	for i, content := range contents {
+		if date := content.ExpirationDate.Format(time.RFC3339); date != want[i] {
+			t.Errorf("content %d: expiration date = %s, want %s", content.ID, date, want[i])
+		}
+	}
+}
+
+var (
+	testAct     = domain.Act{Object: domain.Object{ID: 5, Version: 2}, UserID: 2, DonorCompanyID: 7}
+	testCompany = domain.DonorCompany{Object: domain.Object{ID: 7}, CityID: vladivostok.ID}
+)
+
+func TestActsServiceGetByID(t *testing.T) {
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		setup   func(m actsMocks)
+		wantErr error
+	}{
+		{
+			name: "author",
+			ctx:  asUser(testAct.UserID),
+			setup: func(m actsMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), testAct.ID).Return(testAct, nil)
+			},
+		},
+		{
+			name: "reader in the city of the company",
+			ctx:  asCityUser(1, vladivostok.ID, domain.ReadAct),
+			setup: func(m actsMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), testAct.ID).Return(testAct, nil)
+				m.companies.EXPECT().GetByID(gomock.Any(), testCompany.ID).Return(testCompany, nil)
+			},
+		},
+		{
+			name: "reader in another city",
+			ctx:  asCityUser(1, newYork.ID, domain.ReadAct),
+			setup: func(m actsMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), testAct.ID).Return(testAct, nil)
+				m.companies.EXPECT().GetByID(gomock.Any(), testCompany.ID).Return(testCompany, nil)
+			},
+			wantErr: domain.Forbidden,
+		},
+		{
+			name: "not found",
+			ctx:  asUser(1, domain.ReadAct),
+			setup: func(m actsMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), testAct.ID).Return(domain.Act{}, domain.NotFound)
+			},
+			wantErr: domain.NotFound,
+		},
+		{
+			name: "repository error",
+			ctx:  asUser(1, domain.ReadAct),
+			setup: func(m actsMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), testAct.ID).Return(testAct, nil)
+				m.companies.EXPECT().GetByID(gomock.Any(), testCompany.ID).Return(domain.DonorCompany{}, errRepo)
+			},
+			wantErr: errRepo,
+		},
+		{
+			name:    "anonymous",
+			ctx:     context.Background(),
+			setup:   func(m actsMocks) {},
+			wantErr: domain.Unauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m := newActsService(t)
+			tt.setup(m)
+
+			_, err := s.GetByID(tt.ctx, testAct.ID)
+			checkErr(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestActsServiceCreate(t *testing.T) {
+	verified := time.Now()
+	author := domain.User{Object: domain.Object{ID: testAct.UserID}, EmailVerifiedAt: &verified}
+	input := ActInput{
+		DonorCompanyID: testCompany.ID,
+		Contents:       []ActContentInput{{Name: "Bread", Count: 3, ExpirationDate: verified}},
+	}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		setup   func(m actsMocks)
+		wantErr error
+	}{
+		{
+			name: "act with contents",
+			ctx:  asUser(author.ID, domain.CreateAct),
+			setup: func(m actsMocks) {
+				m.users.EXPECT().GetByID(gomock.Any(), author.ID).Return(author, nil)
+				m.repo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+				m.contents.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name: "contents fail",
+			ctx:  asUser(author.ID, domain.CreateAct),
+			setup: func(m actsMocks) {
+				m.users.EXPECT().GetByID(gomock.Any(), author.ID).Return(author, nil)
+				m.repo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+				m.contents.EXPECT().Create(gomock.Any(), gomock.Any()).Return(errRepo)
+			},
+			wantErr: errRepo,
+		},
+		{
+			name: "unverified email",
+			ctx:  asUser(author.ID, domain.CreateAct),
+			setup: func(m actsMocks) {
+				m.users.EXPECT().GetByID(gomock.Any(), author.ID).Return(domain.User{Object: author.Object}, nil)
+			},
+			wantErr: domain.Forbidden,
+		},
+		{
+			name:    "without CreateAct",
+			ctx:     asUser(author.ID, domain.ReadAct),
+			setup:   func(m actsMocks) {},
+			wantErr: domain.Forbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m := newActsService(t)
+			m.companies.EXPECT().GetByID(gomock.Any(), testCompany.ID).Return(testCompany, nil)
+			m.txManager.EXPECT().WithinTx(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, fn func(*repository.Repositories) error) error {
+					return fn(&repository.Repositories{Acts: m.repo, ActContents: m.contents})
+				}).AnyTimes()
+			tt.setup(m)
+
+			_, err := s.Create(tt.ctx, input)
+			checkErr(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestActsServiceDelete(t *testing.T) {
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		version int64
+		setup   func(m actsMocks)
+		wantErr error
+	}{
+		{
+			name:    "editor in the city of the company",
+			ctx:     asCityUser(1, vladivostok.ID, domain.EditAct),
+			version: testAct.Version,
+			setup: func(m actsMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), testAct.ID).Return(testAct, nil)
+				m.companies.EXPECT().GetByID(gomock.Any(), testCompany.ID).Return(testCompany, nil)
+				m.repo.EXPECT().Delete(gomock.Any(), testAct.ID, testAct.Version).Return(nil)
+			},
+		},
+		{
+			name:    "author without EditAct",
+			ctx:     asUser(testAct.UserID),
+			version: testAct.Version,
+			setup: func(m actsMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), testAct.ID).Return(testAct, nil)
+				m.companies.EXPECT().GetByID(gomock.Any(), testCompany.ID).Return(testCompany, nil)
+			},
+			wantErr: domain.Forbidden,
+		},
+		{
+			name:    "stale version",
+			ctx:     asUser(1, domain.EditAct),
+			version: testAct.Version + 1,
+			setup: func(m actsMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), testAct.ID).Return(testAct, nil)
+				m.companies.EXPECT().GetByID(gomock.Any(), testCompany.ID).Return(testCompany, nil)
+			},
+			wantErr: domain.Conflict,
+		},
+		{
+			name:    "changed after it was read",
+			ctx:     asUser(1, domain.EditAct),
+			version: testAct.Version,
+			setup: func(m actsMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), testAct.ID).Return(testAct, nil)
+				m.companies.EXPECT().GetByID(gomock.Any(), testCompany.ID).Return(testCompany, nil)
+				m.repo.EXPECT().Delete(gomock.Any(), testAct.ID, testAct.Version).Return(domain.Conflict)
+			},
+			wantErr: domain.Conflict,
+		},
+		{
+			name:    "not found",
+			ctx:     asUser(1, domain.EditAct),
+			version: testAct.Version,
+			setup: func(m actsMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), testAct.ID).Return(domain.Act{}, domain.NotFound)
+			},
+			wantErr: domain.NotFound,
+		},
+		{
+			name:    "repository error",
+			ctx:     asUser(1, domain.EditAct),
+			version: testAct.Version,
+			setup: func(m actsMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), testAct.ID).Return(testAct, nil)
+				m.companies.EXPECT().GetByID(gomock.Any(), testCompany.ID).Return(testCompany, nil)
+				m.repo.EXPECT().Delete(gomock.Any(), testAct.ID, testAct.Version).Return(errRepo)
+			},
+			wantErr: errRepo,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m := newActsService(t)
+			tt.setup(m)
+
+			checkErr(t, s.Delete(tt.ctx, testAct.ID, tt.version), tt.wantErr)
+		})
+	}
+}
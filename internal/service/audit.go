@@ -0,0 +1,26 @@
+package service
+
+import (
+	"context"
+
+	"foodsharing-backend/internal/domain"
+	"foodsharing-backend/internal/repository"
+)
+
+type AuditService struct {
+	repo repository.AuditLog
+}
+
+func NewAuditService(repo repository.AuditLog) *AuditService {
+	return &AuditService{repo: repo}
+}
+
+// GetAll shows the log to admins only, it holds the records of every user.
+func (s *AuditService) GetAll(ctx context.Context, opts domain.ListOptions) ([]domain.AuditEntry, string, error) {
+	if _, err := authorize(ctx, domain.Admin); err != nil {
+		return nil, "", err
+	}
+	opts.Restriction = nil
+	opts.Deleted = false
+	return s.repo.GetAll(ctx, opts)
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"foodsharing-backend/internal/domain"
+	mock_repository "foodsharing-backend/internal/repository/mocks"
+
+	"github.com/golang/mock/gomock"
+)
+
+func TestAuditServiceGetAll(t *testing.T) {
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		setup   func(repo *mock_repository.MockAuditLog)
+		wantErr error
+	}{
+		{
+			name: "admin",
+			ctx:  asUser(1, domain.Admin),
+			setup: func(repo *mock_repository.MockAuditLog) {
+				repo.EXPECT().GetAll(gomock.Any(), domain.ListOptions{}).
+					Return([]domain.AuditEntry{{ID: 1, Action: domain.AuditCreate}}, "", nil)
+			},
+		},
+		{
+			name:    "editor of everything but admin",
+			ctx:     asUser(1, domain.EditUser, domain.EditGroup, domain.EditAct),
+			setup:   func(repo *mock_repository.MockAuditLog) {},
+			wantErr: domain.Forbidden,
+		},
+		{
+			name:    "anonymous",
+			ctx:     context.Background(),
+			setup:   func(repo *mock_repository.MockAuditLog) {},
+			wantErr: domain.Unauthorized,
+		},
+		{
+			name: "repository error",
+			ctx:  asUser(1, domain.Admin),
+			setup: func(repo *mock_repository.MockAuditLog) {
+				repo.EXPECT().GetAll(gomock.Any(), gomock.Any()).Return(nil, "", errRepo)
+			},
+			wantErr: errRepo,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mock_repository.NewMockAuditLog(gomock.NewController(t))
+			tt.setup(repo)
+
+			_, _, err := NewAuditService(repo).GetAll(tt.ctx, domain.ListOptions{
+				Restriction: &domain.ListRestriction{OwnerID: 1},
+			})
+			checkErr(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,413 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"foodsharing-backend/internal/domain"
+	"foodsharing-backend/internal/repository"
+	"foodsharing-backend/pkg/auth"
+	"foodsharing-backend/pkg/email"
+	"foodsharing-backend/pkg/hash"
+
+	"github.com/google/uuid"
+)
+
+const minPasswordLength = 8
+
+type AuthConfig struct {
+	AccessTokenTTL       time.Duration
+	RefreshTokenTTL      time.Duration
+	PasswordResetTTL     time.Duration
+	EmailVerificationTTL time.Duration
+	// TwoFactorPermissions are the permissions whose holders must use two-factor authentication.
+	TwoFactorPermissions domain.Permissions
+	TwoFactorIssuer      string
+	Lockout              LockoutConfig
+	PasswordResetLimit   PasswordResetLimitConfig
+	// LinkBaseURL is the address of the frontend that links in emails point to.
+	LinkBaseURL string
+}
+
+type AuthService struct {
+	users       repository.Users
+	sessions    repository.Sessions
+	credentials repository.Credentials
+	userTokens  repository.UserTokens
+	twoFactor   repository.TwoFactor
+
+	loginAttempts repository.LoginAttempts
+	txManager     repository.TxManager
+
+	authorizer     Authorizer
+	tokenManager   auth.TokenManager
+	passwordHasher hash.PasswordHasher
+	mailer         email.Mailer
+	cfg            AuthConfig
+
+	// resetEmails sends the password reset emails after the response is written.
+	resetEmails *backgroundQueue
+}
+
+func NewAuthService(repos *repository.Repositories, authorizer Authorizer, tokenManager auth.TokenManager,
+	passwordHasher hash.PasswordHasher, mailer email.Mailer, cfg AuthConfig) *AuthService {
+	return &AuthService{
+		users:          repos.Users,
+		sessions:       repos.Sessions,
+		credentials:    repos.Credentials,
+		userTokens:     repos.UserTokens,
+		twoFactor:      repos.TwoFactor,
+		loginAttempts:  repos.LoginAttempts,
+		txManager:      repos.TxManager,
+		authorizer:     authorizer,
+		tokenManager:   tokenManager,
+		passwordHasher: passwordHasher,
+		mailer:         mailer,
+		cfg:            cfg,
+		resetEmails:    newBackgroundQueue(passwordResetWorkers, passwordResetQueueSize, passwordResetTimeout),
+	}
+}
+
+// Stop waits for the password reset emails that are still queued.
+func (s *AuthService) Stop(ctx context.Context) error {
+	return s.resetEmails.stop(ctx)
+}
+
+func (i SignUpInput) validate() error {
+	if err := i.UserInput.validate(); err != nil {
+		return err
+	}
+	return validatePassword(i.Password)
+}
+
+func (i ChangePasswordInput) validate() error {
+	if i.CurrentPassword == "" {
+		return fmt.Errorf("%w: current_password is required", domain.InvalidInput)
+	}
+	return validatePassword(i.NewPassword)
+}
+
+func validatePassword(password string) error {
+	if len([]rune(password)) < minPasswordLength {
+		return fmt.Errorf("%w: password must be at least %d characters long", domain.InvalidInput,
+			minPasswordLength)
+	}
+	return nil
+}
+
+// SignUp registers a user without any groups and signs them in.
+func (s *AuthService) SignUp(ctx context.Context, input SignUpInput, device DeviceInfo) (Tokens, error) {
+	if err := input.validate(); err != nil {
+		return Tokens{}, err
+	}
+
+	passwordHash, err := s.passwordHasher.Hash(input.Password)
+	if err != nil {
+		return Tokens{}, err
+	}
+
+	user := input.UserInput.toDomain()
+	err = s.txManager.WithinTx(ctx, func(repos *repository.Repositories) error {
+		if err := repos.Users.Create(ctx, &user); err != nil {
+			return err
+		}
+		return repos.Credentials.Set(ctx, user.ID, passwordHash)
+	})
+	if err != nil {
+		return Tokens{}, err
+	}
+
+	if err := s.sendVerificationEmail(ctx, user); err != nil {
+		log.Printf("cannot send verification email to user %d: %v", user.ID, err)
+	}
+
+	return s.CreateSession(ctx, user.ID, device)
+}
+
+// SignIn checks the password and creates a session unless the user has to pass the
+// second factor first. Failed attempts are counted towards the lockout of both the
+// email and the address of the client.
+func (s *AuthService) SignIn(ctx context.Context, input SignInInput, device DeviceInfo) (SignInResult, error) {
+	if err := s.checkLockout(ctx, input.Email, device.IP); err != nil {
+		return SignInResult{}, err
+	}
+
+	user, err := s.checkPassword(ctx, input.Email, input.Password)
+	if err != nil {
+		if errors.Is(err, domain.Unauthorized) {
+			if err := s.registerLoginFailure(ctx, input.Email, device.IP); err != nil {
+				return SignInResult{}, err
+			}
+		}
+		return SignInResult{}, err
+	}
+
+	principal, err := s.Identify(ctx, user.ID)
+	if err != nil {
+		return SignInResult{}, err
+	}
+
+	twoFactor, err := s.twoFactor.GetByUserID(ctx, user.ID)
+	if err != nil && !errors.Is(err, domain.NotFound) {
+		return SignInResult{}, err
+	}
+
+	if !twoFactor.IsEnabled() && !s.requiresTwoFactor(principal) {
+		if err := s.resetLoginFailures(ctx, user.Email); err != nil {
+			return SignInResult{}, err
+		}
+
+		tokens, err := s.CreateSession(ctx, user.ID, device)
+		return SignInResult{Tokens: tokens}, err
+	}
+
+	twoFactorToken, err := s.tokenManager.NewTwoFactorToken(uint64(user.ID), twoFactorTokenTTL)
+	if err != nil {
+		return SignInResult{}, fmt.Errorf("cannot create two-factor token: %w", err)
+	}
+
+	return SignInResult{
+		TwoFactorToken:              twoFactorToken,
+		TwoFactorEnrollmentRequired: !twoFactor.IsEnabled(),
+	}, nil
+}
+
+func (s *AuthService) ChangePassword(ctx context.Context, input ChangePasswordInput) error {
+	principal, ok := domain.PrincipalFromContext(ctx)
+	if !ok {
+		return domain.Unauthorized
+	}
+	if err := input.validate(); err != nil {
+		return err
+	}
+
+	user, err := s.users.GetByID(ctx, principal.UserID)
+	if err != nil {
+		return err
+	}
+	if _, err := s.checkPassword(ctx, user.Email, input.CurrentPassword); err != nil {
+		return err
+	}
+
+	passwordHash, err := s.passwordHasher.Hash(input.NewPassword)
+	if err != nil {
+		return err
+	}
+	return s.txManager.WithinTx(ctx, func(repos *repository.Repositories) error {
+		if err := repos.Credentials.Set(ctx, user.ID, passwordHash); err != nil {
+			return err
+		}
+		return repos.Sessions.DeleteByUserID(ctx, user.ID)
+	})
+}
+
+// checkPassword returns the user with the given email if the password matches. Hashes
+// made with outdated cost parameters are transparently replaced. Unknown emails cost the
+// same hashing work as wrong passwords so they cannot be told apart by timing.
+func (s *AuthService) checkPassword(ctx context.Context, email, password string) (domain.User, error) {
+	invalid := fmt.Errorf("%w: invalid email or password", domain.Unauthorized)
+
+	user, err := s.users.GetByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, domain.NotFound) {
+			_, _ = s.passwordHasher.Hash(password)
+			return domain.User{}, invalid
+		}
+		return domain.User{}, err
+	}
+
+	credentials, err := s.credentials.GetByUserID(ctx, user.ID)
+	if err != nil {
+		if errors.Is(err, domain.NotFound) {
+			_, _ = s.passwordHasher.Hash(password)
+			return domain.User{}, invalid
+		}
+		return domain.User{}, err
+	}
+
+	ok, needsRehash, err := s.passwordHasher.Verify(password, credentials.PasswordHash)
+	if err != nil {
+		return domain.User{}, fmt.Errorf("cannot verify password of user %d: %w", user.ID, err)
+	}
+	if !ok {
+		return domain.User{}, invalid
+	}
+
+	if needsRehash {
+		if passwordHash, err := s.passwordHasher.Hash(password); err != nil {
+			log.Printf("cannot rehash password of user %d: %v", user.ID, err)
+		} else if err := s.credentials.Set(ctx, user.ID, passwordHash); err != nil {
+			log.Printf("cannot store rehashed password of user %d: %v", user.ID, err)
+		}
+	}
+
+	return user, nil
+}
+
+func (s *AuthService) Identify(ctx context.Context, userID domain.ID) (domain.Principal, error) {
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return domain.Principal{}, err
+	}
+
+	return s.authorizer.Identify(ctx, user)
+}
+
+// CreateSession starts a new session of the user and returns its tokens.
+func (s *AuthService) CreateSession(ctx context.Context, userID domain.ID, device DeviceInfo) (Tokens, error) {
+	principal, err := s.Identify(ctx, userID)
+	if err != nil {
+		return Tokens{}, err
+	}
+
+	refreshToken, err := s.tokenManager.NewRefreshToken()
+	if err != nil {
+		return Tokens{}, err
+	}
+
+	session := domain.Session{
+		UserID:       userID,
+		RefreshToken: refreshToken,
+		FamilyID:     uuid.New().String(),
+		UserAgent:    device.UserAgent,
+		IP:           device.IP,
+		ExpiresAt:    time.Now().Add(s.cfg.RefreshTokenTTL),
+	}
+	if err := s.sessions.Create(ctx, session); err != nil {
+		return Tokens{}, err
+	}
+
+	return s.issueTokens(principal, refreshToken)
+}
+
+// RefreshTokens exchanges a refresh token for a new pair of tokens. The presented token
+// is rotated, so presenting it again is treated as token theft and revokes the whole
+// family. Permissions are re-read so that group changes take effect on the next refresh.
+func (s *AuthService) RefreshTokens(ctx context.Context, refreshToken string, device DeviceInfo) (Tokens, error) {
+	session, err := s.sessions.GetByRefreshToken(ctx, refreshToken)
+	if err != nil {
+		if errors.Is(err, domain.NotFound) {
+			return Tokens{}, fmt.Errorf("%w: invalid refresh token", domain.Unauthorized)
+		}
+		return Tokens{}, err
+	}
+	if session.RotatedAt != nil {
+		return Tokens{}, s.revokeFamily(ctx, session)
+	}
+
+	principal, err := s.Identify(ctx, session.UserID)
+	if err != nil {
+		if errors.Is(err, domain.NotFound) {
+			return Tokens{}, fmt.Errorf("%w: user no longer exists", domain.Unauthorized)
+		}
+		return Tokens{}, err
+	}
+
+	nextToken, err := s.tokenManager.NewRefreshToken()
+	if err != nil {
+		return Tokens{}, err
+	}
+
+	next := domain.Session{
+		UserID:       session.UserID,
+		RefreshToken: nextToken,
+		FamilyID:     session.FamilyID,
+		UserAgent:    device.UserAgent,
+		IP:           device.IP,
+		ExpiresAt:    time.Now().Add(s.cfg.RefreshTokenTTL),
+	}
+	if err := s.sessions.Rotate(ctx, refreshToken, next); err != nil {
+		if errors.Is(err, domain.NotFound) {
+			// Someone else rotated the token between the lookup and the rotation.
+			return Tokens{}, s.revokeFamily(ctx, session)
+		}
+		return Tokens{}, err
+	}
+
+	return s.issueTokens(principal, nextToken)
+}
+
+// revokeFamily removes every session descending from the same sign-in as the reused
+// token and reports the refresh as unauthorized.
+func (s *AuthService) revokeFamily(ctx context.Context, session domain.Session) error {
+	if err := s.sessions.DeleteFamily(ctx, session.UserID, session.FamilyID); err != nil &&
+		!errors.Is(err, domain.NotFound) {
+		return err
+	}
+
+	log.Printf("refresh token reuse detected for user %d, session family %s revoked", session.UserID,
+		session.FamilyID)
+	return fmt.Errorf("%w: refresh token has already been used", domain.Unauthorized)
+}
+
+func (s *AuthService) Authenticate(_ context.Context, accessToken string) (domain.Principal, error) {
+	claims, err := s.tokenManager.Parse(accessToken)
+	if err != nil {
+		return domain.Principal{}, fmt.Errorf("%w: %v", domain.Unauthorized, err)
+	}
+
+	userID, err := claims.UserID()
+	if err != nil {
+		return domain.Principal{}, fmt.Errorf("%w: invalid subject", domain.Unauthorized)
+	}
+
+	principal := domain.Principal{
+		UserID:         domain.ID(userID),
+		Permissions:    domain.PermissionsFromNames(claims.Permissions.All),
+		OwnPermissions: domain.PermissionsFromNames(claims.Permissions.Own),
+	}
+	for cityID, permissions := range claims.Permissions.City {
+		principal.Grant(domain.PermissionsFromNames(permissions), domain.ScopeCity, domain.ID(cityID))
+	}
+	return principal, nil
+}
+
+func (s *AuthService) GetSessions(ctx context.Context, userID domain.ID) ([]domain.Session, error) {
+	if _, err := authorizeSelfOr(ctx, userID, domain.EditUser); err != nil {
+		return nil, err
+	}
+	return s.sessions.GetByUserID(ctx, userID)
+}
+
+func (s *AuthService) RevokeSession(ctx context.Context, userID domain.ID, sessionID string) error {
+	if _, err := authorizeSelfOr(ctx, userID, domain.EditUser); err != nil {
+		return err
+	}
+	if _, err := uuid.Parse(sessionID); err != nil {
+		return fmt.Errorf("%w: invalid session id", domain.InvalidInput)
+	}
+	return s.sessions.DeleteFamily(ctx, userID, sessionID)
+}
+
+func (s *AuthService) RevokeAllSessions(ctx context.Context, userID domain.ID) error {
+	if _, err := authorizeSelfOr(ctx, userID, domain.EditUser); err != nil {
+		return err
+	}
+	return s.sessions.DeleteByUserID(ctx, userID)
+}
+
+func (s *AuthService) issueTokens(principal domain.Principal, refreshToken string) (Tokens, error) {
+	permissions := auth.Permissions{
+		All: principal.Permissions.Names(),
+		Own: principal.OwnPermissions.Names(),
+	}
+	if len(principal.CityPermissions) > 0 {
+		permissions.City = make(map[uint64][]string, len(principal.CityPermissions))
+		for cityID, cityPermissions := range principal.CityPermissions {
+			permissions.City[uint64(cityID)] = cityPermissions.Names()
+		}
+	}
+
+	accessToken, err := s.tokenManager.NewJWT(uint64(principal.UserID), permissions, s.cfg.AccessTokenTTL)
+	if err != nil {
+		return Tokens{}, fmt.Errorf("cannot create access token: %w", err)
+	}
+
+	return Tokens{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	}, nil
+}
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"foodsharing-backend/internal/domain"
+	"foodsharing-backend/internal/repository"
+	"foodsharing-backend/pkg/auth"
+	"foodsharing-backend/pkg/email"
+)
+
+func (i ResetPasswordInput) validate() error {
+	if i.Token == "" {
+		return fmt.Errorf("%w: token is required", domain.InvalidInput)
+	}
+	return validatePassword(i.Password)
+}
+
+// PasswordResetLimitConfig limits password reset requests to MaxAccountRequests per email
+// and MaxIPRequests per address within Window.
+type PasswordResetLimitConfig struct {
+	MaxAccountRequests int
+	MaxIPRequests      int
+	Window             time.Duration
+}
+
+// The password reset emails are sent by a few workers, passwordResetTimeout bounds each.
+const (
+	passwordResetWorkers   = 4
+	passwordResetQueueSize = 100
+	passwordResetTimeout   = 30 * time.Second
+)
+
+// RequestPasswordReset looks the user up and mails the link in the background, so neither
+// the response time nor a failure to send tells whether the email is registered. Failures
+// are only logged.
+func (s *AuthService) RequestPasswordReset(ctx context.Context, address string, device DeviceInfo) error {
+	if err := s.throttlePasswordReset(ctx, address, device.IP); err != nil {
+		return err
+	}
+
+	queued := s.resetEmails.enqueue(func(ctx context.Context) {
+		if err := s.sendPasswordResetEmail(ctx, address); err != nil {
+			log.Printf("cannot send password reset email: %v", err)
+		}
+	})
+	if !queued {
+		log.Printf("cannot send password reset email: the queue is full")
+	}
+	return nil
+}
+
+// throttlePasswordReset counts the request against the email and the address in the
+// login attempts and returns a LockedError once either has asked too often. Unknown
+// emails are counted like registered ones, so the limit tells nothing about who is.
+func (s *AuthService) throttlePasswordReset(ctx context.Context, address, ip string) error {
+	limit := s.cfg.PasswordResetLimit
+	keys := []loginKey{{subject: domain.PasswordResetAccount, key: normalizeEmail(address)}}
+	if ip != "" {
+		keys = append(keys, loginKey{subject: domain.PasswordResetIP, key: ip})
+	}
+
+	var lockedUntil time.Time
+	for _, k := range keys {
+		attempts, err := s.loginAttempts.AddFailure(ctx, k.subject, k.key, ip, limit.Window)
+		if err != nil {
+			return err
+		}
+
+		maxRequests := limit.MaxAccountRequests
+		if k.subject == domain.PasswordResetIP {
+			maxRequests = limit.MaxIPRequests
+		}
+		until := attempts.LastFailureAt.Add(limit.Window)
+		if attempts.Failures > maxRequests && until.After(lockedUntil) {
+			lockedUntil = until
+		}
+	}
+
+	if !lockedUntil.IsZero() {
+		return domain.LockedError{Until: lockedUntil}
+	}
+	return nil
+}
+
+func (s *AuthService) sendPasswordResetEmail(ctx context.Context, address string) error {
+	user, err := s.users.GetByEmail(ctx, address)
+	if err != nil {
+		if errors.Is(err, domain.NotFound) {
+			return nil
+		}
+		return err
+	}
+
+	token, err := s.newUserToken(ctx, user, domain.PasswordReset, s.cfg.PasswordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	if err := s.mailer.Send(ctx, email.Message{
+		To:      user.Email,
+		Subject: "Password reset",
+		Body: fmt.Sprintf("Hello, %s!\n\nSomeone asked to reset the password of your account. If it was you, "+
+			"follow the link below within %s to set a new password:\n\n%s\n\nOtherwise just ignore this email.",
+			user.Name, s.cfg.PasswordResetTTL, s.link("reset-password", token)),
+	}); err != nil {
+		return fmt.Errorf("user %d: %w", user.ID, err)
+	}
+	return nil
+}
+
+// ResetPassword sets a new password using a token from a password reset email and signs
+// the user out everywhere. Getting the email also proves the user owns the address.
+func (s *AuthService) ResetPassword(ctx context.Context, input ResetPasswordInput) error {
+	if err := input.validate(); err != nil {
+		return err
+	}
+
+	passwordHash, err := s.passwordHasher.Hash(input.Password)
+	if err != nil {
+		return err
+	}
+
+	// The token is only used up if the password is changed.
+	var token domain.UserToken
+	err = s.txManager.WithinTx(ctx, func(repos *repository.Repositories) error {
+		var err error
+		token, err = repos.UserTokens.Consume(ctx, domain.PasswordReset, input.Token)
+		if err != nil {
+			if errors.Is(err, domain.NotFound) {
+				return fmt.Errorf("%w: invalid or expired token", domain.InvalidInput)
+			}
+			return err
+		}
+
+		if err := repos.Credentials.Set(ctx, token.UserID, passwordHash); err != nil {
+			return err
+		}
+		if err := repos.Sessions.DeleteByUserID(ctx, token.UserID); err != nil {
+			return err
+		}
+		if err := repos.Users.VerifyEmail(ctx, token.UserID, token.Email); err != nil && !errors.Is(err, domain.NotFound) {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.resetLoginFailures(ctx, token.Email)
+}
+
+func (s *AuthService) RequestEmailVerification(ctx context.Context) error {
+	principal, ok := domain.PrincipalFromContext(ctx)
+	if !ok {
+		return domain.Unauthorized
+	}
+
+	user, err := s.users.GetByID(ctx, principal.UserID)
+	if err != nil {
+		return err
+	}
+	if user.IsEmailVerified() {
+		return fmt.Errorf("%w: email is already verified", domain.InvalidInput)
+	}
+
+	return s.sendVerificationEmail(ctx, user)
+}
+
+func (s *AuthService) VerifyEmail(ctx context.Context, token string) error {
+	if token == "" {
+		return fmt.Errorf("%w: token is required", domain.InvalidInput)
+	}
+
+	userToken, err := s.userTokens.Consume(ctx, domain.EmailVerification, token)
+	if err != nil {
+		if errors.Is(err, domain.NotFound) {
+			return fmt.Errorf("%w: invalid or expired token", domain.InvalidInput)
+		}
+		return err
+	}
+
+	if err := s.users.VerifyEmail(ctx, userToken.UserID, userToken.Email); err != nil {
+		if errors.Is(err, domain.NotFound) {
+			return fmt.Errorf("%w: email has been changed since the token was sent", domain.InvalidInput)
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *AuthService) sendVerificationEmail(ctx context.Context, user domain.User) error {
+	token, err := s.newUserToken(ctx, user, domain.EmailVerification, s.cfg.EmailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, email.Message{
+		To:      user.Email,
+		Subject: "Email verification",
+		Body: fmt.Sprintf("Hello, %s!\n\nPlease confirm your email by following the link below within %s:"+
+			"\n\n%s", user.Name, s.cfg.EmailVerificationTTL, s.link("verify-email", token)),
+	})
+}
+
+// newUserToken replaces all pending tokens of the user with the same purpose by a new one.
+func (s *AuthService) newUserToken(ctx context.Context, user domain.User, purpose domain.TokenPurpose,
+	ttl time.Duration) (string, error) {
+	if err := s.userTokens.DeleteByUserID(ctx, user.ID, purpose); err != nil {
+		return "", err
+	}
+
+	token, err := auth.NewOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.userTokens.Create(ctx, domain.UserToken{
+		Token:     token,
+		UserID:    user.ID,
+		Purpose:   purpose,
+		Email:     user.Email,
+		ExpiresAt: time.Now().Add(ttl),
+	}); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+func (s *AuthService) link(path, token string) string {
+	return fmt.Sprintf("%s/%s?token=%s", strings.TrimSuffix(s.cfg.LinkBaseURL, "/"), path, url.QueryEscape(token))
+}
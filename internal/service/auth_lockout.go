@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"foodsharing-backend/internal/domain"
+)
+
+// LockoutConfig limits failed sign-ins. After MaxAccountFailures failures with the same
+// email or MaxIPFailures from the same address, sign-ins are blocked for BaseLockout,
+// which doubles with every further failure up to MaxLockout. Failures are forgotten
+// after FailureWindow passes without new ones.
+type LockoutConfig struct {
+	MaxAccountFailures int
+	MaxIPFailures      int
+	BaseLockout        time.Duration
+	MaxLockout         time.Duration
+	FailureWindow      time.Duration
+}
+
+type loginKey struct {
+	subject domain.LoginSubject
+	key     string
+}
+
+func loginKeys(email, ip string) []loginKey {
+	keys := []loginKey{{subject: domain.LoginAccount, key: normalizeEmail(email)}}
+	if ip != "" {
+		keys = append(keys, loginKey{subject: domain.LoginIP, key: ip})
+	}
+	return keys
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func (s *AuthService) GetLockout(ctx context.Context, userID domain.ID) (domain.LoginAttempts, error) {
+	if _, err := authorize(ctx, domain.EditUser); err != nil {
+		return domain.LoginAttempts{}, err
+	}
+
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return domain.LoginAttempts{}, err
+	}
+
+	attempts, err := s.loginAttempts.Get(ctx, domain.LoginAccount, normalizeEmail(user.Email))
+	if errors.Is(err, domain.NotFound) {
+		return domain.LoginAttempts{Subject: domain.LoginAccount, Key: normalizeEmail(user.Email)}, nil
+	}
+	return attempts, err
+}
+
+// Unlock also lifts the lock of the address the last failure of the account came from,
+// otherwise the user could stay locked out when signing in from there.
+func (s *AuthService) Unlock(ctx context.Context, userID domain.ID) error {
+	if _, err := authorize(ctx, domain.EditUser); err != nil {
+		return err
+	}
+
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	attempts, err := s.loginAttempts.Get(ctx, domain.LoginAccount, normalizeEmail(user.Email))
+	if err != nil {
+		if errors.Is(err, domain.NotFound) {
+			return nil
+		}
+		return err
+	}
+	if err := s.resetLoginFailures(ctx, user.Email); err != nil {
+		return err
+	}
+	if attempts.LastIP == "" {
+		return nil
+	}
+	return s.loginAttempts.Reset(ctx, domain.LoginIP, attempts.LastIP)
+}
+
+// checkLockout returns a LockedError if sign-ins with the email or from the address are
+// blocked. Passwords are not even checked then, so guessing goes on no matter the answer.
+func (s *AuthService) checkLockout(ctx context.Context, email, ip string) error {
+	now := time.Now()
+
+	var lockedUntil time.Time
+	for _, k := range loginKeys(email, ip) {
+		attempts, err := s.loginAttempts.Get(ctx, k.subject, k.key)
+		if err != nil {
+			if errors.Is(err, domain.NotFound) {
+				continue
+			}
+			return err
+		}
+		if attempts.IsLocked(now) && attempts.LockedUntil.After(lockedUntil) {
+			lockedUntil = *attempts.LockedUntil
+		}
+	}
+
+	if !lockedUntil.IsZero() {
+		return domain.LockedError{Until: lockedUntil}
+	}
+	return nil
+}
+
+// registerLoginFailure counts a failed sign-in and locks the email or the address once
+// they have failed too often.
+func (s *AuthService) registerLoginFailure(ctx context.Context, email, ip string) error {
+	for _, k := range loginKeys(email, ip) {
+		attempts, err := s.loginAttempts.AddFailure(ctx, k.subject, k.key, ip, s.cfg.Lockout.FailureWindow)
+		if err != nil {
+			return err
+		}
+
+		maxFailures := s.cfg.Lockout.MaxAccountFailures
+		if k.subject == domain.LoginIP {
+			maxFailures = s.cfg.Lockout.MaxIPFailures
+		}
+		if attempts.Failures < maxFailures {
+			continue
+		}
+
+		until := time.Now().Add(s.lockoutDuration(attempts.Failures - maxFailures))
+		if err := s.loginAttempts.Lock(ctx, k.subject, k.key, until); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resetLoginFailures forgets the failures of the account once the user has proved who
+// they are. Failures of the address are kept, one known password must not help to
+// guess the others.
+func (s *AuthService) resetLoginFailures(ctx context.Context, email string) error {
+	return s.loginAttempts.Reset(ctx, domain.LoginAccount, normalizeEmail(email))
+}
+
+func (s *AuthService) lockoutDuration(excessFailures int) time.Duration {
+	lockout := s.cfg.Lockout.BaseLockout
+	for i := 0; i < excessFailures && lockout < s.cfg.Lockout.MaxLockout; i++ {
+		lockout *= 2
+	}
+
+	if lockout > s.cfg.Lockout.MaxLockout {
+		return s.cfg.Lockout.MaxLockout
+	}
+	return lockout
+}
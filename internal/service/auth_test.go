@@ -0,0 +1,389 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"foodsharing-backend/internal/domain"
+	"foodsharing-backend/internal/repository"
+	mock_repository "foodsharing-backend/internal/repository/mocks"
+	"foodsharing-backend/pkg/auth"
+	"foodsharing-backend/pkg/hash"
+
+	"github.com/golang/mock/gomock"
+)
+
+const testPassword = "correct horse battery staple"
+
+type authMocks struct {
+	users         *mock_repository.MockUsers
+	groups        *mock_repository.MockGroups
+	sessions      *mock_repository.MockSessions
+	credentials   *mock_repository.MockCredentials
+	twoFactor     *mock_repository.MockTwoFactor
+	loginAttempts *mock_repository.MockLoginAttempts
+}
+
+var testLockout = LockoutConfig{
+	MaxAccountFailures: 5,
+	MaxIPFailures:      20,
+	BaseLockout:        time.Minute,
+	MaxLockout:         time.Hour,
+	FailureWindow:      time.Hour,
+}
+
+var testPasswordResetLimit = PasswordResetLimitConfig{
+	MaxAccountRequests: 3,
+	MaxIPRequests:      20,
+	Window:             time.Hour,
+}
+
+func newAuthService(t *testing.T) (*AuthService, authMocks) {
+	ctrl := gomock.NewController(t)
+	m := authMocks{
+		users:         mock_repository.NewMockUsers(ctrl),
+		groups:        mock_repository.NewMockGroups(ctrl),
+		sessions:      mock_repository.NewMockSessions(ctrl),
+		credentials:   mock_repository.NewMockCredentials(ctrl),
+		twoFactor:     mock_repository.NewMockTwoFactor(ctrl),
+		loginAttempts: mock_repository.NewMockLoginAttempts(ctrl),
+	}
+
+	tokenManager, err := auth.NewManager("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hasher, err := hash.NewArgon2Hasher(hash.Argon2Params{Memory: 64, Iterations: 1, Parallelism: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repos := &repository.Repositories{
+		Users:         m.users,
+		Groups:        m.groups,
+		Sessions:      m.sessions,
+		Credentials:   m.credentials,
+		TwoFactor:     m.twoFactor,
+		LoginAttempts: m.loginAttempts,
+	}
+	cfg := AuthConfig{
+		AccessTokenTTL:     time.Minute,
+		RefreshTokenTTL:    time.Hour,
+		Lockout:            testLockout,
+		PasswordResetLimit: testPasswordResetLimit,
+	}
+	return NewAuthService(repos, NewAuthorizationService(m.groups), tokenManager, hasher, nil, cfg), m
+}
+
+func testCredentials(t *testing.T, s *AuthService) domain.Credentials {
+	t.Helper()
+	passwordHash, err := s.passwordHasher.Hash(testPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return domain.Credentials{UserID: testUser.ID, PasswordHash: passwordHash}
+}
+
+func TestAuthServiceSignIn(t *testing.T) {
+	device := DeviceInfo{UserAgent: "test", IP: "192.0.2.1"}
+	notLocked := func(m authMocks) {
+		m.loginAttempts.EXPECT().Get(gomock.Any(), domain.LoginAccount, testUser.Email).
+			Return(domain.LoginAttempts{}, domain.NotFound)
+		m.loginAttempts.EXPECT().Get(gomock.Any(), domain.LoginIP, device.IP).
+			Return(domain.LoginAttempts{}, domain.NotFound)
+	}
+	failures := func(m authMocks, account int) {
+		m.loginAttempts.EXPECT().AddFailure(gomock.Any(), domain.LoginAccount, testUser.Email, device.IP,
+			testLockout.FailureWindow).Return(domain.LoginAttempts{Failures: account}, nil)
+		m.loginAttempts.EXPECT().AddFailure(gomock.Any(), domain.LoginIP, device.IP, device.IP, testLockout.FailureWindow).
+			Return(domain.LoginAttempts{Failures: 1}, nil)
+	}
+
+	tests := []struct {
+		name     string
+		password string
+		setup    func(t *testing.T, s *AuthService, m authMocks)
+		wantErr  error
+	}{
+		{
+			name:     "success",
+			password: testPassword,
+			setup: func(t *testing.T, s *AuthService, m authMocks) {
+				notLocked(m)
+				m.users.EXPECT().GetByEmail(gomock.Any(), testUser.Email).Return(testUser, nil)
+				m.credentials.EXPECT().GetByUserID(gomock.Any(), testUser.ID).Return(testCredentials(t, s), nil)
+				m.users.EXPECT().GetByID(gomock.Any(), testUser.ID).Return(testUser, nil).Times(2)
+				m.groups.EXPECT().GetUserGroups(gomock.Any(), testUser.ID).Return(nil, nil).Times(2)
+				m.twoFactor.EXPECT().GetByUserID(gomock.Any(), testUser.ID).
+					Return(domain.TwoFactor{}, domain.NotFound)
+				m.loginAttempts.EXPECT().Reset(gomock.Any(), domain.LoginAccount, testUser.Email).Return(nil)
+				m.sessions.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:     "second factor enabled",
+			password: testPassword,
+			setup: func(t *testing.T, s *AuthService, m authMocks) {
+				enabledAt := time.Now()
+				notLocked(m)
+				m.users.EXPECT().GetByEmail(gomock.Any(), testUser.Email).Return(testUser, nil)
+				m.credentials.EXPECT().GetByUserID(gomock.Any(), testUser.ID).Return(testCredentials(t, s), nil)
+				m.users.EXPECT().GetByID(gomock.Any(), testUser.ID).Return(testUser, nil)
+				m.groups.EXPECT().GetUserGroups(gomock.Any(), testUser.ID).Return(nil, nil)
+				m.twoFactor.EXPECT().GetByUserID(gomock.Any(), testUser.ID).
+					Return(domain.TwoFactor{UserID: testUser.ID, EnabledAt: &enabledAt}, nil)
+			},
+		},
+		{
+			name:     "wrong password",
+			password: "wrong password",
+			setup: func(t *testing.T, s *AuthService, m authMocks) {
+				notLocked(m)
+				m.users.EXPECT().GetByEmail(gomock.Any(), testUser.Email).Return(testUser, nil)
+				m.credentials.EXPECT().GetByUserID(gomock.Any(), testUser.ID).Return(testCredentials(t, s), nil)
+				failures(m, 1)
+			},
+			wantErr: domain.Unauthorized,
+		},
+		{
+			name:     "wrong password locks the account",
+			password: "wrong password",
+			setup: func(t *testing.T, s *AuthService, m authMocks) {
+				notLocked(m)
+				m.users.EXPECT().GetByEmail(gomock.Any(), testUser.Email).Return(testUser, nil)
+				m.credentials.EXPECT().GetByUserID(gomock.Any(), testUser.ID).Return(testCredentials(t, s), nil)
+				failures(m, testLockout.MaxAccountFailures)
+				m.loginAttempts.EXPECT().Lock(gomock.Any(), domain.LoginAccount, testUser.Email, gomock.Any()).
+					Return(nil)
+			},
+			wantErr: domain.Unauthorized,
+		},
+		{
+			name:     "unknown email",
+			password: testPassword,
+			setup: func(t *testing.T, s *AuthService, m authMocks) {
+				notLocked(m)
+				m.users.EXPECT().GetByEmail(gomock.Any(), testUser.Email).Return(domain.User{}, domain.NotFound)
+				failures(m, 1)
+			},
+			wantErr: domain.Unauthorized,
+		},
+		{
+			name:     "locked account",
+			password: testPassword,
+			setup: func(t *testing.T, s *AuthService, m authMocks) {
+				until := time.Now().Add(time.Minute)
+				m.loginAttempts.EXPECT().Get(gomock.Any(), domain.LoginAccount, testUser.Email).
+					Return(domain.LoginAttempts{Failures: 5, LockedUntil: &until}, nil)
+				m.loginAttempts.EXPECT().Get(gomock.Any(), domain.LoginIP, device.IP).
+					Return(domain.LoginAttempts{}, domain.NotFound)
+			},
+			wantErr: domain.TooManyAttempts,
+		},
+		{
+			name:     "repository error",
+			password: testPassword,
+			setup: func(t *testing.T, s *AuthService, m authMocks) {
+				notLocked(m)
+				m.users.EXPECT().GetByEmail(gomock.Any(), testUser.Email).Return(domain.User{}, errRepo)
+			},
+			wantErr: errRepo,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m := newAuthService(t)
+			tt.setup(t, s, m)
+
+			result, err := s.SignIn(context.Background(),
+				SignInInput{Email: testUser.Email, Password: tt.password}, device)
+			checkErr(t, err, tt.wantErr)
+			if tt.wantErr == nil && result.AccessToken == "" && result.TwoFactorToken == "" {
+				t.Errorf("SignIn() = %+v, want tokens", result)
+			}
+		})
+	}
+}
+
+func TestAuthServiceRequestPasswordReset(t *testing.T) {
+	device := DeviceInfo{IP: "192.0.2.1"}
+	requests := func(m authMocks, account, ip int) {
+		now := time.Now()
+		m.loginAttempts.EXPECT().AddFailure(gomock.Any(), domain.PasswordResetAccount, testUser.Email, device.IP,
+			testPasswordResetLimit.Window).Return(domain.LoginAttempts{Failures: account, LastFailureAt: now}, nil)
+		m.loginAttempts.EXPECT().AddFailure(gomock.Any(), domain.PasswordResetIP, device.IP, device.IP,
+			testPasswordResetLimit.Window).Return(domain.LoginAttempts{Failures: ip, LastFailureAt: now}, nil)
+	}
+
+	tests := []struct {
+		name    string
+		setup   func(m authMocks)
+		wantErr error
+	}{
+		{
+			name: "unknown email",
+			setup: func(m authMocks) {
+				requests(m, 1, 1)
+				m.users.EXPECT().GetByEmail(gomock.Any(), testUser.Email).Return(domain.User{}, domain.NotFound)
+			},
+		},
+		{
+			name: "too many requests for the email",
+			setup: func(m authMocks) {
+				requests(m, testPasswordResetLimit.MaxAccountRequests+1, 1)
+			},
+			wantErr: domain.TooManyAttempts,
+		},
+		{
+			name: "too many requests from the address",
+			setup: func(m authMocks) {
+				requests(m, 1, testPasswordResetLimit.MaxIPRequests+1)
+			},
+			wantErr: domain.TooManyAttempts,
+		},
+		{
+			name: "repository error",
+			setup: func(m authMocks) {
+				m.loginAttempts.EXPECT().AddFailure(gomock.Any(), domain.PasswordResetAccount, testUser.Email, device.IP,
+					testPasswordResetLimit.Window).Return(domain.LoginAttempts{}, errRepo)
+			},
+			wantErr: errRepo,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m := newAuthService(t)
+			tt.setup(m)
+
+			checkErr(t, s.RequestPasswordReset(context.Background(), testUser.Email, device), tt.wantErr)
+			// Stopping waits for the email, so its expectations are checked too.
+			if err := s.Stop(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestAuthServiceConfirmTwoFactor(t *testing.T) {
+	device := DeviceInfo{IP: "192.0.2.1"}
+	pending := domain.TwoFactor{UserID: testUser.ID, Secret: "JBSWY3DPEHPK3PXP"}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		setup   func(m authMocks)
+		wantErr error
+	}{
+		{
+			name: "wrong code counts as a failure",
+			ctx:  asUser(testUser.ID),
+			setup: func(m authMocks) {
+				m.twoFactor.EXPECT().GetByUserID(gomock.Any(), testUser.ID).Return(pending, nil)
+				m.users.EXPECT().GetByID(gomock.Any(), testUser.ID).Return(testUser, nil)
+				m.loginAttempts.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(domain.LoginAttempts{}, domain.NotFound).Times(2)
+				m.loginAttempts.EXPECT().AddFailure(gomock.Any(), domain.LoginAccount, testUser.Email, device.IP,
+					testLockout.FailureWindow).Return(domain.LoginAttempts{Failures: 1}, nil)
+				m.loginAttempts.EXPECT().AddFailure(gomock.Any(), domain.LoginIP, device.IP, device.IP,
+					testLockout.FailureWindow).Return(domain.LoginAttempts{Failures: 1}, nil)
+			},
+			wantErr: domain.Unauthorized,
+		},
+		{
+			name: "locked account",
+			ctx:  asUser(testUser.ID),
+			setup: func(m authMocks) {
+				until := time.Now().Add(time.Minute)
+				m.twoFactor.EXPECT().GetByUserID(gomock.Any(), testUser.ID).Return(pending, nil)
+				m.users.EXPECT().GetByID(gomock.Any(), testUser.ID).Return(testUser, nil)
+				m.loginAttempts.EXPECT().Get(gomock.Any(), domain.LoginAccount, testUser.Email).
+					Return(domain.LoginAttempts{LockedUntil: &until}, nil)
+				m.loginAttempts.EXPECT().Get(gomock.Any(), domain.LoginIP, device.IP).
+					Return(domain.LoginAttempts{}, domain.NotFound)
+			},
+			wantErr: domain.TooManyAttempts,
+		},
+		{
+			name: "not started",
+			ctx:  asUser(testUser.ID),
+			setup: func(m authMocks) {
+				m.twoFactor.EXPECT().GetByUserID(gomock.Any(), testUser.ID).
+					Return(domain.TwoFactor{}, domain.NotFound)
+			},
+			wantErr: domain.InvalidInput,
+		},
+		{
+			name: "repository error",
+			ctx:  asUser(testUser.ID),
+			setup: func(m authMocks) {
+				m.twoFactor.EXPECT().GetByUserID(gomock.Any(), testUser.ID).Return(domain.TwoFactor{}, errRepo)
+			},
+			wantErr: errRepo,
+		},
+		{
+			name:    "anonymous",
+			ctx:     context.Background(),
+			setup:   func(m authMocks) {},
+			wantErr: domain.Unauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m := newAuthService(t)
+			tt.setup(m)
+
+			// A code of the wrong length never matches, whatever the time.
+			_, err := s.ConfirmTwoFactor(tt.ctx, "12345", device)
+			checkErr(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestAuthServiceUnlock(t *testing.T) {
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		setup   func(m authMocks)
+		wantErr error
+	}{
+		{
+			name: "lifts the lock of the last address too",
+			ctx:  asUser(1, domain.EditUser),
+			setup: func(m authMocks) {
+				m.users.EXPECT().GetByID(gomock.Any(), testUser.ID).Return(testUser, nil)
+				m.loginAttempts.EXPECT().Get(gomock.Any(), domain.LoginAccount, testUser.Email).
+					Return(domain.LoginAttempts{Failures: 5, LastIP: "192.0.2.1"}, nil)
+				m.loginAttempts.EXPECT().Reset(gomock.Any(), domain.LoginAccount, testUser.Email).Return(nil)
+				m.loginAttempts.EXPECT().Reset(gomock.Any(), domain.LoginIP, "192.0.2.1").Return(nil)
+			},
+		},
+		{
+			name: "no failures",
+			ctx:  asUser(1, domain.EditUser),
+			setup: func(m authMocks) {
+				m.users.EXPECT().GetByID(gomock.Any(), testUser.ID).Return(testUser, nil)
+				m.loginAttempts.EXPECT().Get(gomock.Any(), domain.LoginAccount, testUser.Email).
+					Return(domain.LoginAttempts{}, domain.NotFound)
+			},
+		},
+		{
+			name:    "without EditUser",
+			ctx:     asUser(1, domain.ReadUser),
+			setup:   func(m authMocks) {},
+			wantErr: domain.Forbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m := newAuthService(t)
+			tt.setup(m)
+
+			checkErr(t, s.Unlock(tt.ctx, testUser.ID), tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,269 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"foodsharing-backend/internal/domain"
+	"foodsharing-backend/internal/repository"
+	"foodsharing-backend/pkg/otp"
+)
+
+const (
+	twoFactorTokenTTL = 5 * time.Minute
+	recoveryCodeCount = 10
+)
+
+// errInvalidEnrollmentCode rejects a wrong code confirming an enrollment. Like a wrong code
+// on sign-in it is Unauthorized and counts towards the lockout.
+var errInvalidEnrollmentCode = fmt.Errorf("%w: invalid two-factor code", domain.Unauthorized)
+
+func (s *AuthService) SignInTwoFactor(ctx context.Context, input SignInTwoFactorInput,
+	device DeviceInfo) (SignInResult, error) {
+	userID, err := s.parseTwoFactorToken(input.TwoFactorToken)
+	if err != nil {
+		return SignInResult{}, err
+	}
+
+	twoFactor, err := s.twoFactor.GetByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, domain.NotFound) {
+			return SignInResult{}, fmt.Errorf("%w: two-factor authentication is not set up", domain.InvalidInput)
+		}
+		return SignInResult{}, err
+	}
+
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return SignInResult{}, err
+	}
+	if err := s.checkLockout(ctx, user.Email, device.IP); err != nil {
+		return SignInResult{}, err
+	}
+
+	var result SignInResult
+	if twoFactor.IsEnabled() {
+		err = s.checkTwoFactorCode(ctx, twoFactor, input.TwoFactorCodeInput)
+	} else {
+		result.RecoveryCodes, err = s.confirmTwoFactor(ctx, twoFactor, input.Code)
+	}
+	if err != nil {
+		if errors.Is(err, domain.Unauthorized) {
+			if err := s.registerLoginFailure(ctx, user.Email, device.IP); err != nil {
+				return SignInResult{}, err
+			}
+		}
+		return SignInResult{}, err
+	}
+
+	if err := s.resetLoginFailures(ctx, user.Email); err != nil {
+		return SignInResult{}, err
+	}
+
+	if result.Tokens, err = s.CreateSession(ctx, userID, device); err != nil {
+		return SignInResult{}, err
+	}
+	return result, nil
+}
+
+func (s *AuthService) EnrollTwoFactorOnSignIn(ctx context.Context, twoFactorToken string) (TwoFactorEnrollment,
+	error) {
+	userID, err := s.parseTwoFactorToken(twoFactorToken)
+	if err != nil {
+		return TwoFactorEnrollment{}, err
+	}
+
+	return s.enrollTwoFactor(ctx, userID)
+}
+
+func (s *AuthService) EnrollTwoFactor(ctx context.Context) (TwoFactorEnrollment, error) {
+	principal, ok := domain.PrincipalFromContext(ctx)
+	if !ok {
+		return TwoFactorEnrollment{}, domain.Unauthorized
+	}
+
+	return s.enrollTwoFactor(ctx, principal.UserID)
+}
+
+// ConfirmTwoFactor counts wrong codes towards the lockout, so a stolen session cannot be
+// used to guess the code of an enrollment it started.
+func (s *AuthService) ConfirmTwoFactor(ctx context.Context, code string, device DeviceInfo) ([]string, error) {
+	principal, ok := domain.PrincipalFromContext(ctx)
+	if !ok {
+		return nil, domain.Unauthorized
+	}
+
+	twoFactor, err := s.twoFactor.GetByUserID(ctx, principal.UserID)
+	if err != nil {
+		if errors.Is(err, domain.NotFound) {
+			return nil, fmt.Errorf("%w: two-factor enrollment has not been started", domain.InvalidInput)
+		}
+		return nil, err
+	}
+	if twoFactor.IsEnabled() {
+		return nil, fmt.Errorf("%w: two-factor authentication is already enabled", domain.AlreadyExists)
+	}
+
+	user, err := s.users.GetByID(ctx, principal.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkLockout(ctx, user.Email, device.IP); err != nil {
+		return nil, err
+	}
+
+	codes, err := s.confirmTwoFactor(ctx, twoFactor, code)
+	if errors.Is(err, errInvalidEnrollmentCode) {
+		if err := s.registerLoginFailure(ctx, user.Email, device.IP); err != nil {
+			return nil, err
+		}
+	}
+	return codes, err
+}
+
+func (s *AuthService) DisableTwoFactor(ctx context.Context, input TwoFactorCodeInput, device DeviceInfo) error {
+	principal, ok := domain.PrincipalFromContext(ctx)
+	if !ok {
+		return domain.Unauthorized
+	}
+	if s.requiresTwoFactor(principal) {
+		return fmt.Errorf("%w: two-factor authentication is mandatory for your permissions", domain.Forbidden)
+	}
+
+	twoFactor, err := s.twoFactor.GetByUserID(ctx, principal.UserID)
+	if err != nil && !errors.Is(err, domain.NotFound) {
+		return err
+	}
+	if !twoFactor.IsEnabled() {
+		return fmt.Errorf("%w: two-factor authentication is not enabled", domain.InvalidInput)
+	}
+
+	user, err := s.users.GetByID(ctx, principal.UserID)
+	if err != nil {
+		return err
+	}
+	if err := s.checkLockout(ctx, user.Email, device.IP); err != nil {
+		return err
+	}
+
+	if err := s.checkTwoFactorCode(ctx, twoFactor, input); err != nil {
+		if errors.Is(err, domain.Unauthorized) {
+			if err := s.registerLoginFailure(ctx, user.Email, device.IP); err != nil {
+				return err
+			}
+		}
+		return err
+	}
+	return s.twoFactor.Delete(ctx, principal.UserID)
+}
+
+func (s *AuthService) ResetTwoFactor(ctx context.Context, userID domain.ID) error {
+	if _, err := authorize(ctx, domain.EditUser); err != nil {
+		return err
+	}
+	return s.txManager.WithinTx(ctx, func(repos *repository.Repositories) error {
+		if err := repos.TwoFactor.Delete(ctx, userID); err != nil {
+			return err
+		}
+		return repos.Sessions.DeleteByUserID(ctx, userID)
+	})
+}
+
+func (s *AuthService) enrollTwoFactor(ctx context.Context, userID domain.ID) (TwoFactorEnrollment, error) {
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return TwoFactorEnrollment{}, err
+	}
+
+	secret, err := otp.GenerateSecret()
+	if err != nil {
+		return TwoFactorEnrollment{}, err
+	}
+	if err := s.twoFactor.Set(ctx, userID, secret); err != nil {
+		return TwoFactorEnrollment{}, err
+	}
+
+	return TwoFactorEnrollment{
+		Secret:          secret,
+		ProvisioningURI: otp.ProvisioningURI(s.cfg.TwoFactorIssuer, user.Email, secret),
+	}, nil
+}
+
+// confirmTwoFactor enables a pending enrollment once the user proves their authenticator
+// works and returns a fresh set of recovery codes.
+func (s *AuthService) confirmTwoFactor(ctx context.Context, twoFactor domain.TwoFactor, code string) ([]string,
+	error) {
+	if code == "" {
+		return nil, fmt.Errorf("%w: code is required", domain.InvalidInput)
+	}
+
+	step, ok := otp.Validate(twoFactor.Secret, code, time.Now())
+	if !ok {
+		return nil, errInvalidEnrollmentCode
+	}
+	if err := s.twoFactor.Enable(ctx, twoFactor.UserID, step); err != nil {
+		if errors.Is(err, domain.NotFound) {
+			return nil, errInvalidEnrollmentCode
+		}
+		return nil, err
+	}
+
+	codes, err := otp.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.twoFactor.SetRecoveryCodes(ctx, twoFactor.UserID, codes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// checkTwoFactorCode accepts every code only once: authenticator codes must belong to a
+// later time step than the last accepted one and recovery codes are used up.
+func (s *AuthService) checkTwoFactorCode(ctx context.Context, twoFactor domain.TwoFactor,
+	input TwoFactorCodeInput) error {
+	invalid := fmt.Errorf("%w: invalid two-factor code", domain.Unauthorized)
+
+	var err error
+	switch {
+	case input.Code != "":
+		step, ok := otp.Validate(twoFactor.Secret, input.Code, time.Now())
+		if !ok {
+			return invalid
+		}
+		err = s.twoFactor.UseStep(ctx, twoFactor.UserID, step)
+	case input.RecoveryCode != "":
+		err = s.twoFactor.UseRecoveryCode(ctx, twoFactor.UserID, otp.NormalizeRecoveryCode(input.RecoveryCode))
+	default:
+		return fmt.Errorf("%w: code or recovery_code is required", domain.InvalidInput)
+	}
+
+	if errors.Is(err, domain.NotFound) {
+		return invalid
+	}
+	return err
+}
+
+func (s *AuthService) parseTwoFactorToken(token string) (domain.ID, error) {
+	if token == "" {
+		return 0, fmt.Errorf("%w: two_factor_token is required", domain.InvalidInput)
+	}
+
+	userID, err := s.tokenManager.ParseTwoFactorToken(token)
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid two-factor token", domain.Unauthorized)
+	}
+	return domain.ID(userID), nil
+}
+
+// requiresTwoFactor reports whether the user holds any of the permissions that make
+// two-factor authentication mandatory. Permissions over their own records do not count.
+func (s *AuthService) requiresTwoFactor(principal domain.Principal) bool {
+	permissions := principal.Permissions
+	for _, cityPermissions := range principal.CityPermissions {
+		permissions = permissions.Union(cityPermissions)
+	}
+	return permissions.Intersects(s.cfg.TwoFactorPermissions)
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"foodsharing-backend/internal/domain"
+	"foodsharing-backend/internal/repository"
+)
+
+type AuthorizationService struct {
+	groups repository.Groups
+}
+
+func NewAuthorizationService(groups repository.Groups) *AuthorizationService {
+	return &AuthorizationService{groups: groups}
+}
+
+// Identify grants the user the permissions of each of their groups in the scope of the
+// group. City scoped groups apply to the city of the user, memberships limited to a city
+// turn everything but own records scope into that city. Permissions inherited from parent
+// groups are granted in the scope of the group the user is a member of.
+func (s *AuthorizationService) Identify(ctx context.Context, user domain.User) (domain.Principal, error) {
+	memberships, err := s.groups.GetUserGroups(ctx, user.ID)
+	if err != nil {
+		return domain.Principal{}, err
+	}
+
+	principal := domain.Principal{UserID: user.ID}
+	for _, membership := range memberships {
+		scope, cityID := membershipScope(user, membership)
+		principal.Grant(membership.EffectivePermissions, scope, cityID)
+	}
+	return principal, nil
+}
+
+// Explain evaluates the memberships of the user the way Identify does and reports for
+// each of them whether it covers the record and holds the permission the action needs.
+func (s *AuthorizationService) Explain(ctx context.Context, user domain.User, action domain.Action,
+	resource domain.Resource, record domain.Attributes) (domain.PermissionExplanation, error) {
+	permission, ok := domain.PermissionFor(action, resource)
+	if !ok {
+		return domain.PermissionExplanation{}, fmt.Errorf("%w: unknown action %q on %q", domain.InvalidInput,
+			action, resource)
+	}
+
+	memberships, err := s.groups.GetUserGroups(ctx, user.ID)
+	if err != nil {
+		return domain.PermissionExplanation{}, err
+	}
+
+	anyRecord := record == domain.Attributes{}
+	explanation := domain.PermissionExplanation{
+		UserID:     user.ID,
+		Action:     action,
+		Resource:   resource,
+		Permission: permission,
+		Record:     record,
+		Groups:     make([]domain.GroupGrant, 0, len(memberships)),
+	}
+
+	principal := domain.Principal{UserID: user.ID}
+	for _, membership := range memberships {
+		scope, cityID := membershipScope(user, membership)
+		principal.Grant(membership.EffectivePermissions, scope, cityID)
+
+		grant := domain.GroupGrant{
+			GroupID:     membership.ID,
+			Name:        membership.Name,
+			Scope:       scope,
+			ExpiresAt:   membership.ExpiresAt,
+			Permissions: membership.EffectivePermissions,
+			Applies:     anyRecord || covers(user.ID, scope, cityID, record),
+			Grants:      membership.EffectivePermissions.Has(permission),
+		}
+		if scope == domain.ScopeCity {
+			grant.CityID = cityID
+		}
+		explanation.Groups = append(explanation.Groups, grant)
+	}
+
+	if anyRecord {
+		explanation.Allowed = s.Can(principal, action, resource)
+	} else {
+		explanation.Allowed = s.CanOn(principal, action, resource, record)
+	}
+	return explanation, nil
+}
+
+func (s *AuthorizationService) Can(user domain.Principal, action domain.Action, resource domain.Resource) bool {
+	return user.AnyPermissions().Allows(action, resource)
+}
+
+func (s *AuthorizationService) CanOn(user domain.Principal, action domain.Action, resource domain.Resource,
+	attrs domain.Attributes) bool {
+	return user.PermissionsOn(attrs).Allows(action, resource)
+}
+
+// membershipScope returns the scope the permissions of the membership apply in and, for
+// ScopeCity, the city.
+func membershipScope(user domain.User, membership domain.Membership) (domain.Scope, domain.ID) {
+	scope, cityID := membership.Scope, user.CityID
+	if membership.CityID != 0 {
+		cityID = membership.CityID
+		if scope == domain.ScopeAll {
+			scope = domain.ScopeCity
+		}
+	}
+	return scope, cityID
+}
+
+// covers reports whether permissions granted in the scope apply to the record, matching
+// domain.Principal.PermissionsOn.
+func covers(userID domain.ID, scope domain.Scope, cityID domain.ID, record domain.Attributes) bool {
+	switch scope {
+	case domain.ScopeAll:
+		return true
+	case domain.ScopeOwn:
+		return record.OwnerID != 0 && record.OwnerID == userID
+	case domain.ScopeCity:
+		return cityID != 0 && record.CityID == cityID
+	default:
+		return false
+	}
+}
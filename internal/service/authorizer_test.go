@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"foodsharing-backend/internal/domain"
+	mock_repository "foodsharing-backend/internal/repository/mocks"
+
+	"github.com/golang/mock/gomock"
+)
+
+var (
+	allActions   = []domain.Action{domain.ActionCreate, domain.ActionRead, domain.ActionEdit}
+	allResources = []domain.Resource{domain.ResourceUsers, domain.ResourceActs, domain.ResourceCities,
+		domain.ResourceCompanies, domain.ResourceGroups}
+)
+
+func membership(scope domain.Scope, cityID domain.ID, permissions ...domain.Permission) domain.Membership {
+	set := domain.NewPermissions(permissions...)
+	return domain.Membership{
+		Group:  domain.Group{Name: "group", Permissions: set, EffectivePermissions: set, Scope: scope},
+		CityID: cityID,
+	}
+}
+
+// identify runs Identify for the user with the given memberships.
+func identify(t *testing.T, user domain.User, memberships ...domain.Membership) (*AuthorizationService,
+	domain.Principal) {
+	t.Helper()
+
+	repo := mock_repository.NewMockGroups(gomock.NewController(t))
+	repo.EXPECT().GetUserGroups(gomock.Any(), user.ID).Return(memberships, nil)
+
+	s := NewAuthorizationService(repo)
+	principal, err := s.Identify(context.Background(), user)
+	if err != nil {
+		t.Fatalf("Identify() error = %v", err)
+	}
+	return s, principal
+}
+
+func TestAuthorizationServiceActions(t *testing.T) {
+	user := domain.User{Object: domain.Object{ID: 1}, CityID: 10}
+	record := domain.Attributes{OwnerID: 2, CityID: 20}
+
+	var all []domain.Permission
+	for _, resource := range allResources {
+		for _, action := range allActions {
+			permission, ok := domain.PermissionFor(action, resource)
+			if !ok {
+				t.Fatalf("no permission for %s on %s", action, resource)
+			}
+			all = append(all, permission)
+		}
+	}
+
+	for _, resource := range allResources {
+		for _, action := range allActions {
+			permission, _ := domain.PermissionFor(action, resource)
+			var others []domain.Permission
+			for _, p := range all {
+				if p != permission {
+					others = append(others, p)
+				}
+			}
+
+			tests := []struct {
+				name       string
+				membership domain.Membership
+				want       bool
+			}{
+				{"granted", membership(domain.ScopeAll, 0, permission), true},
+				{"missing", membership(domain.ScopeAll, 0, others...), false},
+				{"admin", membership(domain.ScopeAll, 0, domain.Admin), true},
+			}
+			for _, tt := range tests {
+				t.Run(string(permission)+"/"+tt.name, func(t *testing.T) {
+					s, principal := identify(t, user, tt.membership)
+
+					if got := s.Can(principal, action, resource); got != tt.want {
+						t.Errorf("Can() = %v, want %v", got, tt.want)
+					}
+					if got := s.CanOn(principal, action, resource, record); got != tt.want {
+						t.Errorf("CanOn() = %v, want %v", got, tt.want)
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestAuthorizationServiceInheritedPermissions(t *testing.T) {
+	user := domain.User{Object: domain.Object{ID: 1}, CityID: 10}
+	child := membership(domain.ScopeCity, 0, domain.ReadAct)
+	child.ParentID = 3
+	child.EffectivePermissions = child.EffectivePermissions.Union(domain.NewPermissions(domain.EditAct))
+
+	s, principal := identify(t, user, child)
+
+	if !s.CanOn(principal, domain.ActionEdit, domain.ResourceActs, domain.Attributes{OwnerID: 2, CityID: 10}) {
+		t.Error("CanOn() = false for an inherited permission in the scope of the group, want true")
+	}
+	if s.CanOn(principal, domain.ActionEdit, domain.ResourceActs, domain.Attributes{OwnerID: 2, CityID: 20}) {
+		t.Error("CanOn() = true for an inherited permission outside the scope of the group, want false")
+	}
+}
+
+func TestAuthorizationServiceScopes(t *testing.T) {
+	user := domain.User{Object: domain.Object{ID: 1}, CityID: 10}
+
+	tests := []struct {
+		name       string
+		membership domain.Membership
+		record     domain.Attributes
+		want       bool
+	}{
+		{
+			name:       "own scope on own record",
+			membership: membership(domain.ScopeOwn, 0, domain.EditAct),
+			record:     domain.Attributes{OwnerID: 1, CityID: 20},
+			want:       true,
+		},
+		{
+			name:       "own scope on record of another user",
+			membership: membership(domain.ScopeOwn, 0, domain.EditAct),
+			record:     domain.Attributes{OwnerID: 2, CityID: 10},
+			want:       false,
+		},
+		{
+			name:       "own scope on record without owner",
+			membership: membership(domain.ScopeOwn, 0, domain.EditAct),
+			record:     domain.Attributes{CityID: 10},
+			want:       false,
+		},
+		{
+			name:       "city scope in city of user",
+			membership: membership(domain.ScopeCity, 0, domain.EditAct),
+			record:     domain.Attributes{OwnerID: 2, CityID: 10},
+			want:       true,
+		},
+		{
+			name:       "city scope in another city",
+			membership: membership(domain.ScopeCity, 0, domain.EditAct),
+			record:     domain.Attributes{OwnerID: 1, CityID: 20},
+			want:       false,
+		},
+		{
+			name:       "city membership in its city",
+			membership: membership(domain.ScopeAll, 20, domain.EditAct),
+			record:     domain.Attributes{OwnerID: 2, CityID: 20},
+			want:       true,
+		},
+		{
+			name:       "city membership in city of user",
+			membership: membership(domain.ScopeAll, 20, domain.EditAct),
+			record:     domain.Attributes{OwnerID: 2, CityID: 10},
+			want:       false,
+		},
+		{
+			name:       "city membership keeps own scope",
+			membership: membership(domain.ScopeOwn, 20, domain.EditAct),
+			record:     domain.Attributes{OwnerID: 1, CityID: 10},
+			want:       true,
+		},
+		{
+			name:       "city scope without permission",
+			membership: membership(domain.ScopeCity, 0, domain.ReadAct),
+			record:     domain.Attributes{OwnerID: 2, CityID: 10},
+			want:       false,
+		},
+		{
+			name:       "admin in city scope",
+			membership: membership(domain.ScopeCity, 0, domain.Admin),
+			record:     domain.Attributes{OwnerID: 2, CityID: 10},
+			want:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, principal := identify(t, user, tt.membership)
+
+			if got := s.CanOn(principal, domain.ActionEdit, domain.ResourceActs, tt.record); got != tt.want {
+				t.Errorf("CanOn() = %v, want %v", got, tt.want)
+			}
+			if got := s.Can(principal, domain.ActionEdit, domain.ResourceActs); !got && tt.want {
+				t.Errorf("Can() = false although CanOn() allows the record")
+			}
+		})
+	}
+}
+
+func TestAuthorizationServiceIdentifyError(t *testing.T) {
+	repo := mock_repository.NewMockGroups(gomock.NewController(t))
+	repo.EXPECT().GetUserGroups(gomock.Any(), domain.ID(1)).Return(nil, errRepo)
+
+	_, err := NewAuthorizationService(repo).Identify(context.Background(), domain.User{Object: domain.Object{ID: 1}})
+	checkErr(t, err, errRepo)
+}
+
+func TestAuthorizationServiceExplain(t *testing.T) {
+	user := domain.User{Object: domain.Object{ID: 1}, CityID: 10}
+	memberships := []domain.Membership{
+		membership(domain.ScopeOwn, 0, domain.EditAct),
+		membership(domain.ScopeCity, 0, domain.ReadAct),
+	}
+
+	groups := mock_repository.NewMockGroups(gomock.NewController(t))
+	groups.EXPECT().GetUserGroups(gomock.Any(), user.ID).Return(memberships, nil)
+	s := NewAuthorizationService(groups)
+
+	explanation, err := s.Explain(context.Background(), user, domain.ActionEdit, domain.ResourceActs,
+		domain.Attributes{OwnerID: 1, CityID: 20})
+	if err != nil {
+		t.Fatalf("Explain() error = %v", err)
+	}
+	if !explanation.Allowed {
+		t.Errorf("Allowed = false, want true")
+	}
+	if got := explanation.Groups[0]; !got.Applies || !got.Grants {
+		t.Errorf("own group: Applies = %v, Grants = %v, want both true", got.Applies, got.Grants)
+	}
+	if got := explanation.Groups[1]; got.Applies || got.Grants || got.CityID != 10 {
+		t.Errorf("city group: Applies = %v, Grants = %v, CityID = %d, want false, false, 10",
+			got.Applies, got.Grants, got.CityID)
+	}
+
+	if _, err := s.Explain(context.Background(), user, "delete", domain.ResourceActs,
+		domain.Attributes{}); err == nil {
+		t.Errorf("Explain() of an unknown action succeeded")
+	}
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// backgroundQueue runs work that must not hold up the response on a fixed number of
+// workers. Jobs that do not fit into the queue are dropped, so a flood of requests
+// cannot pile up goroutines.
+type backgroundQueue struct {
+	jobs    chan func(ctx context.Context)
+	timeout time.Duration
+
+	// ctx is cancelled when stop runs out of time, which aborts the running jobs.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	stopped bool
+	workers sync.WaitGroup
+}
+
+// newBackgroundQueue starts workers that run each job with a context limited to timeout.
+func newBackgroundQueue(workers, size int, timeout time.Duration) *backgroundQueue {
+	ctx, cancel := context.WithCancel(context.Background())
+	q := &backgroundQueue{
+		jobs:    make(chan func(ctx context.Context), size),
+		timeout: timeout,
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+
+	q.workers.Add(workers)
+	for i := 0; i < workers; i++ {
+		go q.work()
+	}
+	return q
+}
+
+func (q *backgroundQueue) work() {
+	defer q.workers.Done()
+	for job := range q.jobs {
+		q.run(job)
+	}
+}
+
+func (q *backgroundQueue) run(job func(ctx context.Context)) {
+	ctx, cancel := context.WithTimeout(q.ctx, q.timeout)
+	defer cancel()
+	job(ctx)
+}
+
+// enqueue reports false if the job was dropped because the queue is full or stopped.
+func (q *backgroundQueue) enqueue(job func(ctx context.Context)) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.stopped {
+		return false
+	}
+
+	select {
+	case q.jobs <- job:
+		return true
+	default:
+		return false
+	}
+}
+
+// stop refuses new jobs and waits for the queued ones. If ctx ends first, the running
+// jobs are cancelled, the remaining ones are lost and ctx.Err() is returned.
+func (q *backgroundQueue) stop(ctx context.Context) error {
+	q.mu.Lock()
+	if !q.stopped {
+		q.stopped = true
+		close(q.jobs)
+	}
+	q.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		q.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		q.cancel()
+		return nil
+	case <-ctx.Done():
+		q.cancel()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"foodsharing-backend/internal/domain"
+	"foodsharing-backend/internal/repository"
+)
+
+type CitiesService struct {
+	repo    repository.Cities
+	regions repository.Regions
+}
+
+func NewCitiesService(repo repository.Cities, regions repository.Regions) *CitiesService {
+	return &CitiesService{
+		repo:    repo,
+		regions: regions,
+	}
+}
+
+func (i CityInput) validate() error {
+	if strings.TrimSpace(i.Name) == "" {
+		return fmt.Errorf("%w: name is required", domain.InvalidInput)
+	}
+	if i.Timezone == "" {
+		return fmt.Errorf("%w: timezone is required", domain.InvalidInput)
+	}
+	if _, err := time.LoadLocation(i.Timezone); err != nil {
+		return fmt.Errorf("%w: unknown timezone %q", domain.InvalidInput, i.Timezone)
+	}
+	if i.Coordinates != nil && !i.Coordinates.IsValid() {
+		return fmt.Errorf("%w: coordinates are out of range", domain.InvalidInput)
+	}
+	return nil
+}
+
+func (i CityInput) toDomain() domain.City {
+	return domain.City{
+		Name:        strings.TrimSpace(i.Name),
+		RegionID:    i.RegionID,
+		Timezone:    i.Timezone,
+		Coordinates: i.Coordinates,
+	}
+}
+
+// Create needs AddCity for all records: a city scoped grant cannot cover a city that does
+// not exist yet.
+func (s *CitiesService) Create(ctx context.Context, input CityInput) (domain.City, error) {
+	if _, err := authorize(ctx, domain.AddCity); err != nil {
+		return domain.City{}, err
+	}
+	if err := input.validate(); err != nil {
+		return domain.City{}, err
+	}
+	if err := s.checkRegion(ctx, input.RegionID); err != nil {
+		return domain.City{}, err
+	}
+
+	city := input.toDomain()
+	if err := s.repo.Create(ctx, &city); err != nil {
+		return domain.City{}, err
+	}
+	return city, nil
+}
+
+func (s *CitiesService) Update(ctx context.Context, id domain.ID, input CityInput, version int64) error {
+	if err := input.validate(); err != nil {
+		return err
+	}
+
+	city, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if _, err := authorizeOn(ctx, domain.EditCity, city.Attributes()); err != nil {
+		return err
+	}
+	if err := checkVersion(version, city.Version); err != nil {
+		return err
+	}
+	if err := s.checkRegion(ctx, input.RegionID); err != nil {
+		return err
+	}
+
+	updated := input.toDomain()
+	updated.ID = id
+	updated.Version = city.Version
+	return s.repo.Update(ctx, updated)
+}
+
+// Delete fails with InUse while the city is referenced.
+func (s *CitiesService) Delete(ctx context.Context, id domain.ID, version int64) error {
+	city, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if _, err := authorizeOn(ctx, domain.EditCity, city.Attributes()); err != nil {
+		return err
+	}
+	if err := checkVersion(version, city.Version); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, id, city.Version)
+}
+
+func (s *CitiesService) GetByID(ctx context.Context, id domain.ID) (domain.City, error) {
+	city, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return domain.City{}, err
+	}
+	if _, err := authorizeOn(ctx, domain.ReadCity, city.Attributes()); err != nil {
+		return domain.City{}, err
+	}
+	return city, nil
+}
+
+// GetAll lists the cities the user may read.
+func (s *CitiesService) GetAll(ctx context.Context, opts domain.ListOptions) ([]domain.CitySummary, string, error) {
+	opts, err := restrictList(ctx, domain.ReadCity, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	return s.repo.GetAll(ctx, opts)
+}
+
+// checkRegion fails with InvalidInput when the region does not exist. Zero means no region.
+func (s *CitiesService) checkRegion(ctx context.Context, regionID domain.ID) error {
+	if regionID == 0 {
+		return nil
+	}
+	if _, err := s.regions.GetByID(ctx, regionID); err != nil {
+		if errors.Is(err, domain.NotFound) {
+			return fmt.Errorf("%w: region does not exist", domain.InvalidInput)
+		}
+		return err
+	}
+	return nil
+}
+
+// cityLocations resolves the time zones of cities, loading each city once. Date-only fields
+// leave the services placed in the zone of their city, see domain.DateIn.
+type cityLocations struct {
+	cities    repository.Cities
+	locations map[domain.ID]*time.Location
+}
+
+func newCityLocations(cities repository.Cities) *cityLocations {
+	return &cityLocations{
+		cities:    cities,
+		locations: make(map[domain.ID]*time.Location),
+	}
+}
+
+func (l *cityLocations) get(ctx context.Context, cityID domain.ID) (*time.Location, error) {
+	if location, ok := l.locations[cityID]; ok {
+		return location, nil
+	}
+
+	city, err := l.cities.GetByID(ctx, cityID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot find city %d: %w", cityID, err)
+	}
+	location, err := city.Location()
+	if err != nil {
+		return nil, err
+	}
+	l.locations[cityID] = location
+	return location, nil
+}
+
+// dateIn places the date-only value in the zone of the city.
+func (l *cityLocations) dateIn(ctx context.Context, cityID domain.ID, date time.Time) (time.Time, error) {
+	location, err := l.get(ctx, cityID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return domain.DateIn(date, location), nil
+}
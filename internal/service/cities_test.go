@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"foodsharing-backend/internal/domain"
+	mock_repository "foodsharing-backend/internal/repository/mocks"
+
+	"github.com/golang/mock/gomock"
+)
+
+type citiesMocks struct {
+	repo    *mock_repository.MockCities
+	regions *mock_repository.MockRegions
+}
+
+func newCitiesService(t *testing.T) (*CitiesService, citiesMocks) {
+	ctrl := gomock.NewController(t)
+	m := citiesMocks{
+		repo:    mock_repository.NewMockCities(ctrl),
+		regions: mock_repository.NewMockRegions(ctrl),
+	}
+	return NewCitiesService(m.repo, m.regions), m
+}
+
+func TestCitiesServiceCreate(t *testing.T) {
+	input := CityInput{Name: " Vladivostok ", RegionID: 3, Timezone: "Asia/Vladivostok"}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		input   CityInput
+		setup   func(m citiesMocks)
+		wantErr error
+	}{
+		{
+			name:  "new city",
+			ctx:   asUser(1, domain.AddCity),
+			input: input,
+			setup: func(m citiesMocks) {
+				m.regions.EXPECT().GetByID(gomock.Any(), domain.ID(3)).Return(domain.Region{}, nil)
+				m.repo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, city *domain.City) error {
+						if city.Name != "Vladivostok" {
+							t.Errorf("Create() name = %q, want it trimmed", city.Name)
+						}
+						return nil
+					})
+			},
+		},
+		{
+			name:    "city scoped permission",
+			ctx:     asCityUser(1, 10, domain.AddCity),
+			input:   input,
+			setup:   func(m citiesMocks) {},
+			wantErr: domain.Forbidden,
+		},
+		{
+			name:  "name taken",
+			ctx:   asUser(1, domain.AddCity),
+			input: input,
+			setup: func(m citiesMocks) {
+				m.regions.EXPECT().GetByID(gomock.Any(), domain.ID(3)).Return(domain.Region{}, nil)
+				m.repo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(domain.AlreadyExists)
+			},
+			wantErr: domain.AlreadyExists,
+		},
+		{
+			name:  "missing region",
+			ctx:   asUser(1, domain.AddCity),
+			input: input,
+			setup: func(m citiesMocks) {
+				m.regions.EXPECT().GetByID(gomock.Any(), domain.ID(3)).Return(domain.Region{}, domain.NotFound)
+			},
+			wantErr: domain.InvalidInput,
+		},
+		{
+			name:    "unknown timezone",
+			ctx:     asUser(1, domain.AddCity),
+			input:   CityInput{Name: "Vladivostok", Timezone: "Mars/Olympus"},
+			setup:   func(m citiesMocks) {},
+			wantErr: domain.InvalidInput,
+		},
+		{
+			name:    "missing name",
+			ctx:     asUser(1, domain.AddCity),
+			input:   CityInput{Name: " "},
+			setup:   func(m citiesMocks) {},
+			wantErr: domain.InvalidInput,
+		},
+		{
+			name:  "repository error",
+			ctx:   asUser(1, domain.AddCity),
+			input: input,
+			setup: func(m citiesMocks) {
+				m.regions.EXPECT().GetByID(gomock.Any(), domain.ID(3)).Return(domain.Region{}, nil)
+				m.repo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(errRepo)
+			},
+			wantErr: errRepo,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m := newCitiesService(t)
+			tt.setup(m)
+
+			_, err := s.Create(tt.ctx, tt.input)
+			checkErr(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestCitiesServiceDelete(t *testing.T) {
+	city := vladivostok
+	city.Version = 5
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		version int64
+		setup   func(m citiesMocks)
+		wantErr error
+	}{
+		{
+			name:    "editor of the city",
+			ctx:     asCityUser(1, city.ID, domain.EditCity),
+			version: city.Version,
+			setup: func(m citiesMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), city.ID).Return(city, nil)
+				m.repo.EXPECT().Delete(gomock.Any(), city.ID, city.Version).Return(nil)
+			},
+		},
+		{
+			name:    "editor of another city",
+			ctx:     asCityUser(1, newYork.ID, domain.EditCity),
+			version: city.Version,
+			setup: func(m citiesMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), city.ID).Return(city, nil)
+			},
+			wantErr: domain.Forbidden,
+		},
+		{
+			name:    "stale version",
+			ctx:     asUser(1, domain.EditCity),
+			version: city.Version - 1,
+			setup: func(m citiesMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), city.ID).Return(city, nil)
+			},
+			wantErr: domain.Conflict,
+		},
+		{
+			name:    "city in use",
+			ctx:     asUser(1, domain.EditCity),
+			version: city.Version,
+			setup: func(m citiesMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), city.ID).Return(city, nil)
+				m.repo.EXPECT().Delete(gomock.Any(), city.ID, city.Version).Return(domain.InUse)
+			},
+			wantErr: domain.InUse,
+		},
+		{
+			name:    "not found",
+			ctx:     asUser(1, domain.EditCity),
+			version: city.Version,
+			setup: func(m citiesMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), city.ID).Return(domain.City{}, domain.NotFound)
+			},
+			wantErr: domain.NotFound,
+		},
+		{
+			name:    "repository error",
+			ctx:     asUser(1, domain.EditCity),
+			version: city.Version,
+			setup: func(m citiesMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), city.ID).Return(domain.City{}, errRepo)
+			},
+			wantErr: errRepo,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m := newCitiesService(t)
+			tt.setup(m)
+
+			checkErr(t, s.Delete(tt.ctx, city.ID, tt.version), tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"foodsharing-backend/internal/domain"
+	"foodsharing-backend/internal/repository"
+)
+
+type DonorCompaniesService struct {
+	repo   repository.DonorCompanies
+	cities repository.Cities
+}
+
+func NewDonorCompaniesService(repo repository.DonorCompanies, cities repository.Cities) *DonorCompaniesService {
+	return &DonorCompaniesService{
+		repo:   repo,
+		cities: cities,
+	}
+}
+
+func (i DonorCompanyInput) validate() error {
+	if i.Name == "" {
+		return fmt.Errorf("%w: name is required", domain.InvalidInput)
+	}
+	if i.CityID == 0 {
+		return fmt.Errorf("%w: city_id is required", domain.InvalidInput)
+	}
+	if i.ContractDate.IsZero() {
+		return fmt.Errorf("%w: contract_date is required", domain.InvalidInput)
+	}
+	if i.ContractNumber <= 0 {
+		return fmt.Errorf("%w: contract_number must be positive", domain.InvalidInput)
+	}
+	return nil
+}
+
+func (i DonorCompanyInput) toDomain() domain.DonorCompany {
+	return domain.DonorCompany{
+		Name:           i.Name,
+		CityID:         i.CityID,
+		ContractDate:   i.ContractDate,
+		ContractNumber: i.ContractNumber,
+	}
+}
+
+func (s *DonorCompaniesService) Create(ctx context.Context, input DonorCompanyInput) (domain.DonorCompany, error) {
+	if err := input.validate(); err != nil {
+		return domain.DonorCompany{}, err
+	}
+	if _, err := authorizeOn(ctx, domain.AddCompany, domain.Attributes{CityID: input.CityID}); err != nil {
+		return domain.DonorCompany{}, err
+	}
+
+	company := input.toDomain()
+	if err := s.repo.Create(ctx, &company); err != nil {
+		return domain.DonorCompany{}, err
+	}
+
+	contractDate, err := s.contractDate(ctx, company)
+	if err != nil {
+		return domain.DonorCompany{}, err
+	}
+	company.ContractDate = contractDate
+	return company, nil
+}
+
+// Update needs the EditCompany permission for both the current and the new city of the
+// company.
+func (s *DonorCompaniesService) Update(ctx context.Context, id domain.ID, input DonorCompanyInput,
+	version int64) error {
+	if err := input.validate(); err != nil {
+		return err
+	}
+
+	company, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if _, err := authorizeOn(ctx, domain.EditCompany, company.Attributes()); err != nil {
+		return err
+	}
+	if err := checkVersion(version, company.Version); err != nil {
+		return err
+	}
+
+	updated := input.toDomain()
+	updated.ID = id
+	updated.Version = company.Version
+	if _, err := authorizeOn(ctx, domain.EditCompany, updated.Attributes()); err != nil {
+		return err
+	}
+	return s.repo.Update(ctx, updated)
+}
+
+func (s *DonorCompaniesService) Delete(ctx context.Context, id domain.ID, version int64) error {
+	company, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if _, err := authorizeOn(ctx, domain.EditCompany, company.Attributes()); err != nil {
+		return err
+	}
+	if err := checkVersion(version, company.Version); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, id, company.Version)
+}
+
+func (s *DonorCompaniesService) Restore(ctx context.Context, id domain.ID) error {
+	if _, err := authorize(ctx, domain.Admin); err != nil {
+		return err
+	}
+	return s.repo.Restore(ctx, id)
+}
+
+func (s *DonorCompaniesService) GetByID(ctx context.Context, id domain.ID) (domain.DonorCompany, error) {
+	company, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return domain.DonorCompany{}, err
+	}
+	if _, err := authorizeOn(ctx, domain.ReadCompany, company.Attributes()); err != nil {
+		return domain.DonorCompany{}, err
+	}
+	if company.ContractDate, err = s.contractDate(ctx, company); err != nil {
+		return domain.DonorCompany{}, err
+	}
+	return company, nil
+}
+
+// GetAll lists the companies of the cities the user may read companies in.
+func (s *DonorCompaniesService) GetAll(ctx context.Context, opts domain.ListOptions) ([]domain.DonorCompany, string,
+	error) {
+	opts, err := restrictList(ctx, domain.ReadCompany, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	opts.Deleted = false
+	return s.getAll(ctx, opts)
+}
+
+func (s *DonorCompaniesService) GetDeleted(ctx context.Context, opts domain.ListOptions) ([]domain.DonorCompany,
+	string, error) {
+	opts, err := listDeleted(ctx, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	return s.getAll(ctx, opts)
+}
+
+func (s *DonorCompaniesService) getAll(ctx context.Context, opts domain.ListOptions) ([]domain.DonorCompany, string,
+	error) {
+	companies, next, err := s.repo.GetAll(ctx, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	locations := newCityLocations(s.cities)
+	for i := range companies {
+		companies[i].ContractDate, err = locations.dateIn(ctx, companies[i].CityID, companies[i].ContractDate)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+	return companies, next, nil
+}
+
+// contractDate returns the contract date of the company at the start of the day in the zone
+// of its city.
+func (s *DonorCompaniesService) contractDate(ctx context.Context, company domain.DonorCompany) (time.Time, error) {
+	return newCityLocations(s.cities).dateIn(ctx, company.CityID, company.ContractDate)
+}
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"foodsharing-backend/internal/domain"
+	mock_repository "foodsharing-backend/internal/repository/mocks"
+
+	"github.com/golang/mock/gomock"
+)
+
+var (
+	vladivostok = domain.City{Object: domain.Object{ID: 10}, Name: "Vladivostok", Timezone: "Asia/Vladivostok"}
+	newYork     = domain.City{Object: domain.Object{ID: 20}, Name: "New York", Timezone: "America/New_York"}
+)
+
+func TestDonorCompaniesServiceContractDatesInCityZone(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := mock_repository.NewMockDonorCompanies(ctrl)
+	cities := mock_repository.NewMockCities(ctrl)
+	s := NewDonorCompaniesService(repo, cities)
+	ctx := asUser(1, domain.ReadCompany)
+
+	stored := time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)
+	companies := []domain.DonorCompany{
+		{Object: domain.Object{ID: 1}, CityID: vladivostok.ID, ContractDate: stored},
+		{Object: domain.Object{ID: 2}, CityID: newYork.ID, ContractDate: stored},
+		{Object: domain.Object{ID: 3}, CityID: vladivostok.ID, ContractDate: stored},
+	}
+	repo.EXPECT().GetAll(gomock.Any(), gomock.Any()).Return(companies, "", nil)
+	cities.EXPECT().GetByID(gomock.Any(), vladivostok.ID).Return(vladivostok, nil)
+	cities.EXPECT().GetByID(gomock.Any(), newYork.ID).Return(newYork, nil)
+
+	got, _, err := s.GetAll(ctx, domain.ListOptions{})
+	if err != nil {
+		t.Fatalf("GetAll() error = %v", err)
+	}
+	want := []string{"2024-05-01T00:00:00+10:00", "2024-05-01T00:00:00-04:00", "2024-05-01T00:00:00+10:00"}
+	for i, company := range got {
+		if date := company.ContractDate.Format(time.RFC3339); date != want[i] {
+			t.Errorf("company %d: contract date = %s, want %s", company.ID, date, want[i])
+		}
+	}
+
+	repo.EXPECT().GetByID(gomock.Any(), domain.ID(2)).Return(companies[1], nil)
+	cities.EXPECT().GetByID(gomock.Any(), newYork.ID).Return(newYork, nil)
+
+	company, err := s.GetByID(ctx, 2)
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if date := company.ContractDate.Format(time.RFC3339); date != want[1] {
+		t.Errorf("GetByID() contract date = %s, want %s", date, want[1])
+	}
+}
+
+func TestDonorCompaniesServiceGetByID(t *testing.T) {
+	company := domain.DonorCompany{Object: domain.Object{ID: 7}, CityID: vladivostok.ID}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		setup   func(repo *mock_repository.MockDonorCompanies, cities *mock_repository.MockCities)
+		wantErr error
+	}{
+		{
+			name: "reader in the city",
+			ctx:  asCityUser(1, vladivostok.ID, domain.ReadCompany),
+			setup: func(repo *mock_repository.MockDonorCompanies, cities *mock_repository.MockCities) {
+				repo.EXPECT().GetByID(gomock.Any(), company.ID).Return(company, nil)
+				cities.EXPECT().GetByID(gomock.Any(), vladivostok.ID).Return(vladivostok, nil)
+			},
+		},
+		{
+			name: "reader in another city",
+			ctx:  asCityUser(1, newYork.ID, domain.ReadCompany),
+			setup: func(repo *mock_repository.MockDonorCompanies, cities *mock_repository.MockCities) {
+				repo.EXPECT().GetByID(gomock.Any(), company.ID).Return(company, nil)
+			},
+			wantErr: domain.Forbidden,
+		},
+		{
+			name: "not found",
+			ctx:  asUser(1, domain.ReadCompany),
+			setup: func(repo *mock_repository.MockDonorCompanies, cities *mock_repository.MockCities) {
+				repo.EXPECT().GetByID(gomock.Any(), company.ID).Return(domain.DonorCompany{}, domain.NotFound)
+			},
+			wantErr: domain.NotFound,
+		},
+		{
+			name: "repository error",
+			ctx:  asUser(1, domain.ReadCompany),
+			setup: func(repo *mock_repository.MockDonorCompanies, cities *mock_repository.MockCities) {
+				repo.EXPECT().GetByID(gomock.Any(), company.ID).Return(company, nil)
+				cities.EXPECT().GetByID(gomock.Any(), vladivostok.ID).Return(domain.City{}, errRepo)
+			},
+			wantErr: errRepo,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			repo := mock_repository.NewMockDonorCompanies(ctrl)
+			cities := mock_repository.NewMockCities(ctrl)
+			tt.setup(repo, cities)
+
+			_, err := NewDonorCompaniesService(repo, cities).GetByID(tt.ctx, company.ID)
+			checkErr(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestDonorCompaniesServiceDelete(t *testing.T) {
+	company := domain.DonorCompany{Object: domain.Object{ID: 7, Version: 4}, CityID: vladivostok.ID}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		version int64
+		setup   func(repo *mock_repository.MockDonorCompanies)
+		wantErr error
+	}{
+		{
+			name:    "editor in the city",
+			ctx:     asCityUser(1, vladivostok.ID, domain.EditCompany),
+			version: company.Version,
+			setup: func(repo *mock_repository.MockDonorCompanies) {
+				repo.EXPECT().GetByID(gomock.Any(), company.ID).Return(company, nil)
+				repo.EXPECT().Delete(gomock.Any(), company.ID, company.Version).Return(nil)
+			},
+		},
+		{
+			name:    "editor in another city",
+			ctx:     asCityUser(1, newYork.ID, domain.EditCompany),
+			version: company.Version,
+			setup: func(repo *mock_repository.MockDonorCompanies) {
+				repo.EXPECT().GetByID(gomock.Any(), company.ID).Return(company, nil)
+			},
+			wantErr: domain.Forbidden,
+		},
+		{
+			name:    "stale version",
+			ctx:     asUser(1, domain.EditCompany),
+			version: company.Version - 1,
+			setup: func(repo *mock_repository.MockDonorCompanies) {
+				repo.EXPECT().GetByID(gomock.Any(), company.ID).Return(company, nil)
+			},
+			wantErr: domain.Conflict,
+		},
+		{
+			name:    "company with acts",
+			ctx:     asUser(1, domain.EditCompany),
+			version: company.Version,
+			setup: func(repo *mock_repository.MockDonorCompanies) {
+				repo.EXPECT().GetByID(gomock.Any(), company.ID).Return(company, nil)
+				repo.EXPECT().Delete(gomock.Any(), company.ID, company.Version).Return(domain.InUse)
+			},
+			wantErr: domain.InUse,
+		},
+		{
+			name:    "not found",
+			ctx:     asUser(1, domain.EditCompany),
+			version: company.Version,
+			setup: func(repo *mock_repository.MockDonorCompanies) {
+				repo.EXPECT().GetByID(gomock.Any(), company.ID).Return(domain.DonorCompany{}, domain.NotFound)
+			},
+			wantErr: domain.NotFound,
+		},
+		{
+			name:    "repository error",
+			ctx:     asUser(1, domain.EditCompany),
+			version: company.Version,
+			setup: func(repo *mock_repository.MockDonorCompanies) {
+				repo.EXPECT().GetByID(gomock.Any(), company.ID).Return(company, nil)
+				repo.EXPECT().Delete(gomock.Any(), company.ID, company.Version).Return(errRepo)
+			},
+			wantErr: errRepo,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			repo := mock_repository.NewMockDonorCompanies(ctrl)
+			tt.setup(repo)
+
+			s := NewDonorCompaniesService(repo, mock_repository.NewMockCities(ctrl))
+			checkErr(t, s.Delete(tt.ctx, company.ID, tt.version), tt.wantErr)
+		})
+	}
+}
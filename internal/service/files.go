@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"foodsharing-backend/internal/domain"
+	"foodsharing-backend/internal/repository"
+	"foodsharing-backend/pkg/storage"
+)
+
+type FilesService struct {
+	repo      repository.Files
+	acts      repository.Acts
+	companies repository.DonorCompanies
+	storage   storage.Provider
+}
+
+func NewFilesService(repo repository.Files, acts repository.Acts, companies repository.DonorCompanies,
+	storage storage.Provider) *FilesService {
+	return &FilesService{
+		repo:      repo,
+		acts:      acts,
+		companies: companies,
+		storage:   storage,
+	}
+}
+
+func (i FileUploadInput) validate() error {
+	if i.File == nil {
+		return fmt.Errorf("%w: file is required", domain.InvalidInput)
+	}
+	if i.Name == "" {
+		return fmt.Errorf("%w: file name is required", domain.InvalidInput)
+	}
+	if i.Size <= 0 {
+		return fmt.Errorf("%w: file is empty", domain.InvalidInput)
+	}
+	return nil
+}
+
+// Upload pushes the file straight to the file storage. The file record tracks the
+// upload status so failed uploads remain visible.
+func (s *FilesService) Upload(ctx context.Context, input FileUploadInput) (domain.File, error) {
+	principal, ok := domain.PrincipalFromContext(ctx)
+	if !ok {
+		return domain.File{}, domain.Unauthorized
+	}
+	if err := input.validate(); err != nil {
+		return domain.File{}, err
+	}
+
+	file := domain.File{
+		UserID:      principal.UserID,
+		Type:        fileTypeOf(input.ContentType),
+		ContentType: input.ContentType,
+		Name:        input.Name,
+		Size:        input.Size,
+		Status:      domain.StorageUploadInProgress,
+	}
+	if err := s.repo.Create(ctx, &file); err != nil {
+		return domain.File{}, err
+	}
+
+	url, err := s.storage.Upload(ctx, storage.UploadInput{
+		File:        input.File,
+		Name:        fmt.Sprintf("%d/%s", file.ID, input.Name),
+		Size:        input.Size,
+		ContentType: input.ContentType,
+	})
+	if err != nil {
+		if statusErr := s.repo.UpdateStatus(ctx, file.ID, domain.StorageUploadError); statusErr != nil {
+			log.Printf("cannot mark file %d as failed: %v", file.ID, statusErr)
+		}
+		return domain.File{}, fmt.Errorf("cannot upload file: %w", err)
+	}
+
+	if err := s.repo.UpdateStatusAndSetURL(ctx, file.ID, url); err != nil {
+		return domain.File{}, err
+	}
+
+	file.Status = domain.UploadedToStorage
+	file.URL = url
+	return file, nil
+}
+
+// GetByID returns the file to the user who uploaded it and to the users who may read one
+// of the acts it is attached to.
+func (s *FilesService) GetByID(ctx context.Context, id domain.ID) (domain.File, error) {
+	principal, ok := domain.PrincipalFromContext(ctx)
+	if !ok {
+		return domain.File{}, domain.Unauthorized
+	}
+
+	file, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return domain.File{}, err
+	}
+	if file.UserID == principal.UserID || principal.Permissions.IsAdmin() {
+		return file, nil
+	}
+
+	acts, err := s.acts.GetFileActs(ctx, id)
+	if err != nil {
+		return domain.File{}, err
+	}
+	for _, act := range acts {
+		if act.UserID == principal.UserID {
+			return file, nil
+		}
+		company, err := s.companies.GetByID(ctx, act.DonorCompanyID)
+		if err != nil {
+			return domain.File{}, err
+		}
+		if principal.CanOn(domain.ReadAct, act.Attributes(company)) {
+			return file, nil
+		}
+	}
+	return domain.File{}, domain.Forbidden
+}
+
+func fileTypeOf(contentType string) domain.FileType {
+	switch {
+	case strings.HasPrefix(contentType, "image/"):
+		return domain.Image
+	case strings.HasPrefix(contentType, "application/pdf"),
+		strings.HasPrefix(contentType, "application/msword"),
+		strings.HasPrefix(contentType, "application/vnd.openxmlformats-officedocument"),
+		strings.HasPrefix(contentType, "text/"):
+		return domain.Document
+	default:
+		return domain.Other
+	}
+}
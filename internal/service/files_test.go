@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"foodsharing-backend/internal/domain"
+	mock_repository "foodsharing-backend/internal/repository/mocks"
+
+	"github.com/golang/mock/gomock"
+)
+
+type filesMocks struct {
+	repo      *mock_repository.MockFiles
+	acts      *mock_repository.MockActs
+	companies *mock_repository.MockDonorCompanies
+}
+
+func TestFilesServiceGetByID(t *testing.T) {
+	file := domain.File{Object: domain.Object{ID: 8}, UserID: 2, Status: domain.UploadedToStorage}
+	otherAct := domain.Act{Object: domain.Object{ID: 6}, UserID: 3, DonorCompanyID: testCompany.ID}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		setup   func(m filesMocks)
+		wantErr error
+	}{
+		{
+			name: "owner",
+			ctx:  asUser(file.UserID),
+			setup: func(m filesMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), file.ID).Return(file, nil)
+			},
+		},
+		{
+			name: "admin",
+			ctx:  asUser(1, domain.Admin),
+			setup: func(m filesMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), file.ID).Return(file, nil)
+			},
+		},
+		{
+			name: "author of an act the file is attached to",
+			ctx:  asUser(otherAct.UserID),
+			setup: func(m filesMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), file.ID).Return(file, nil)
+				m.acts.EXPECT().GetFileActs(gomock.Any(), file.ID).Return([]domain.Act{otherAct}, nil)
+			},
+		},
+		{
+			name: "reader of an act the file is attached to",
+			ctx:  asCityUser(1, vladivostok.ID, domain.ReadAct),
+			setup: func(m filesMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), file.ID).Return(file, nil)
+				m.acts.EXPECT().GetFileActs(gomock.Any(), file.ID).Return([]domain.Act{otherAct}, nil)
+				m.companies.EXPECT().GetByID(gomock.Any(), testCompany.ID).Return(testCompany, nil)
+			},
+		},
+		{
+			name: "reader of acts in another city",
+			ctx:  asCityUser(1, newYork.ID, domain.ReadAct),
+			setup: func(m filesMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), file.ID).Return(file, nil)
+				m.acts.EXPECT().GetFileActs(gomock.Any(), file.ID).Return([]domain.Act{otherAct}, nil)
+				m.companies.EXPECT().GetByID(gomock.Any(), testCompany.ID).Return(testCompany, nil)
+			},
+			wantErr: domain.Forbidden,
+		},
+		{
+			name: "file not attached to any act",
+			ctx:  asUser(1, domain.ReadAct),
+			setup: func(m filesMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), file.ID).Return(file, nil)
+				m.acts.EXPECT().GetFileActs(gomock.Any(), file.ID).Return(nil, nil)
+			},
+			wantErr: domain.Forbidden,
+		},
+		{
+			name: "not found",
+			ctx:  asUser(1),
+			setup: func(m filesMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), file.ID).Return(domain.File{}, domain.NotFound)
+			},
+			wantErr: domain.NotFound,
+		},
+		{
+			name: "repository error",
+			ctx:  asUser(1),
+			setup: func(m filesMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), file.ID).Return(file, nil)
+				m.acts.EXPECT().GetFileActs(gomock.Any(), file.ID).Return(nil, errRepo)
+			},
+			wantErr: errRepo,
+		},
+		{
+			name:    "anonymous",
+			ctx:     context.Background(),
+			setup:   func(m filesMocks) {},
+			wantErr: domain.Unauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			m := filesMocks{
+				repo:      mock_repository.NewMockFiles(ctrl),
+				acts:      mock_repository.NewMockActs(ctrl),
+				companies: mock_repository.NewMockDonorCompanies(ctrl),
+			}
+			tt.setup(m)
+
+			s := NewFilesService(m.repo, m.acts, m.companies, nil)
+			got, err := s.GetByID(tt.ctx, file.ID)
+			checkErr(t, err, tt.wantErr)
+			if err == nil && got.ID != file.ID {
+				t.Errorf("GetByID() = file %d, want %d", got.ID, file.ID)
+			}
+		})
+	}
+}
@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"foodsharing-backend/internal/domain"
+	"foodsharing-backend/internal/repository"
+)
+
+type GroupsService struct {
+	repo      repository.Groups
+	users     repository.Users
+	cities    repository.Cities
+	txManager repository.TxManager
+}
+
+func NewGroupsService(repo repository.Groups, users repository.Users, cities repository.Cities,
+	txManager repository.TxManager) *GroupsService {
+	return &GroupsService{
+		repo:      repo,
+		users:     users,
+		cities:    cities,
+		txManager: txManager,
+	}
+}
+
+func (i GroupInput) validate() error {
+	if i.Name == "" {
+		return fmt.Errorf("%w: name is required", domain.InvalidInput)
+	}
+	if i.Scope != "" && !i.Scope.IsValid() {
+		return fmt.Errorf("%w: unknown scope %q", domain.InvalidInput, i.Scope)
+	}
+	return i.Permissions.Validate()
+}
+
+func (i GroupInput) scope() domain.Scope {
+	if i.Scope == "" {
+		return domain.ScopeAll
+	}
+	return i.Scope
+}
+
+func (s *GroupsService) Create(ctx context.Context, input GroupInput) (domain.Group, error) {
+	principal, err := authorize(ctx, domain.CreateGroup)
+	if err != nil {
+		return domain.Group{}, err
+	}
+	if err := input.validate(); err != nil {
+		return domain.Group{}, err
+	}
+	inherited, err := inheritedPermissions(ctx, s.repo, 0, input.ParentID)
+	if err != nil {
+		return domain.Group{}, err
+	}
+	if err := checkCanGrant(principal, input.Permissions.Union(inherited)); err != nil {
+		return domain.Group{}, err
+	}
+
+	group := domain.Group{
+		Name:                 input.Name,
+		Permissions:          input.Permissions,
+		EffectivePermissions: input.Permissions.Union(inherited),
+		ParentID:             input.ParentID,
+		Scope:                input.scope(),
+	}
+	if err := s.repo.Create(ctx, &group); err != nil {
+		return domain.Group{}, err
+	}
+	return group, nil
+}
+
+func (s *GroupsService) Update(ctx context.Context, id domain.ID, input GroupInput, version int64) error {
+	principal, err := authorize(ctx, domain.EditGroup)
+	if err != nil {
+		return err
+	}
+	if err := input.validate(); err != nil {
+		return err
+	}
+
+	// The cycle check reads the ancestors of the new parent, so it only holds if no other
+	// update of the chain commits in between. The serializable transaction makes sure.
+	return s.txManager.WithinTx(ctx, func(repos *repository.Repositories) error {
+		group, err := repos.Groups.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := checkVersion(version, group.Version); err != nil {
+			return err
+		}
+		inherited, err := inheritedPermissions(ctx, repos.Groups, id, input.ParentID)
+		if err != nil {
+			return err
+		}
+		if err := checkCanGrant(principal, group.EffectivePermissions.Union(input.Permissions).Union(inherited)); err != nil {
+			return err
+		}
+
+		group.Name = input.Name
+		group.Permissions = input.Permissions
+		group.ParentID = input.ParentID
+		group.Scope = input.scope()
+		return repos.Groups.Update(ctx, group)
+	})
+}
+
+func (s *GroupsService) Delete(ctx context.Context, id domain.ID, version int64) error {
+	principal, err := authorize(ctx, domain.EditGroup)
+	if err != nil {
+		return err
+	}
+
+	group, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := checkVersion(version, group.Version); err != nil {
+		return err
+	}
+	if err := checkCanGrant(principal, group.EffectivePermissions); err != nil {
+		return err
+	}
+
+	return s.repo.Delete(ctx, id, group.Version)
+}
+
+func (s *GroupsService) GetByID(ctx context.Context, id domain.ID) (domain.Group, error) {
+	if _, err := authorize(ctx, domain.ReadGroup); err != nil {
+		return domain.Group{}, err
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *GroupsService) GetAll(ctx context.Context, opts domain.ListOptions) ([]domain.Group, string, error) {
+	if _, err := authorize(ctx, domain.ReadGroup); err != nil {
+		return nil, "", err
+	}
+	opts.Restriction = nil
+	return s.repo.GetAll(ctx, opts)
+}
+
+func (s *GroupsService) AddUser(ctx context.Context, groupID domain.ID, input GroupMemberInput) error {
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+		return fmt.Errorf("%w: expiry must be in the future", domain.InvalidInput)
+	}
+
+	principal, err := authorize(ctx, domain.EditGroup)
+	if err != nil {
+		return err
+	}
+
+	group, err := s.repo.GetByID(ctx, groupID)
+	if err != nil {
+		return err
+	}
+	if err := checkCanGrant(principal, group.EffectivePermissions); err != nil {
+		return err
+	}
+	if _, err := s.users.GetByID(ctx, input.UserID); err != nil {
+		if errors.Is(err, domain.NotFound) {
+			return fmt.Errorf("%w: user does not exist", domain.InvalidInput)
+		}
+		return err
+	}
+	if input.CityID != 0 {
+		if _, err := s.cities.GetByID(ctx, input.CityID); err != nil {
+			if errors.Is(err, domain.NotFound) {
+				return fmt.Errorf("%w: city does not exist", domain.InvalidInput)
+			}
+			return err
+		}
+	}
+
+	return s.repo.AddUser(ctx, domain.Member{
+		GroupID:   groupID,
+		UserID:    input.UserID,
+		CityID:    input.CityID,
+		ExpiresAt: input.ExpiresAt,
+	})
+}
+
+func (s *GroupsService) RemoveUser(ctx context.Context, groupID domain.ID, userID domain.ID,
+	cityID domain.ID) error {
+	principal, err := authorize(ctx, domain.EditGroup)
+	if err != nil {
+		return err
+	}
+
+	group, err := s.repo.GetByID(ctx, groupID)
+	if err != nil {
+		return err
+	}
+	if err := checkCanGrant(principal, group.EffectivePermissions); err != nil {
+		return err
+	}
+
+	return s.repo.RemoveUser(ctx, groupID, userID, cityID)
+}
+
+// inheritedPermissions returns the permissions group id would inherit from parentID. It
+// rejects parents that do not exist and parents that would close a cycle, that is the
+// group itself or one of its descendants. A zero id stands for a group being created.
+func inheritedPermissions(ctx context.Context, groups repository.Groups, id domain.ID,
+	parentID domain.ID) (domain.Permissions, error) {
+	if parentID == 0 {
+		return nil, nil
+	}
+
+	parent, err := groups.GetByID(ctx, parentID)
+	if err != nil {
+		if errors.Is(err, domain.NotFound) {
+			return nil, fmt.Errorf("%w: parent group does not exist", domain.InvalidInput)
+		}
+		return nil, err
+	}
+
+	if id != 0 {
+		ancestors, err := groups.GetAncestors(ctx, parentID)
+		if err != nil {
+			return nil, err
+		}
+		for _, ancestor := range ancestors {
+			if ancestor == id {
+				return nil, fmt.Errorf("%w: parent group would create a cycle", domain.InvalidInput)
+			}
+		}
+	}
+
+	return parent.EffectivePermissions, nil
+}
+
+// RemoveExpiredMembers relies on the audit log to record the removed memberships.
+func (s *GroupsService) RemoveExpiredMembers(ctx context.Context) error {
+	return s.repo.DeleteExpiredMembers(ctx, time.Now())
+}
+
+// checkCanGrant prevents privilege escalation: only admins may hand out permissions
+// they do not hold themselves.
+func checkCanGrant(principal domain.Principal, permissions domain.Permissions) error {
+	if principal.Permissions.IsAdmin() {
+		return nil
+	}
+	if len(permissions.Without(principal.Permissions)) != 0 {
+		return fmt.Errorf("%w: cannot grant permissions you do not have", domain.Forbidden)
+	}
+	return nil
+}
@@ -0,0 +1,406 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"foodsharing-backend/internal/domain"
+	"foodsharing-backend/internal/repository"
+	mock_repository "foodsharing-backend/internal/repository/mocks"
+
+	"github.com/golang/mock/gomock"
+)
+
+type groupsMocks struct {
+	repo      *mock_repository.MockGroups
+	users     *mock_repository.MockUsers
+	cities    *mock_repository.MockCities
+	txManager *mock_repository.MockTxManager
+}
+
+func newGroupsService(t *testing.T) (*GroupsService, groupsMocks) {
+	ctrl := gomock.NewController(t)
+	m := groupsMocks{
+		repo:      mock_repository.NewMockGroups(ctrl),
+		users:     mock_repository.NewMockUsers(ctrl),
+		cities:    mock_repository.NewMockCities(ctrl),
+		txManager: mock_repository.NewMockTxManager(ctrl),
+	}
+	m.txManager.EXPECT().WithinTx(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(*repository.Repositories) error) error {
+			return fn(&repository.Repositories{Groups: m.repo, Users: m.users})
+		}).AnyTimes()
+	return NewGroupsService(m.repo, m.users, m.cities, m.txManager), m
+}
+
+var testGroup = domain.Group{
+	Object:               domain.Object{ID: 4, Version: 2},
+	Name:                 "Volunteers",
+	Permissions:          domain.NewPermissions(domain.CreateAct),
+	EffectivePermissions: domain.NewPermissions(domain.CreateAct),
+	Scope:                domain.ScopeAll,
+}
+
+func TestGroupsServiceCreate(t *testing.T) {
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		input   GroupInput
+		setup   func(m groupsMocks)
+		wantErr error
+	}{
+		{
+			name:  "permissions the creator holds",
+			ctx:   asUser(1, domain.CreateGroup, domain.CreateAct),
+			input: GroupInput{Name: "Volunteers", Permissions: domain.NewPermissions(domain.CreateAct)},
+			setup: func(m groupsMocks) {
+				m.repo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, group *domain.Group) error {
+						if group.Scope != domain.ScopeAll {
+							t.Errorf("Create() scope = %q, want %q", group.Scope, domain.ScopeAll)
+						}
+						return nil
+					})
+			},
+		},
+		{
+			name:    "permissions the creator lacks",
+			ctx:     asUser(1, domain.CreateGroup),
+			input:   GroupInput{Name: "Editors", Permissions: domain.NewPermissions(domain.EditAct)},
+			setup:   func(m groupsMocks) {},
+			wantErr: domain.Forbidden,
+		},
+		{
+			name:  "permissions the creator lacks inherited from the parent",
+			ctx:   asUser(1, domain.CreateGroup, domain.CreateAct),
+			input: GroupInput{Name: "Editors", ParentID: 3},
+			setup: func(m groupsMocks) {
+				parent := domain.Group{Object: domain.Object{ID: 3}, EffectivePermissions: domain.NewPermissions(domain.EditAct)}
+				m.repo.EXPECT().GetByID(gomock.Any(), parent.ID).Return(parent, nil)
+			},
+			wantErr: domain.Forbidden,
+		},
+		{
+			name:  "unknown parent",
+			ctx:   asUser(1, domain.CreateGroup),
+			input: GroupInput{Name: "Editors", ParentID: 3},
+			setup: func(m groupsMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), domain.ID(3)).Return(domain.Group{}, domain.NotFound)
+			},
+			wantErr: domain.InvalidInput,
+		},
+		{
+			name:    "without CreateGroup",
+			ctx:     asUser(1, domain.ReadGroup),
+			input:   GroupInput{Name: "Volunteers"},
+			setup:   func(m groupsMocks) {},
+			wantErr: domain.Forbidden,
+		},
+		{
+			name:    "unknown scope",
+			ctx:     asUser(1, domain.CreateGroup),
+			input:   GroupInput{Name: "Volunteers", Scope: "region"},
+			setup:   func(m groupsMocks) {},
+			wantErr: domain.InvalidInput,
+		},
+		{
+			name:    "unknown permission",
+			ctx:     asUser(1, domain.Admin),
+			input:   GroupInput{Name: "Volunteers", Permissions: domain.NewPermissions("acts:delete")},
+			setup:   func(m groupsMocks) {},
+			wantErr: domain.InvalidInput,
+		},
+		{
+			name:    "missing name",
+			ctx:     asUser(1, domain.CreateGroup),
+			input:   GroupInput{},
+			setup:   func(m groupsMocks) {},
+			wantErr: domain.InvalidInput,
+		},
+		{
+			name:  "repository error",
+			ctx:   asUser(1, domain.CreateGroup),
+			input: GroupInput{Name: "Volunteers"},
+			setup: func(m groupsMocks) {
+				m.repo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(errRepo)
+			},
+			wantErr: errRepo,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m := newGroupsService(t)
+			tt.setup(m)
+
+			_, err := s.Create(tt.ctx, tt.input)
+			checkErr(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestGroupsServiceUpdate(t *testing.T) {
+	parent := domain.Group{Object: domain.Object{ID: 3}, EffectivePermissions: domain.NewPermissions(domain.EditAct)}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		input   GroupInput
+		version int64
+		setup   func(m groupsMocks)
+		wantErr error
+	}{
+		{
+			name:    "new parent",
+			ctx:     asUser(1, domain.EditGroup, domain.CreateAct, domain.EditAct),
+			input:   GroupInput{Name: "Volunteers", Permissions: testGroup.Permissions, ParentID: parent.ID},
+			version: testGroup.Version,
+			setup: func(m groupsMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), testGroup.ID).Return(testGroup, nil)
+				m.repo.EXPECT().GetByID(gomock.Any(), parent.ID).Return(parent, nil)
+				m.repo.EXPECT().GetAncestors(gomock.Any(), parent.ID).Return([]domain.ID{parent.ID}, nil)
+				m.repo.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(ctx context.Context, group domain.Group) error {
+						if group.ParentID != parent.ID || group.Version != testGroup.Version {
+							t.Errorf("Update() got %+v", group)
+						}
+						return nil
+					})
+			},
+		},
+		{
+			name:    "parent that descends from the group",
+			ctx:     asUser(1, domain.Admin),
+			input:   GroupInput{Name: "Volunteers", ParentID: parent.ID},
+			version: testGroup.Version,
+			setup: func(m groupsMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), testGroup.ID).Return(testGroup, nil)
+				m.repo.EXPECT().GetByID(gomock.Any(), parent.ID).Return(parent, nil)
+				m.repo.EXPECT().GetAncestors(gomock.Any(), parent.ID).Return([]domain.ID{parent.ID, testGroup.ID}, nil)
+			},
+			wantErr: domain.InvalidInput,
+		},
+		{
+			name:    "group as its own parent",
+			ctx:     asUser(1, domain.Admin),
+			input:   GroupInput{Name: "Volunteers", ParentID: testGroup.ID},
+			version: testGroup.Version,
+			setup: func(m groupsMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), testGroup.ID).Return(testGroup, nil).Times(2)
+				m.repo.EXPECT().GetAncestors(gomock.Any(), testGroup.ID).Return([]domain.ID{testGroup.ID}, nil)
+			},
+			wantErr: domain.InvalidInput,
+		},
+		{
+			name:    "stale version",
+			ctx:     asUser(1, domain.Admin),
+			input:   GroupInput{Name: "Volunteers"},
+			version: testGroup.Version - 1,
+			setup: func(m groupsMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), testGroup.ID).Return(testGroup, nil)
+			},
+			wantErr: domain.Conflict,
+		},
+		{
+			name:    "permissions the editor lacks inherited from the parent",
+			ctx:     asUser(1, domain.EditGroup, domain.CreateAct),
+			input:   GroupInput{Name: "Volunteers", ParentID: parent.ID},
+			version: testGroup.Version,
+			setup: func(m groupsMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), testGroup.ID).Return(testGroup, nil)
+				m.repo.EXPECT().GetByID(gomock.Any(), parent.ID).Return(parent, nil)
+				m.repo.EXPECT().GetAncestors(gomock.Any(), parent.ID).Return([]domain.ID{parent.ID}, nil)
+			},
+			wantErr: domain.Forbidden,
+		},
+		{
+			name:    "without EditGroup",
+			ctx:     asUser(1, domain.ReadGroup),
+			input:   GroupInput{Name: "Volunteers"},
+			version: testGroup.Version,
+			setup:   func(m groupsMocks) {},
+			wantErr: domain.Forbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m := newGroupsService(t)
+			tt.setup(m)
+
+			checkErr(t, s.Update(tt.ctx, testGroup.ID, tt.input, tt.version), tt.wantErr)
+		})
+	}
+}
+
+func TestGroupsServiceDelete(t *testing.T) {
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		version int64
+		setup   func(m groupsMocks)
+		wantErr error
+	}{
+		{
+			name:    "editor holding the permissions of the group",
+			ctx:     asUser(1, domain.EditGroup, domain.CreateAct),
+			version: testGroup.Version,
+			setup: func(m groupsMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), testGroup.ID).Return(testGroup, nil)
+				m.repo.EXPECT().Delete(gomock.Any(), testGroup.ID, testGroup.Version).Return(nil)
+			},
+		},
+		{
+			name:    "editor lacking the permissions of the group",
+			ctx:     asUser(1, domain.EditGroup),
+			version: testGroup.Version,
+			setup: func(m groupsMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), testGroup.ID).Return(testGroup, nil)
+			},
+			wantErr: domain.Forbidden,
+		},
+		{
+			name:    "without EditGroup",
+			ctx:     asUser(1, domain.ReadGroup),
+			version: testGroup.Version,
+			setup:   func(m groupsMocks) {},
+			wantErr: domain.Forbidden,
+		},
+		{
+			name:    "stale version",
+			ctx:     asUser(1, domain.Admin),
+			version: testGroup.Version - 1,
+			setup: func(m groupsMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), testGroup.ID).Return(testGroup, nil)
+			},
+			wantErr: domain.Conflict,
+		},
+		{
+			name:    "group with members",
+			ctx:     asUser(1, domain.Admin),
+			version: testGroup.Version,
+			setup: func(m groupsMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), testGroup.ID).Return(testGroup, nil)
+				m.repo.EXPECT().Delete(gomock.Any(), testGroup.ID, testGroup.Version).Return(domain.InUse)
+			},
+			wantErr: domain.InUse,
+		},
+		{
+			name:    "not found",
+			ctx:     asUser(1, domain.Admin),
+			version: testGroup.Version,
+			setup: func(m groupsMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), testGroup.ID).Return(domain.Group{}, domain.NotFound)
+			},
+			wantErr: domain.NotFound,
+		},
+		{
+			name:    "repository error",
+			ctx:     asUser(1, domain.Admin),
+			version: testGroup.Version,
+			setup: func(m groupsMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), testGroup.ID).Return(domain.Group{}, errRepo)
+			},
+			wantErr: errRepo,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m := newGroupsService(t)
+			tt.setup(m)
+
+			checkErr(t, s.Delete(tt.ctx, testGroup.ID, tt.version), tt.wantErr)
+		})
+	}
+}
+
+func TestGroupsServiceAddUser(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		input   GroupMemberInput
+		setup   func(m groupsMocks)
+		wantErr error
+	}{
+		{
+			name: "editor holding the permissions of the group",
+			ctx:  asUser(1, domain.EditGroup, domain.CreateAct),
+			setup: func(m groupsMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), testGroup.ID).Return(testGroup, nil)
+				m.users.EXPECT().GetByID(gomock.Any(), testUser.ID).Return(testUser, nil)
+				m.repo.EXPECT().AddUser(gomock.Any(), domain.Member{GroupID: testGroup.ID, UserID: testUser.ID}).Return(nil)
+			},
+		},
+		{
+			name: "editor lacking the permissions of the group",
+			ctx:  asUser(1, domain.EditGroup),
+			setup: func(m groupsMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), testGroup.ID).Return(testGroup, nil)
+			},
+			wantErr: domain.Forbidden,
+		},
+		{
+			name: "unknown user",
+			ctx:  asUser(1, domain.Admin),
+			setup: func(m groupsMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), testGroup.ID).Return(testGroup, nil)
+				m.users.EXPECT().GetByID(gomock.Any(), testUser.ID).Return(domain.User{}, domain.NotFound)
+			},
+			wantErr: domain.InvalidInput,
+		},
+		{
+			name:  "limited to a city",
+			ctx:   asUser(1, domain.Admin),
+			input: GroupMemberInput{CityID: vladivostok.ID},
+			setup: func(m groupsMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), testGroup.ID).Return(testGroup, nil)
+				m.users.EXPECT().GetByID(gomock.Any(), testUser.ID).Return(testUser, nil)
+				m.cities.EXPECT().GetByID(gomock.Any(), vladivostok.ID).Return(vladivostok, nil)
+				m.repo.EXPECT().AddUser(gomock.Any(),
+					domain.Member{GroupID: testGroup.ID, UserID: testUser.ID, CityID: vladivostok.ID}).Return(nil)
+			},
+		},
+		{
+			name:  "unknown city",
+			ctx:   asUser(1, domain.Admin),
+			input: GroupMemberInput{CityID: 30},
+			setup: func(m groupsMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), testGroup.ID).Return(testGroup, nil)
+				m.users.EXPECT().GetByID(gomock.Any(), testUser.ID).Return(testUser, nil)
+				m.cities.EXPECT().GetByID(gomock.Any(), domain.ID(30)).Return(domain.City{}, domain.NotFound)
+			},
+			wantErr: domain.InvalidInput,
+		},
+		{
+			name: "repository error",
+			ctx:  asUser(1, domain.Admin),
+			setup: func(m groupsMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), testGroup.ID).Return(testGroup, nil)
+				m.users.EXPECT().GetByID(gomock.Any(), testUser.ID).Return(testUser, nil)
+				m.repo.EXPECT().AddUser(gomock.Any(), domain.Member{GroupID: testGroup.ID, UserID: testUser.ID}).Return(errRepo)
+			},
+			wantErr: errRepo,
+		},
+		{
+			name:    "expiry in the past",
+			ctx:     asUser(1, domain.Admin),
+			input:   GroupMemberInput{ExpiresAt: &past},
+			setup:   func(m groupsMocks) {},
+			wantErr: domain.InvalidInput,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m := newGroupsService(t)
+			tt.setup(m)
+
+			tt.input.UserID = testUser.ID
+			checkErr(t, s.AddUser(tt.ctx, testGroup.ID, tt.input), tt.wantErr)
+		})
+	}
+}
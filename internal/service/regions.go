@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"foodsharing-backend/internal/domain"
+	"foodsharing-backend/internal/repository"
+)
+
+type RegionsService struct {
+	repo repository.Regions
+}
+
+func NewRegionsService(repo repository.Regions) *RegionsService {
+	return &RegionsService{repo: repo}
+}
+
+func (i RegionInput) validate() error {
+	if strings.TrimSpace(i.Name) == "" {
+		return fmt.Errorf("%w: name is required", domain.InvalidInput)
+	}
+	return nil
+}
+
+func (s *RegionsService) Create(ctx context.Context, input RegionInput) (domain.Region, error) {
+	if _, err := authorize(ctx, domain.AddCity); err != nil {
+		return domain.Region{}, err
+	}
+	if err := input.validate(); err != nil {
+		return domain.Region{}, err
+	}
+
+	region := domain.Region{Name: strings.TrimSpace(input.Name)}
+	if err := s.repo.Create(ctx, &region); err != nil {
+		return domain.Region{}, err
+	}
+	return region, nil
+}
+
+func (s *RegionsService) Update(ctx context.Context, id domain.ID, input RegionInput, version int64) error {
+	if _, err := authorize(ctx, domain.EditCity); err != nil {
+		return err
+	}
+	if err := input.validate(); err != nil {
+		return err
+	}
+
+	region, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := checkVersion(version, region.Version); err != nil {
+		return err
+	}
+	region.Name = strings.TrimSpace(input.Name)
+	return s.repo.Update(ctx, region)
+}
+
+// Delete fails with InUse while cities belong to the region.
+func (s *RegionsService) Delete(ctx context.Context, id domain.ID, version int64) error {
+	if _, err := authorize(ctx, domain.EditCity); err != nil {
+		return err
+	}
+
+	region, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := checkVersion(version, region.Version); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, id, region.Version)
+}
+
+func (s *RegionsService) GetByID(ctx context.Context, id domain.ID) (domain.Region, error) {
+	if _, err := authorize(ctx, domain.ReadCity); err != nil {
+		return domain.Region{}, err
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *RegionsService) GetAll(ctx context.Context, opts domain.ListOptions) ([]domain.Region, string, error) {
+	if _, err := authorize(ctx, domain.ReadCity); err != nil {
+		return nil, "", err
+	}
+	opts.Restriction = nil
+	return s.repo.GetAll(ctx, opts)
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"foodsharing-backend/internal/domain"
+	mock_repository "foodsharing-backend/internal/repository/mocks"
+
+	"github.com/golang/mock/gomock"
+)
+
+func TestRegionsService(t *testing.T) {
+	region := domain.Region{Object: domain.Object{ID: 3, Version: 2}, Name: "Primorye"}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		call    func(s *RegionsService, ctx context.Context) error
+		setup   func(repo *mock_repository.MockRegions)
+		wantErr error
+	}{
+		{
+			name: "get",
+			ctx:  asUser(1, domain.ReadCity),
+			call: func(s *RegionsService, ctx context.Context) error {
+				_, err := s.GetByID(ctx, region.ID)
+				return err
+			},
+			setup: func(repo *mock_repository.MockRegions) {
+				repo.EXPECT().GetByID(gomock.Any(), region.ID).Return(region, nil)
+			},
+		},
+		{
+			name: "get with city scoped permission",
+			ctx:  asCityUser(1, 10, domain.ReadCity),
+			call: func(s *RegionsService, ctx context.Context) error {
+				_, err := s.GetByID(ctx, region.ID)
+				return err
+			},
+			setup:   func(repo *mock_repository.MockRegions) {},
+			wantErr: domain.Forbidden,
+		},
+		{
+			name: "get missing",
+			ctx:  asUser(1, domain.ReadCity),
+			call: func(s *RegionsService, ctx context.Context) error {
+				_, err := s.GetByID(ctx, region.ID)
+				return err
+			},
+			setup: func(repo *mock_repository.MockRegions) {
+				repo.EXPECT().GetByID(gomock.Any(), region.ID).Return(domain.Region{}, domain.NotFound)
+			},
+			wantErr: domain.NotFound,
+		},
+		{
+			name: "update",
+			ctx:  asUser(1, domain.EditCity),
+			call: func(s *RegionsService, ctx context.Context) error {
+				return s.Update(ctx, region.ID, RegionInput{Name: " Primorsky Krai "}, region.Version)
+			},
+			setup: func(repo *mock_repository.MockRegions) {
+				repo.EXPECT().GetByID(gomock.Any(), region.ID).Return(region, nil)
+				updated := region
+				updated.Name = "Primorsky Krai"
+				repo.EXPECT().Update(gomock.Any(), updated).Return(nil)
+			},
+		},
+		{
+			name: "update stale version",
+			ctx:  asUser(1, domain.EditCity),
+			call: func(s *RegionsService, ctx context.Context) error {
+				return s.Update(ctx, region.ID, RegionInput{Name: "Primorsky Krai"}, region.Version+1)
+			},
+			setup: func(repo *mock_repository.MockRegions) {
+				repo.EXPECT().GetByID(gomock.Any(), region.ID).Return(region, nil)
+			},
+			wantErr: domain.Conflict,
+		},
+		{
+			name: "update without a name",
+			ctx:  asUser(1, domain.EditCity),
+			call: func(s *RegionsService, ctx context.Context) error {
+				return s.Update(ctx, region.ID, RegionInput{Name: " "}, region.Version)
+			},
+			setup:   func(repo *mock_repository.MockRegions) {},
+			wantErr: domain.InvalidInput,
+		},
+		{
+			name: "delete",
+			ctx:  asUser(1, domain.EditCity),
+			call: func(s *RegionsService, ctx context.Context) error {
+				return s.Delete(ctx, region.ID, region.Version)
+			},
+			setup: func(repo *mock_repository.MockRegions) {
+				repo.EXPECT().GetByID(gomock.Any(), region.ID).Return(region, nil)
+				repo.EXPECT().Delete(gomock.Any(), region.ID, region.Version).Return(nil)
+			},
+		},
+		{
+			name: "delete region with cities",
+			ctx:  asUser(1, domain.EditCity),
+			call: func(s *RegionsService, ctx context.Context) error {
+				return s.Delete(ctx, region.ID, region.Version)
+			},
+			setup: func(repo *mock_repository.MockRegions) {
+				repo.EXPECT().GetByID(gomock.Any(), region.ID).Return(region, nil)
+				repo.EXPECT().Delete(gomock.Any(), region.ID, region.Version).Return(domain.InUse)
+			},
+			wantErr: domain.InUse,
+		},
+		{
+			name: "delete without permission",
+			ctx:  asUser(1, domain.ReadCity),
+			call: func(s *RegionsService, ctx context.Context) error {
+				return s.Delete(ctx, region.ID, region.Version)
+			},
+			setup:   func(repo *mock_repository.MockRegions) {},
+			wantErr: domain.Forbidden,
+		},
+		{
+			name: "repository error",
+			ctx:  asUser(1, domain.ReadCity),
+			call: func(s *RegionsService, ctx context.Context) error {
+				_, _, err := s.GetAll(ctx, domain.ListOptions{})
+				return err
+			},
+			setup: func(repo *mock_repository.MockRegions) {
+				repo.EXPECT().GetAll(gomock.Any(), gomock.Any()).Return(nil, "", errRepo)
+			},
+			wantErr: errRepo,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mock_repository.NewMockRegions(gomock.NewController(t))
+			tt.setup(repo)
+
+			checkErr(t, tt.call(NewRegionsService(repo), tt.ctx), tt.wantErr)
+		})
+	}
+}
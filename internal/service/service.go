@@ -0,0 +1,457 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"foodsharing-backend/internal/domain"
+	"foodsharing-backend/internal/repository"
+	"foodsharing-backend/pkg/auth"
+	"foodsharing-backend/pkg/email"
+	"foodsharing-backend/pkg/hash"
+	"foodsharing-backend/pkg/storage"
+)
+
+type UserInput struct {
+	Surname     string    `json:"surname"`
+	Name        string    `json:"name"`
+	Patronymic  string    `json:"patronymic"`
+	DateOfBirth time.Time `json:"date_of_birth"`
+	PhoneNumber string    `json:"phone_number"`
+	Email       string    `json:"email"`
+	CityID      domain.ID `json:"city_id"`
+}
+
+type Users interface {
+	Create(ctx context.Context, input UserInput) (domain.User, error)
+	// Update fails with Conflict when version, the version of the user the update is based on,
+	// is not the current one. Zero skips the check. The same goes for the other updates and
+	// deletes.
+	Update(ctx context.Context, id domain.ID, input UserInput, version int64) error
+	// Delete keeps the user until the trash is purged, admins can restore it before that.
+	// The same goes for donor companies and acts.
+	Delete(ctx context.Context, id domain.ID, version int64) error
+	Restore(ctx context.Context, id domain.ID) error
+
+	GetByID(ctx context.Context, id domain.ID) (domain.User, error)
+	GetAll(ctx context.Context, opts domain.ListOptions) ([]domain.User, string, error)
+	// GetDeleted lists the deleted users to admins.
+	GetDeleted(ctx context.Context, opts domain.ListOptions) ([]domain.User, string, error)
+	GetGroups(ctx context.Context, id domain.ID) ([]domain.Membership, error)
+	ExplainPermission(ctx context.Context, id domain.ID, input PermissionQuery) (domain.PermissionExplanation, error)
+}
+
+// PermissionQuery asks whether a user may perform Action on Resource. CityID and OwnerID
+// describe the record, leaving both zero asks about any record.
+type PermissionQuery struct {
+	Action   domain.Action
+	Resource domain.Resource
+	CityID   domain.ID
+	OwnerID  domain.ID
+}
+
+// GroupInput describes a group. An empty Scope means domain.ScopeAll, a zero ParentID a
+// group without a parent.
+type GroupInput struct {
+	Name        string             `json:"name"`
+	Permissions domain.Permissions `json:"permissions"`
+	Scope       domain.Scope       `json:"scope"`
+	ParentID    domain.ID          `json:"parent_id"`
+}
+
+// GroupMemberInput adds a user to a group. A non-zero CityID limits the permissions of the
+// group to that city, ExpiresAt makes the membership temporary.
+type GroupMemberInput struct {
+	UserID    domain.ID  `json:"user_id"`
+	CityID    domain.ID  `json:"city_id"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type Groups interface {
+	Create(ctx context.Context, input GroupInput) (domain.Group, error)
+	Update(ctx context.Context, id domain.ID, input GroupInput, version int64) error
+	Delete(ctx context.Context, id domain.ID, version int64) error
+
+	GetByID(ctx context.Context, id domain.ID) (domain.Group, error)
+	GetAll(ctx context.Context, opts domain.ListOptions) ([]domain.Group, string, error)
+
+	AddUser(ctx context.Context, groupID domain.ID, input GroupMemberInput) error
+	RemoveUser(ctx context.Context, groupID domain.ID, userID domain.ID, cityID domain.ID) error
+	// RemoveExpiredMembers drops the memberships that have expired. It is run by a background
+	// job and does not check the permissions of the caller.
+	RemoveExpiredMembers(ctx context.Context) error
+}
+
+// CityInput describes a city. Timezone must be an IANA name, RegionID and Coordinates
+// are optional.
+type CityInput struct {
+	Name        string              `json:"name"`
+	RegionID    domain.ID           `json:"region_id"`
+	Timezone    string              `json:"timezone"`
+	Coordinates *domain.Coordinates `json:"coordinates"`
+}
+
+type Cities interface {
+	Create(ctx context.Context, input CityInput) (domain.City, error)
+	Update(ctx context.Context, id domain.ID, input CityInput, version int64) error
+	Delete(ctx context.Context, id domain.ID, version int64) error
+
+	GetByID(ctx context.Context, id domain.ID) (domain.City, error)
+	GetAll(ctx context.Context, opts domain.ListOptions) ([]domain.CitySummary, string, error)
+}
+
+type RegionInput struct {
+	Name string `json:"name"`
+}
+
+// Regions are managed with the city permissions. Only permissions for all records count,
+// regions span cities.
+type Regions interface {
+	Create(ctx context.Context, input RegionInput) (domain.Region, error)
+	Update(ctx context.Context, id domain.ID, input RegionInput, version int64) error
+	Delete(ctx context.Context, id domain.ID, version int64) error
+
+	GetByID(ctx context.Context, id domain.ID) (domain.Region, error)
+	GetAll(ctx context.Context, opts domain.ListOptions) ([]domain.Region, string, error)
+}
+
+type DonorCompanyInput struct {
+	Name           string    `json:"name"`
+	CityID         domain.ID `json:"city_id"`
+	ContractDate   time.Time `json:"contract_date"`
+	ContractNumber int       `json:"contract_number"`
+}
+
+type DonorCompanies interface {
+	Create(ctx context.Context, input DonorCompanyInput) (domain.DonorCompany, error)
+	Update(ctx context.Context, id domain.ID, input DonorCompanyInput, version int64) error
+	Delete(ctx context.Context, id domain.ID, version int64) error
+	Restore(ctx context.Context, id domain.ID) error
+
+	GetByID(ctx context.Context, id domain.ID) (domain.DonorCompany, error)
+	GetAll(ctx context.Context, opts domain.ListOptions) ([]domain.DonorCompany, string, error)
+	GetDeleted(ctx context.Context, opts domain.ListOptions) ([]domain.DonorCompany, string, error)
+}
+
+type ActContentInput struct {
+	Number         int       `json:"number"`
+	Name           string    `json:"name"`
+	Count          int       `json:"count"`
+	Price          int       `json:"price"`
+	ExpirationDate time.Time `json:"expiration_date"`
+	Comment        string    `json:"comment"`
+}
+
+// ActInput describes a new act. The act is created on behalf of the current user
+// together with its contents and links to already uploaded files.
+type ActInput struct {
+	DonorCompanyID domain.ID         `json:"donor_company_id"`
+	Contents       []ActContentInput `json:"contents"`
+	FileIDs        []domain.ID       `json:"file_ids"`
+}
+
+type ActUpdateInput struct {
+	DonorCompanyID domain.ID `json:"donor_company_id"`
+}
+
+type Acts interface {
+	Create(ctx context.Context, input ActInput) (domain.Act, error)
+	Update(ctx context.Context, id domain.ID, input ActUpdateInput, version int64) error
+	Delete(ctx context.Context, id domain.ID, version int64) error
+	Restore(ctx context.Context, id domain.ID) error
+
+	GetByID(ctx context.Context, id domain.ID) (domain.Act, error)
+	GetAll(ctx context.Context, opts domain.ListOptions) ([]domain.Act, string, error)
+	GetDeleted(ctx context.Context, opts domain.ListOptions) ([]domain.Act, string, error)
+
+	GetContents(ctx context.Context, actID domain.ID) ([]domain.ActContent, error)
+	AddContents(ctx context.Context, actID domain.ID, inputs ...ActContentInput) error
+	UpdateContent(ctx context.Context, actID domain.ID, contentID domain.ID, input ActContentInput,
+		version int64) error
+	DeleteContent(ctx context.Context, actID domain.ID, contentID domain.ID, version int64) error
+
+	GetFiles(ctx context.Context, actID domain.ID) ([]domain.File, error)
+	AddFile(ctx context.Context, actID domain.ID, fileID domain.ID) error
+	RemoveFile(ctx context.Context, actID domain.ID, fileID domain.ID) error
+}
+
+type FileUploadInput struct {
+	File        io.Reader
+	Name        string
+	Size        int64
+	ContentType string
+}
+
+type Files interface {
+	Upload(ctx context.Context, input FileUploadInput) (domain.File, error)
+	GetByID(ctx context.Context, id domain.ID) (domain.File, error)
+}
+
+// DeviceInfo describes the client a session was started or refreshed from.
+type DeviceInfo struct {
+	UserAgent string
+	IP        string
+}
+
+type Tokens struct {
+	AccessToken  string `json:"access_token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
+
+// SignInResult holds either the tokens of a new session or, if the account is protected
+// by two-factor authentication, a short-lived TwoFactorToken for SignInTwoFactor.
+// TwoFactorEnrollmentRequired is set when the user has to enroll before signing in.
+type SignInResult struct {
+	Tokens
+	TwoFactorToken              string `json:"two_factor_token,omitempty"`
+	TwoFactorEnrollmentRequired bool   `json:"two_factor_enrollment_required,omitempty"`
+	// RecoveryCodes are returned once, when the enrollment is completed at sign-in.
+	RecoveryCodes []string `json:"recovery_codes,omitempty"`
+}
+
+type SignUpInput struct {
+	UserInput
+	Password string `json:"password"`
+}
+
+type SignInInput struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type ChangePasswordInput struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+type ResetPasswordInput struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+// TwoFactorCodeInput is either a code of the authenticator app or a recovery code.
+type TwoFactorCodeInput struct {
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+type SignInTwoFactorInput struct {
+	TwoFactorToken string `json:"two_factor_token"`
+	TwoFactorCodeInput
+}
+
+// TwoFactorEnrollment is what the user needs to set up an authenticator app, the
+// ProvisioningURI is meant to be shown as a QR code.
+type TwoFactorEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+type Auth interface {
+	SignUp(ctx context.Context, input SignUpInput, device DeviceInfo) (Tokens, error)
+	SignIn(ctx context.Context, input SignInInput, device DeviceInfo) (SignInResult, error)
+	// SignInTwoFactor completes a sign-in with a two-factor code. Users who had to enroll at
+	// sign-in confirm their enrollment with it and get their recovery codes.
+	SignInTwoFactor(ctx context.Context, input SignInTwoFactorInput, device DeviceInfo) (SignInResult, error)
+	EnrollTwoFactorOnSignIn(ctx context.Context, twoFactorToken string) (TwoFactorEnrollment, error)
+	// ChangePassword replaces the password of the current user and signs them out everywhere.
+	ChangePassword(ctx context.Context, input ChangePasswordInput) error
+
+	// RequestPasswordReset mails a password reset link in the background. Unknown emails and
+	// failures to send look the same to the caller, so that it cannot be used to find out who
+	// is registered. Requests are limited per email and per address.
+	RequestPasswordReset(ctx context.Context, email string, device DeviceInfo) error
+	ResetPassword(ctx context.Context, input ResetPasswordInput) error
+	// RequestEmailVerification mails a verification link to the current user.
+	RequestEmailVerification(ctx context.Context) error
+	VerifyEmail(ctx context.Context, token string) error
+
+	// EnrollTwoFactor starts enabling two-factor authentication for the current user, it takes
+	// effect once ConfirmTwoFactor returns the recovery codes.
+	EnrollTwoFactor(ctx context.Context) (TwoFactorEnrollment, error)
+	// ConfirmTwoFactor and DisableTwoFactor count wrong codes towards the sign-in lockout.
+	ConfirmTwoFactor(ctx context.Context, code string, device DeviceInfo) ([]string, error)
+	// DisableTwoFactor is refused to users whose permissions make two-factor authentication
+	// mandatory.
+	DisableTwoFactor(ctx context.Context, input TwoFactorCodeInput, device DeviceInfo) error
+	// ResetTwoFactor removes two-factor authentication of a user who lost both the
+	// authenticator and the recovery codes.
+	ResetTwoFactor(ctx context.Context, userID domain.ID) error
+
+	// Identify resolves the principal of a user with the permissions of all groups
+	// the user belongs to.
+	Identify(ctx context.Context, userID domain.ID) (domain.Principal, error)
+
+	CreateSession(ctx context.Context, userID domain.ID, device DeviceInfo) (Tokens, error)
+	RefreshTokens(ctx context.Context, refreshToken string, device DeviceInfo) (Tokens, error)
+	Authenticate(ctx context.Context, accessToken string) (domain.Principal, error)
+
+	// GetSessions lists the signed in devices of the user. Revoking a session stops its
+	// refresh token from working, already issued access tokens stay valid until they expire.
+	GetSessions(ctx context.Context, userID domain.ID) ([]domain.Session, error)
+	RevokeSession(ctx context.Context, userID domain.ID, sessionID string) error
+	RevokeAllSessions(ctx context.Context, userID domain.ID) error
+
+	// GetLockout shows the failed sign-ins of a user, Unlock forgets them and lifts the lock
+	// of the user and of the address the last failure came from.
+	GetLockout(ctx context.Context, userID domain.ID) (domain.LoginAttempts, error)
+	Unlock(ctx context.Context, userID domain.ID) error
+
+	// Stop waits for the work left running in the background, for use on shutdown.
+	Stop(ctx context.Context) error
+}
+
+// Authorizer decides what users may do. The effective permissions of a user are the
+// union of the permissions of all their groups, each limited to the scope of the group.
+// Admin grants everything within its scope.
+type Authorizer interface {
+	Identify(ctx context.Context, user domain.User) (domain.Principal, error)
+	// Can reports whether the user may perform the action on at least some records.
+	Can(user domain.Principal, action domain.Action, resource domain.Resource) bool
+	// CanOn reports whether the user may perform the action on a record with the attributes.
+	CanOn(user domain.Principal, action domain.Action, resource domain.Resource, attrs domain.Attributes) bool
+	// Explain tells which groups of the user make the decision on the action, for a record
+	// with the attributes or for any record when they are zero.
+	Explain(ctx context.Context, user domain.User, action domain.Action, resource domain.Resource,
+		record domain.Attributes) (domain.PermissionExplanation, error)
+}
+
+type Services struct {
+	Authorizer     Authorizer
+	Users          Users
+	Groups         Groups
+	Cities         Cities
+	Regions        Regions
+	DonorCompanies DonorCompanies
+	Acts           Acts
+	Files          Files
+	Auth           Auth
+	Trash          Trash
+	Audit          Audit
+}
+
+// Audit reads the log of the changes, which the repositories record on their own.
+type Audit interface {
+	GetAll(ctx context.Context, opts domain.ListOptions) ([]domain.AuditEntry, string, error)
+}
+
+type Trash interface {
+	// Purge removes the deleted records that have been kept for the retention period. It is
+	// run by a background job and does not check the permissions of the caller.
+	Purge(ctx context.Context) error
+}
+
+type Deps struct {
+	Repos          *repository.Repositories
+	Storage        storage.Provider
+	Mailer         email.Mailer
+	TokenManager   auth.TokenManager
+	PasswordHasher hash.PasswordHasher
+	AuthConfig     AuthConfig
+	// DeletedRetention is how long deleted records are kept before they are purged.
+	DeletedRetention time.Duration
+}
+
+func NewServices(deps Deps) *Services {
+	authorizer := NewAuthorizationService(deps.Repos.Groups)
+
+	return &Services{
+		Authorizer:     authorizer,
+		Users:          NewUsersService(deps.Repos.Users, deps.Repos.Groups, authorizer),
+		Groups:         NewGroupsService(deps.Repos.Groups, deps.Repos.Users, deps.Repos.Cities, deps.Repos.TxManager),
+		Cities:         NewCitiesService(deps.Repos.Cities, deps.Repos.Regions),
+		Regions:        NewRegionsService(deps.Repos.Regions),
+		DonorCompanies: NewDonorCompaniesService(deps.Repos.DonorCompanies, deps.Repos.Cities),
+		Acts: NewActsService(deps.Repos.Acts, deps.Repos.ActContents, deps.Repos.DonorCompanies,
+			deps.Repos.Cities, deps.Repos.Files, deps.Repos.Users, deps.Repos.TxManager),
+		Files: NewFilesService(deps.Repos.Files, deps.Repos.Acts, deps.Repos.DonorCompanies, deps.Storage),
+		Auth: NewAuthService(deps.Repos, authorizer, deps.TokenManager, deps.PasswordHasher, deps.Mailer,
+			deps.AuthConfig),
+		Trash: NewTrashService(deps.Repos.TxManager, deps.DeletedRetention),
+		Audit: NewAuditService(deps.Repos.AuditLog),
+	}
+}
+
+// authorize returns the principal of the request if it holds the given permission.
+func authorize(ctx context.Context, permission domain.Permission) (domain.Principal, error) {
+	principal, ok := domain.PrincipalFromContext(ctx)
+	if !ok {
+		return domain.Principal{}, domain.Unauthorized
+	}
+	if !principal.Can(permission) {
+		return domain.Principal{}, domain.Forbidden
+	}
+	return principal, nil
+}
+
+// authorizeOn is like authorize but also accepts permissions granted for the scope of
+// a record with the given attributes.
+func authorizeOn(ctx context.Context, permission domain.Permission, attrs domain.Attributes) (domain.Principal,
+	error) {
+	principal, ok := domain.PrincipalFromContext(ctx)
+	if !ok {
+		return domain.Principal{}, domain.Unauthorized
+	}
+	if !principal.CanOn(permission, attrs) {
+		return domain.Principal{}, domain.Forbidden
+	}
+	return principal, nil
+}
+
+// checkVersion fails with Conflict unless the expected version is zero or current.
+func checkVersion(expected, current int64) error {
+	if expected != 0 && expected != current {
+		return fmt.Errorf("%w: expected version %d, current version is %d", domain.Conflict, expected, current)
+	}
+	return nil
+}
+
+// restrictList limits the list to the cities where the principal holds the permission,
+// unless they hold it for all records. Asking for the records of a city where they do not
+// hold it is Forbidden.
+func restrictList(ctx context.Context, permission domain.Permission, opts domain.ListOptions) (domain.ListOptions,
+	error) {
+	principal, ok := domain.PrincipalFromContext(ctx)
+	if !ok {
+		return domain.ListOptions{}, domain.Unauthorized
+	}
+
+	opts.Restriction = nil
+	if principal.Can(permission) {
+		return opts, nil
+	}
+
+	cities := principal.CitiesWith(permission)
+	if len(cities) == 0 {
+		return domain.ListOptions{}, domain.Forbidden
+	}
+	if cityID := opts.Filter.CityID; cityID != 0 && !principal.CanOn(permission, domain.Attributes{CityID: cityID}) {
+		return domain.ListOptions{}, domain.Forbidden
+	}
+	opts.Restriction = &domain.ListRestriction{CityIDs: cities}
+	return opts, nil
+}
+
+// listDeleted lets admins list the deleted records.
+func listDeleted(ctx context.Context, opts domain.ListOptions) (domain.ListOptions, error) {
+	if _, err := authorize(ctx, domain.Admin); err != nil {
+		return domain.ListOptions{}, err
+	}
+	opts.Restriction = nil
+	opts.Deleted = true
+	return opts, nil
+}
+
+// authorizeSelfOr is like authorize but also lets users act on their own record.
+func authorizeSelfOr(ctx context.Context, userID domain.ID, permission domain.Permission) (domain.Principal, error) {
+	principal, ok := domain.PrincipalFromContext(ctx)
+	if !ok {
+		return domain.Principal{}, domain.Unauthorized
+	}
+	if principal.UserID != userID && !principal.Can(permission) {
+		return domain.Principal{}, domain.Forbidden
+	}
+	return principal, nil
+}
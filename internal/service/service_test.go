@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"foodsharing-backend/internal/domain"
+)
+
+// errRepo stands for any failure of a repository.
+var errRepo = errors.New("connection refused")
+
+// asUser returns a context of the user holding the permissions for all records.
+func asUser(id domain.ID, permissions ...domain.Permission) context.Context {
+	return domain.WithPrincipal(context.Background(), domain.Principal{
+		UserID:      id,
+		Permissions: domain.NewPermissions(permissions...),
+	})
+}
+
+// asCityUser returns a context of the user holding the permissions in the city only.
+func asCityUser(id domain.ID, cityID domain.ID, permissions ...domain.Permission) context.Context {
+	return domain.WithPrincipal(context.Background(), domain.Principal{
+		UserID:          id,
+		CityPermissions: map[domain.ID]domain.Permissions{cityID: domain.NewPermissions(permissions...)},
+	})
+}
+
+// checkErr fails the test unless err matches want, nil included.
+func checkErr(t *testing.T, err, want error) {
+	t.Helper()
+	if want == nil {
+		if err != nil {
+			t.Fatalf("error = %v, want nil", err)
+		}
+		return
+	}
+	if !errors.Is(err, want) {
+		t.Fatalf("error = %v, want %v", err, want)
+	}
+}
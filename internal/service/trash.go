@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"foodsharing-backend/internal/repository"
+)
+
+type TrashService struct {
+	txManager repository.TxManager
+	retention time.Duration
+}
+
+func NewTrashService(txManager repository.TxManager, retention time.Duration) *TrashService {
+	return &TrashService{txManager: txManager, retention: retention}
+}
+
+// Purge removes the acts first, so the donor companies and users they referred to can go
+// in the same run.
+func (s *TrashService) Purge(ctx context.Context) error {
+	deletedBefore := time.Now().Add(-s.retention)
+
+	var acts, companies, users int64
+	err := s.txManager.WithinTx(ctx, func(repos *repository.Repositories) error {
+		var err error
+		if acts, err = repos.Acts.Purge(ctx, deletedBefore); err != nil {
+			return err
+		}
+		if companies, err = repos.DonorCompanies.Purge(ctx, deletedBefore); err != nil {
+			return err
+		}
+		users, err = repos.Users.Purge(ctx, deletedBefore)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if acts+companies+users > 0 {
+		log.Printf("purged %d acts, %d donor companies and %d users deleted before %s", acts, companies, users,
+			deletedBefore.Format(time.RFC3339))
+	}
+	return nil
+}
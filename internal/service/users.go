@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+
+	"foodsharing-backend/internal/domain"
+	"foodsharing-backend/internal/repository"
+)
+
+type UsersService struct {
+	repo       repository.Users
+	groups     repository.Groups
+	authorizer Authorizer
+}
+
+func NewUsersService(repo repository.Users, groups repository.Groups, authorizer Authorizer) *UsersService {
+	return &UsersService{
+		repo:       repo,
+		groups:     groups,
+		authorizer: authorizer,
+	}
+}
+
+func (i UserInput) validate() error {
+	if i.Surname == "" {
+		return fmt.Errorf("%w: surname is required", domain.InvalidInput)
+	}
+	if i.Name == "" {
+		return fmt.Errorf("%w: name is required", domain.InvalidInput)
+	}
+	if i.DateOfBirth.IsZero() {
+		return fmt.Errorf("%w: date_of_birth is required", domain.InvalidInput)
+	}
+	if _, err := mail.ParseAddress(i.Email); err != nil {
+		return fmt.Errorf("%w: invalid email", domain.InvalidInput)
+	}
+	if i.CityID == 0 {
+		return fmt.Errorf("%w: city_id is required", domain.InvalidInput)
+	}
+	return nil
+}
+
+func (i UserInput) toDomain() domain.User {
+	return domain.User{
+		Surname:     i.Surname,
+		Name:        i.Name,
+		Patronymic:  i.Patronymic,
+		DateOfBirth: i.DateOfBirth,
+		PhoneNumber: i.PhoneNumber,
+		Email:       i.Email,
+		CityID:      i.CityID,
+	}
+}
+
+func (s *UsersService) Create(ctx context.Context, input UserInput) (domain.User, error) {
+	if err := input.validate(); err != nil {
+		return domain.User{}, err
+	}
+	if _, err := authorizeOn(ctx, domain.CreateUser, domain.Attributes{CityID: input.CityID}); err != nil {
+		return domain.User{}, err
+	}
+
+	user := input.toDomain()
+	if err := s.repo.Create(ctx, &user); err != nil {
+		return domain.User{}, err
+	}
+	return user, nil
+}
+
+// Update lets users edit their own profile. Moving a user to another city always needs
+// the EditUser permission for the new city, as city scoped permissions follow the user.
+func (s *UsersService) Update(ctx context.Context, id domain.ID, input UserInput, version int64) error {
+	if err := input.validate(); err != nil {
+		return err
+	}
+
+	user, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := authorizeUser(ctx, domain.EditUser, user); err != nil {
+		return err
+	}
+	if err := checkVersion(version, user.Version); err != nil {
+		return err
+	}
+	if input.CityID != user.CityID {
+		if _, err := authorizeOn(ctx, domain.EditUser, domain.Attributes{CityID: input.CityID}); err != nil {
+			return err
+		}
+	}
+
+	updated := input.toDomain()
+	updated.ID = id
+	updated.Version = user.Version
+	return s.repo.Update(ctx, updated)
+}
+
+func (s *UsersService) Delete(ctx context.Context, id domain.ID, version int64) error {
+	user, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if _, err := authorizeOn(ctx, domain.EditUser, user.Attributes()); err != nil {
+		return err
+	}
+	if err := checkVersion(version, user.Version); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, id, user.Version)
+}
+
+func (s *UsersService) Restore(ctx context.Context, id domain.ID) error {
+	if _, err := authorize(ctx, domain.Admin); err != nil {
+		return err
+	}
+	return s.repo.Restore(ctx, id)
+}
+
+func (s *UsersService) GetByID(ctx context.Context, id domain.ID) (domain.User, error) {
+	user, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return domain.User{}, err
+	}
+	if err := authorizeUser(ctx, domain.ReadUser, user); err != nil {
+		return domain.User{}, err
+	}
+	return user, nil
+}
+
+// GetAll lists all users to those who may read any user and the users of their cities to
+// those who may read users in some cities only.
+func (s *UsersService) GetAll(ctx context.Context, opts domain.ListOptions) ([]domain.User, string, error) {
+	opts, err := restrictList(ctx, domain.ReadUser, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	opts.Deleted = false
+	return s.repo.GetAll(ctx, opts)
+}
+
+func (s *UsersService) GetDeleted(ctx context.Context, opts domain.ListOptions) ([]domain.User, string, error) {
+	opts, err := listDeleted(ctx, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	return s.repo.GetAll(ctx, opts)
+}
+
+func (s *UsersService) GetGroups(ctx context.Context, id domain.ID) ([]domain.Membership, error) {
+	if _, err := authorizeSelfOr(ctx, id, domain.ReadGroup); err != nil {
+		return nil, err
+	}
+	return s.groups.GetUserGroups(ctx, id)
+}
+
+// ExplainPermission is open to the user themselves, like GetGroups, so they can find out
+// why they were denied.
+func (s *UsersService) ExplainPermission(ctx context.Context, id domain.ID,
+	input PermissionQuery) (domain.PermissionExplanation, error) {
+	if _, err := authorizeSelfOr(ctx, id, domain.ReadGroup); err != nil {
+		return domain.PermissionExplanation{}, err
+	}
+
+	user, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return domain.PermissionExplanation{}, err
+	}
+
+	record := domain.Attributes{OwnerID: input.OwnerID, CityID: input.CityID}
+	return s.authorizer.Explain(ctx, user, input.Action, input.Resource, record)
+}
+
+// authorizeUser lets users act on their own record, others need the permission in the
+// scope of the user.
+func authorizeUser(ctx context.Context, permission domain.Permission, user domain.User) error {
+	principal, ok := domain.PrincipalFromContext(ctx)
+	if !ok {
+		return domain.Unauthorized
+	}
+	if principal.UserID != user.ID && !principal.CanOn(permission, user.Attributes()) {
+		return domain.Forbidden
+	}
+	return nil
+}
@@ -0,0 +1,448 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"foodsharing-backend/internal/domain"
+	mock_repository "foodsharing-backend/internal/repository/mocks"
+
+	"github.com/golang/mock/gomock"
+)
+
+type usersMocks struct {
+	repo   *mock_repository.MockUsers
+	groups *mock_repository.MockGroups
+}
+
+func newUsersService(t *testing.T) (*UsersService, usersMocks) {
+	ctrl := gomock.NewController(t)
+	m := usersMocks{
+		repo:   mock_repository.NewMockUsers(ctrl),
+		groups: mock_repository.NewMockGroups(ctrl),
+	}
+	return NewUsersService(m.repo, m.groups, NewAuthorizationService(m.groups)), m
+}
+
+var testUser = domain.User{
+	Object: domain.Object{ID: 2, Version: 3},
+	Name:   "Anna",
+	Email:  "anna@example.com",
+	CityID: 10,
+}
+
+func TestUsersServiceGetByID(t *testing.T) {
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		setup   func(m usersMocks)
+		wantErr error
+	}{
+		{
+			name: "own profile",
+			ctx:  asUser(testUser.ID),
+			setup: func(m usersMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), testUser.ID).Return(testUser, nil)
+			},
+		},
+		{
+			name: "reader in the city",
+			ctx:  asCityUser(1, testUser.CityID, domain.ReadUser),
+			setup: func(m usersMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), testUser.ID).Return(testUser, nil)
+			},
+		},
+		{
+			name: "reader in another city",
+			ctx:  asCityUser(1, 20, domain.ReadUser),
+			setup: func(m usersMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), testUser.ID).Return(testUser, nil)
+			},
+			wantErr: domain.Forbidden,
+		},
+		{
+			name: "not found",
+			ctx:  asUser(1, domain.ReadUser),
+			setup: func(m usersMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), testUser.ID).Return(domain.User{}, domain.NotFound)
+			},
+			wantErr: domain.NotFound,
+		},
+		{
+			name: "repository error",
+			ctx:  asUser(1, domain.ReadUser),
+			setup: func(m usersMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), testUser.ID).Return(domain.User{}, errRepo)
+			},
+			wantErr: errRepo,
+		},
+		{
+			name: "anonymous",
+			ctx:  context.Background(),
+			setup: func(m usersMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), testUser.ID).Return(testUser, nil)
+			},
+			wantErr: domain.Unauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m := newUsersService(t)
+			tt.setup(m)
+
+			user, err := s.GetByID(tt.ctx, testUser.ID)
+			checkErr(t, err, tt.wantErr)
+			if err == nil && user.ID != testUser.ID {
+				t.Errorf("GetByID() = user %d, want %d", user.ID, testUser.ID)
+			}
+		})
+	}
+}
+
+func TestUsersServiceUpdate(t *testing.T) {
+	input := UserInput{
+		Surname:     "Ivanova",
+		Name:        "Anna",
+		DateOfBirth: time.Date(1990, time.March, 8, 0, 0, 0, 0, time.UTC),
+		Email:       "anna@example.com",
+		CityID:      testUser.CityID,
+	}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		input   UserInput
+		version int64
+		setup   func(m usersMocks)
+		wantErr error
+	}{
+		{
+			name:    "own profile",
+			ctx:     asUser(testUser.ID),
+			input:   input,
+			version: testUser.Version,
+			setup: func(m usersMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), testUser.ID).Return(testUser, nil)
+				m.repo.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, user domain.User) error {
+						if user.ID != testUser.ID || user.Surname != input.Surname || user.Version != testUser.Version {
+							t.Errorf("Update() got %+v", user)
+						}
+						return nil
+					})
+			},
+		},
+		{
+			name:  "without a version",
+			ctx:   asUser(testUser.ID),
+			input: input,
+			setup: func(m usersMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), testUser.ID).Return(testUser, nil)
+				m.repo.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:    "stale version",
+			ctx:     asUser(testUser.ID),
+			input:   input,
+			version: testUser.Version - 1,
+			setup: func(m usersMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), testUser.ID).Return(testUser, nil)
+			},
+			wantErr: domain.Conflict,
+		},
+		{
+			name:    "changed since it was read",
+			ctx:     asUser(testUser.ID),
+			input:   input,
+			version: testUser.Version,
+			setup: func(m usersMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), testUser.ID).Return(testUser, nil)
+				m.repo.EXPECT().Update(gomock.Any(), gomock.Any()).Return(domain.Conflict)
+			},
+			wantErr: domain.Conflict,
+		},
+		{
+			name:  "moving to a city the user may not edit",
+			ctx:   asUser(testUser.ID),
+			input: UserInput{Surname: "I", Name: "A", DateOfBirth: input.DateOfBirth, Email: input.Email, CityID: 20},
+			setup: func(m usersMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), testUser.ID).Return(testUser, nil)
+			},
+			wantErr: domain.Forbidden,
+		},
+		{
+			name:  "another user",
+			ctx:   asUser(1, domain.ReadUser),
+			input: input,
+			setup: func(m usersMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), testUser.ID).Return(testUser, nil)
+			},
+			wantErr: domain.Forbidden,
+		},
+		{
+			name:  "email taken",
+			ctx:   asUser(1, domain.EditUser),
+			input: input,
+			setup: func(m usersMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), testUser.ID).Return(testUser, nil)
+				m.repo.EXPECT().Update(gomock.Any(), gomock.Any()).Return(domain.AlreadyExists)
+			},
+			wantErr: domain.AlreadyExists,
+		},
+		{
+			name:  "not found",
+			ctx:   asUser(1, domain.EditUser),
+			input: input,
+			setup: func(m usersMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), testUser.ID).Return(domain.User{}, domain.NotFound)
+			},
+			wantErr: domain.NotFound,
+		},
+		{
+			name:    "invalid email",
+			ctx:     asUser(1, domain.EditUser),
+			input:   UserInput{Surname: "I", Name: "A", DateOfBirth: input.DateOfBirth, Email: "anna", CityID: 10},
+			setup:   func(m usersMocks) {},
+			wantErr: domain.InvalidInput,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m := newUsersService(t)
+			tt.setup(m)
+
+			checkErr(t, s.Update(tt.ctx, testUser.ID, tt.input, tt.version), tt.wantErr)
+		})
+	}
+}
+
+func TestUsersServiceDelete(t *testing.T) {
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		version int64
+		setup   func(m usersMocks)
+		wantErr error
+	}{
+		{
+			name:    "editor in the city",
+			ctx:     asCityUser(1, testUser.CityID, domain.EditUser),
+			version: testUser.Version,
+			setup: func(m usersMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), testUser.ID).Return(testUser, nil)
+				m.repo.EXPECT().Delete(gomock.Any(), testUser.ID, testUser.Version).Return(nil)
+			},
+		},
+		{
+			name: "any version",
+			ctx:  asUser(1, domain.EditUser),
+			setup: func(m usersMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), testUser.ID).Return(testUser, nil)
+				m.repo.EXPECT().Delete(gomock.Any(), testUser.ID, testUser.Version).Return(nil)
+			},
+		},
+		{
+			name:    "editor in another city",
+			ctx:     asCityUser(1, 20, domain.EditUser),
+			version: testUser.Version,
+			setup: func(m usersMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), testUser.ID).Return(testUser, nil)
+			},
+			wantErr: domain.Forbidden,
+		},
+		{
+			name:    "stale version",
+			ctx:     asUser(1, domain.EditUser),
+			version: testUser.Version + 1,
+			setup: func(m usersMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), testUser.ID).Return(testUser, nil)
+			},
+			wantErr: domain.Conflict,
+		},
+		{
+			name:    "changed after it was read",
+			ctx:     asUser(1, domain.EditUser),
+			version: testUser.Version,
+			setup: func(m usersMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), testUser.ID).Return(testUser, nil)
+				m.repo.EXPECT().Delete(gomock.Any(), testUser.ID, testUser.Version).Return(domain.Conflict)
+			},
+			wantErr: domain.Conflict,
+		},
+		{
+			name:    "not found",
+			ctx:     asUser(1, domain.EditUser),
+			version: testUser.Version,
+			setup: func(m usersMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), testUser.ID).Return(domain.User{}, domain.NotFound)
+			},
+			wantErr: domain.NotFound,
+		},
+		{
+			name:    "repository error",
+			ctx:     asUser(1, domain.EditUser),
+			version: testUser.Version,
+			setup: func(m usersMocks) {
+				m.repo.EXPECT().GetByID(gomock.Any(), testUser.ID).Return(testUser, nil)
+				m.repo.EXPECT().Delete(gomock.Any(), testUser.ID, testUser.Version).Return(errRepo)
+			},
+			wantErr: errRepo,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m := newUsersService(t)
+			tt.setup(m)
+
+			checkErr(t, s.Delete(tt.ctx, testUser.ID, tt.version), tt.wantErr)
+		})
+	}
+}
+
+func TestUsersServiceRestore(t *testing.T) {
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		setup   func(m usersMocks)
+		wantErr error
+	}{
+		{
+			name: "admin",
+			ctx:  asUser(1, domain.Admin),
+			setup: func(m usersMocks) {
+				m.repo.EXPECT().Restore(gomock.Any(), testUser.ID).Return(nil)
+			},
+		},
+		{
+			name:    "editor",
+			ctx:     asUser(1, domain.EditUser),
+			setup:   func(m usersMocks) {},
+			wantErr: domain.Forbidden,
+		},
+		{
+			name: "email taken since",
+			ctx:  asUser(1, domain.Admin),
+			setup: func(m usersMocks) {
+				m.repo.EXPECT().Restore(gomock.Any(), testUser.ID).Return(domain.AlreadyExists)
+			},
+			wantErr: domain.AlreadyExists,
+		},
+		{
+			name: "not deleted",
+			ctx:  asUser(1, domain.Admin),
+			setup: func(m usersMocks) {
+				m.repo.EXPECT().Restore(gomock.Any(), testUser.ID).Return(domain.NotFound)
+			},
+			wantErr: domain.NotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m := newUsersService(t)
+			tt.setup(m)
+
+			checkErr(t, s.Restore(tt.ctx, testUser.ID), tt.wantErr)
+		})
+	}
+}
+
+func TestUsersServiceGetAll(t *testing.T) {
+	t.Run("reader of all users", func(t *testing.T) {
+		s, m := newUsersService(t)
+		m.repo.EXPECT().GetAll(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, opts domain.ListOptions) ([]domain.User, string, error) {
+				if opts.Restriction != nil {
+					t.Errorf("GetAll() restriction = %+v, want none", opts.Restriction)
+				}
+				return []domain.User{testUser}, "", nil
+			})
+
+		opts := domain.ListOptions{Restriction: &domain.ListRestriction{OwnerID: 1}}
+		users, _, err := s.GetAll(asUser(1, domain.ReadUser), opts)
+		checkErr(t, err, nil)
+		if len(users) != 1 {
+			t.Errorf("GetAll() = %d users, want 1", len(users))
+		}
+	})
+
+	t.Run("restricted to the cities of the reader", func(t *testing.T) {
+		s, m := newUsersService(t)
+		m.repo.EXPECT().GetAll(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, opts domain.ListOptions) ([]domain.User, string, error) {
+				if opts.Restriction == nil || len(opts.Restriction.CityIDs) != 1 || opts.Restriction.CityIDs[0] != 10 {
+					t.Errorf("GetAll() restriction = %+v, want city 10", opts.Restriction)
+				}
+				if opts.Deleted {
+					t.Errorf("GetAll() lists deleted users")
+				}
+				return []domain.User{testUser}, "", nil
+			})
+
+		_, _, err := s.GetAll(asCityUser(1, 10, domain.ReadUser), domain.ListOptions{Deleted: true})
+		checkErr(t, err, nil)
+	})
+
+	t.Run("filtered by a city outside the reach of the reader", func(t *testing.T) {
+		s, _ := newUsersService(t)
+
+		opts := domain.ListOptions{Filter: domain.ListFilter{CityID: 20}}
+		_, _, err := s.GetAll(asCityUser(1, 10, domain.ReadUser), opts)
+		checkErr(t, err, domain.Forbidden)
+	})
+
+	t.Run("without permission", func(t *testing.T) {
+		s, _ := newUsersService(t)
+
+		_, _, err := s.GetAll(asUser(1), domain.ListOptions{})
+		checkErr(t, err, domain.Forbidden)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		s, m := newUsersService(t)
+		m.repo.EXPECT().GetAll(gomock.Any(), gomock.Any()).Return(nil, "", errRepo)
+
+		_, _, err := s.GetAll(asUser(1, domain.ReadUser), domain.ListOptions{})
+		checkErr(t, err, errRepo)
+	})
+}
+
+func TestUsersServiceExplainPermission(t *testing.T) {
+	query := PermissionQuery{Action: domain.ActionEdit, Resource: domain.ResourceActs, CityID: testUser.CityID}
+
+	t.Run("the user themselves", func(t *testing.T) {
+		s, m := newUsersService(t)
+		m.repo.EXPECT().GetByID(gomock.Any(), testUser.ID).Return(testUser, nil)
+		m.groups.EXPECT().GetUserGroups(gomock.Any(), testUser.ID).Return([]domain.Membership{
+			{Group: domain.Group{Name: "Editors", EffectivePermissions: domain.NewPermissions(domain.EditAct),
+				Scope: domain.ScopeCity}},
+		}, nil)
+
+		explanation, err := s.ExplainPermission(asUser(testUser.ID), testUser.ID, query)
+		checkErr(t, err, nil)
+		if !explanation.Allowed {
+			t.Errorf("Allowed = false, want true")
+		}
+	})
+
+	t.Run("another user without ReadGroup", func(t *testing.T) {
+		s, _ := newUsersService(t)
+
+		_, err := s.ExplainPermission(asUser(1, domain.ReadUser), testUser.ID, query)
+		checkErr(t, err, domain.Forbidden)
+	})
+
+	t.Run("unknown resource", func(t *testing.T) {
+		s, m := newUsersService(t)
+		m.repo.EXPECT().GetByID(gomock.Any(), testUser.ID).Return(testUser, nil)
+
+		_, err := s.ExplainPermission(asUser(1, domain.ReadGroup), testUser.ID,
+			PermissionQuery{Action: domain.ActionEdit, Resource: "parcels"})
+		checkErr(t, err, domain.InvalidInput)
+	})
+}
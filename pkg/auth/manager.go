@@ -0,0 +1,129 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const opaqueTokenSize = 32
+
+// Audiences keep tokens of one kind from being accepted in place of another.
+const (
+	accessAudience    = "access"
+	twoFactorAudience = "two-factor"
+)
+
+// Permissions are the permission names of the token owner by scope: All applies to every
+// record, Own to the records of the owner and City to the records of the cities it is keyed by.
+type Permissions struct {
+	All  []string            `json:"perm,omitempty"`
+	Own  []string            `json:"perm_own,omitempty"`
+	City map[uint64][]string `json:"perm_city,omitempty"`
+}
+
+// Claims is the payload of an access token.
+type Claims struct {
+	jwt.RegisteredClaims
+	Permissions
+}
+
+func (c Claims) UserID() (uint64, error) {
+	return strconv.ParseUint(c.Subject, 10, 64)
+}
+
+type TokenManager interface {
+	NewJWT(userID uint64, permissions Permissions, ttl time.Duration) (string, error)
+	Parse(accessToken string) (Claims, error)
+	// NewTwoFactorToken issues a token proving that the user has passed the first sign-in
+	// step. It cannot be used as an access token.
+	NewTwoFactorToken(userID uint64, ttl time.Duration) (string, error)
+	ParseTwoFactorToken(token string) (uint64, error)
+	NewRefreshToken() (string, error)
+}
+
+type Manager struct {
+	signingKey []byte
+}
+
+func NewManager(signingKey string) (*Manager, error) {
+	if signingKey == "" {
+		return nil, errors.New("empty signing key")
+	}
+
+	return &Manager{signingKey: []byte(signingKey)}, nil
+}
+
+func (m *Manager) NewJWT(userID uint64, permissions Permissions, ttl time.Duration) (string, error) {
+	return m.sign(Claims{
+		RegisteredClaims: registeredClaims(userID, accessAudience, ttl),
+		Permissions:      permissions,
+	})
+}
+
+func (m *Manager) Parse(accessToken string) (Claims, error) {
+	return m.parse(accessToken, accessAudience)
+}
+
+func (m *Manager) NewTwoFactorToken(userID uint64, ttl time.Duration) (string, error) {
+	return m.sign(Claims{RegisteredClaims: registeredClaims(userID, twoFactorAudience, ttl)})
+}
+
+func (m *Manager) ParseTwoFactorToken(token string) (uint64, error) {
+	claims, err := m.parse(token, twoFactorAudience)
+	if err != nil {
+		return 0, err
+	}
+	return claims.UserID()
+}
+
+func (m *Manager) NewRefreshToken() (string, error) {
+	return NewOpaqueToken()
+}
+
+func (m *Manager) sign(claims Claims) (string, error) {
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(m.signingKey)
+}
+
+func (m *Manager) parse(token, audience string) (Claims, error) {
+	var claims Claims
+	_, err := jwt.ParseWithClaims(token, &claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return m.signingKey, nil
+	})
+	if err != nil {
+		return Claims{}, err
+	}
+	if !claims.VerifyAudience(audience, true) {
+		return Claims{}, errors.New("token has wrong audience")
+	}
+
+	return claims, nil
+}
+
+func registeredClaims(userID uint64, audience string, ttl time.Duration) jwt.RegisteredClaims {
+	now := time.Now()
+	return jwt.RegisteredClaims{
+		Subject:   strconv.FormatUint(userID, 10),
+		Audience:  jwt.ClaimStrings{audience},
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+	}
+}
+
+// NewOpaqueToken returns a random URL-safe token that carries no data.
+func NewOpaqueToken() (string, error) {
+	b := make([]byte, opaqueTokenSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("cannot generate token: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,33 @@
+package hash
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+)
+
+// TokenHasher turns secret tokens into values that are safe to store and look up.
+type TokenHasher interface {
+	Hash(token string) string
+}
+
+// HMACHasher hashes tokens with HMAC-SHA256, so a leaked database alone is not enough
+// to forge or recover tokens.
+type HMACHasher struct {
+	key []byte
+}
+
+func NewHMACHasher(key string) (*HMACHasher, error) {
+	if key == "" {
+		return nil, errors.New("empty hash key")
+	}
+
+	return &HMACHasher{key: []byte(key)}, nil
+}
+
+func (h *HMACHasher) Hash(token string) string {
+	mac := hmac.New(sha256.New, h.key)
+	mac.Write([]byte(token))
+	return hex.EncodeToString(mac.Sum(nil))
+}
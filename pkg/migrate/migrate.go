@@ -0,0 +1,267 @@
+package migrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// lockKey identifies the advisory lock held while migrating, so that instances started
+// at the same time apply every migration once.
+const lockKey int64 = 0x666f6f647368
+
+var fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration is a schema change read from a pair of files: NNNN_name.up.sql applies it
+// and the optional NNNN_name.down.sql reverts it. Checksum is computed from the up script.
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+// Status of a migration: AppliedAt is nil for pending ones.
+type Status struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+}
+
+type Migrator struct {
+	db         *pgxpool.Pool
+	migrations []Migration
+}
+
+// NewMigrator reads the migrations from the root of fsys.
+func NewMigrator(pool *pgxpool.Pool, fsys fs.FS) (*Migrator, error) {
+	migrations, err := load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: pool, migrations: migrations}, nil
+}
+
+func load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("cannot read migrations: %w", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("invalid migration version in %s", entry.Name())
+		}
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("cannot read migration %s: %w", entry.Name(), err)
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has different names: %s and %s", version, migration.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			sum := sha256.Sum256(content)
+			migration.Up = string(content)
+			migration.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("migration %d has no up script", migration.Version)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+const (
+	createMigrationsTableQuery = `CREATE TABLE IF NOT EXISTS schema_migrations (
+			version    bigint                   NOT NULL PRIMARY KEY,
+			name       text                     NOT NULL,
+			checksum   text                     NOT NULL,
+			applied_at timestamp with time zone NOT NULL
+		)`
+	getAppliedMigrationsQuery = `SELECT version, name, checksum, applied_at FROM schema_migrations ORDER BY version`
+	addMigrationQuery         = `INSERT INTO schema_migrations (version, name, checksum, applied_at)
+		VALUES ($1, $2, $3, now())`
+	removeMigrationQuery = `DELETE FROM schema_migrations WHERE version = $1`
+)
+
+type applied struct {
+	name      string
+	checksum  string
+	appliedAt time.Time
+}
+
+// Up applies the pending migrations in order, each in its own transaction, and returns
+// them. It refuses to run when an applied migration was changed or is unknown.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var done []Migration
+	err := m.locked(ctx, func(conn *pgxpool.Conn, history map[int64]applied) error {
+		for _, migration := range m.migrations {
+			if _, ok := history[migration.Version]; ok {
+				continue
+			}
+			if err := m.apply(ctx, conn, migration.Up, addMigrationQuery, migration.Version, migration.Name,
+				migration.Checksum); err != nil {
+				return fmt.Errorf("cannot apply migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			done = append(done, migration)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down reverts the latest steps applied migrations, newest first, and returns them.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var done []Migration
+	err := m.locked(ctx, func(conn *pgxpool.Conn, history map[int64]applied) error {
+		for i := len(m.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+			migration := m.migrations[i]
+			if _, ok := history[migration.Version]; !ok {
+				continue
+			}
+			if migration.Down == "" {
+				return fmt.Errorf("migration %d_%s cannot be reverted", migration.Version, migration.Name)
+			}
+			if err := m.apply(ctx, conn, migration.Down, removeMigrationQuery, migration.Version); err != nil {
+				return fmt.Errorf("cannot revert migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			done = append(done, migration)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Status lists every known migration with the time it was applied at.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	var statuses []Status
+	err := m.locked(ctx, func(_ *pgxpool.Conn, history map[int64]applied) error {
+		for _, migration := range m.migrations {
+			status := Status{Version: migration.Version, Name: migration.Name}
+			if entry, ok := history[migration.Version]; ok {
+				appliedAt := entry.appliedAt
+				status.AppliedAt = &appliedAt
+			}
+			statuses = append(statuses, status)
+		}
+		return nil
+	})
+	return statuses, err
+}
+
+// locked runs fn on a single connection holding the advisory lock, after making sure the
+// history matches the known migrations.
+func (m *Migrator) locked(ctx context.Context, fn func(*pgxpool.Conn, map[int64]applied) error) (err error) {
+	conn, err := m.db.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, "SELECT pg_advisory_lock($1)", lockKey); err != nil {
+		return fmt.Errorf("cannot take migration lock: %w", err)
+	}
+	defer func() {
+		// The lock belongs to the session, it must not outlive the run on a pooled connection.
+		_, unlockErr := conn.Exec(context.Background(), "SELECT pg_advisory_unlock($1)", lockKey)
+		if unlockErr != nil {
+			conn.Conn().Close(context.Background())
+			if err == nil {
+				err = fmt.Errorf("cannot release migration lock: %w", unlockErr)
+			}
+		}
+	}()
+
+	if _, err := conn.Exec(ctx, createMigrationsTableQuery); err != nil {
+		return fmt.Errorf("cannot create migrations table: %w", err)
+	}
+	history, err := m.history(ctx, conn)
+	if err != nil {
+		return err
+	}
+	return fn(conn, history)
+}
+
+func (m *Migrator) history(ctx context.Context, conn *pgxpool.Conn) (map[int64]applied, error) {
+	rows, err := conn.Query(ctx, getAppliedMigrationsQuery)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	history := make(map[int64]applied)
+	for rows.Next() {
+		var version int64
+		var entry applied
+		if err := rows.Scan(&version, &entry.name, &entry.checksum, &entry.appliedAt); err != nil {
+			return nil, fmt.Errorf("cannot scan migration: %w", err)
+		}
+		history[version] = entry
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("cannot get applied migrations: %w", err)
+	}
+
+	known := make(map[int64]Migration, len(m.migrations))
+	for _, migration := range m.migrations {
+		known[migration.Version] = migration
+	}
+	for version, entry := range history {
+		migration, ok := known[version]
+		if !ok {
+			return nil, fmt.Errorf("applied migration %d_%s is unknown", version, entry.name)
+		}
+		if migration.Checksum != entry.checksum {
+			return nil, fmt.Errorf("applied migration %d_%s was modified", version, migration.Name)
+		}
+	}
+	return history, nil
+}
+
+// apply runs the script and records it with the bookkeeping query in one transaction.
+func (m *Migrator) apply(ctx context.Context, conn *pgxpool.Conn, script, bookkeeping string,
+	args ...interface{}) error {
+	return conn.BeginFunc(ctx, func(tx pgx.Tx) error {
+		// Without arguments the script is sent as a simple query, which may hold several
+		// statements.
+		if _, err := tx.Exec(ctx, script); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, bookkeeping, args...); err != nil {
+			return fmt.Errorf("cannot record migration: %w", err)
+		}
+		return nil
+	})
+}
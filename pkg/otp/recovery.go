@@ -0,0 +1,39 @@
+package otp
+
+import (
+	"crypto/rand"
+	"fmt"
+	"strings"
+)
+
+const (
+	recoveryCodeSize  = 10
+	recoveryGroupSize = 4
+)
+
+// GenerateRecoveryCodes returns n random single-use codes for users who lost their
+// authenticator.
+func GenerateRecoveryCodes(n int) ([]string, error) {
+	codes := make([]string, n)
+	for i := range codes {
+		b := make([]byte, recoveryCodeSize)
+		if _, err := rand.Read(b); err != nil {
+			return nil, fmt.Errorf("cannot generate recovery code: %w", err)
+		}
+		codes[i] = NormalizeRecoveryCode(encoding.EncodeToString(b))
+	}
+	return codes, nil
+}
+
+// NormalizeRecoveryCode brings a recovery code to its canonical form: lower case, split
+// into groups of four characters. Case and separators typed by the user are ignored.
+func NormalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+
+	var groups []string
+	for len(code) > recoveryGroupSize {
+		groups = append(groups, code[:recoveryGroupSize])
+		code = code[recoveryGroupSize:]
+	}
+	return strings.Join(append(groups, code), "-")
+}